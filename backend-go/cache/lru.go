@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+type lruStore struct {
+	mu       sync.Mutex
+	capacity int
+	items    map[string]*list.Element
+	order    *list.List // mais recente na frente
+}
+
+// NewLRU cria um Store em memória com no máximo capacity chaves. Ao atingir
+// o limite, a chave usada há mais tempo é descartada; chaves expiradas são
+// removidas quando lidas.
+func NewLRU(capacity int) Store {
+	if capacity <= 0 {
+		capacity = 10000
+	}
+	return &lruStore{
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Get retorna o valor da chave e a marca como usada
+func (s *lruStore) Get(key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, ok := s.items[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := element.Value.(*lruEntry)
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		s.order.Remove(element)
+		delete(s.items, key)
+		return nil, false, nil
+	}
+	s.order.MoveToFront(element)
+	return entry.value, true, nil
+}
+
+// Set grava o valor, descartando a chave menos usada se o limite for atingido
+func (s *lruStore) Set(key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+
+	if element, ok := s.items[key]; ok {
+		entry := element.Value.(*lruEntry)
+		entry.value = value
+		entry.expiresAt = expiresAt
+		s.order.MoveToFront(element)
+		return nil
+	}
+
+	s.items[key] = s.order.PushFront(&lruEntry{key: key, value: value, expiresAt: expiresAt})
+	for s.order.Len() > s.capacity {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.items, oldest.Value.(*lruEntry).key)
+	}
+	return nil
+}
+
+// Delete remove a chave, se existir
+func (s *lruStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if element, ok := s.items[key]; ok {
+		s.order.Remove(element)
+		delete(s.items, key)
+	}
+	return nil
+}
+
+// Len retorna o número de chaves guardadas, incluindo as expiradas ainda não lidas
+func (s *lruStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
@@ -0,0 +1,18 @@
+// Package cache define o armazenamento chave-valor usado pelos caches do
+// backend e uma implementação LRU em memória.
+package cache
+
+import "time"
+
+// Store guarda valores opacos por chave com expiração. A interface segue os
+// comandos GET, SET com EX e DEL, então um cliente Redis (ou compatível)
+// pode implementá-la para compartilhar o cache entre instâncias.
+type Store interface {
+	// Get retorna o valor e se a chave existe e não expirou
+	Get(key string) ([]byte, bool, error)
+	// Set grava o valor; ttl zero ou negativo não expira
+	Set(key string, value []byte, ttl time.Duration) error
+	Delete(key string) error
+	// Len retorna o número de chaves guardadas, quando disponível
+	Len() int
+}
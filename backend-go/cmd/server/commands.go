@@ -0,0 +1,355 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"backend-go/cache"
+	"backend-go/config"
+	"backend-go/database"
+	"backend-go/eventbus"
+	"backend-go/models"
+	"backend-go/repository"
+	"backend-go/service"
+
+	"gorm.io/gorm"
+)
+
+// runCommand executa um subcomando de manutenção em vez de subir o servidor.
+// Uso: main <comando> [flags]
+func runCommand(name string, args []string, db *gorm.DB, cfg config.Config) error {
+	switch name {
+	case "repair-orphans":
+		fs := flag.NewFlagSet(name, flag.ExitOnError)
+		deleteRows := fs.Bool("delete", false, "remove os registros órfãos (por padrão apenas reporta)")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		_, err := database.RepairOrphans(db, *deleteRows)
+		return err
+	case "backfill-rollups":
+		fs := flag.NewFlagSet(name, flag.ExitOnError)
+		fromFlag := fs.String("from", "", "primeiro dia a recalcular (YYYY-MM-DD); vazio recalcula todo o histórico")
+		toFlag := fs.String("to", "", "dia final, exclusivo (YYYY-MM-DD)")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		from, err := parseDayFlag(*fromFlag)
+		if err != nil {
+			return fmt.Errorf("--from inválido: %w", err)
+		}
+		to, err := parseDayFlag(*toFlag)
+		if err != nil {
+			return fmt.Errorf("--to inválido: %w", err)
+		}
+		return backfillRollups(db, cfg, from, to)
+	case "archive-interactions":
+		fs := flag.NewFlagSet(name, flag.ExitOnError)
+		beforeFlag := fs.String("before", "", "arquiva os meses anteriores a esta data (YYYY-MM-DD); padrão: janela de retenção configurada")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		before, err := parseDayFlag(*beforeFlag)
+		if err != nil {
+			return fmt.Errorf("--before inválido: %w", err)
+		}
+		return archiveInteractions(db, cfg, before)
+	case "restore-interactions":
+		fs := flag.NewFlagSet(name, flag.ExitOnError)
+		archiveID := fs.Uint("id", 0, "ID do arquivamento a restaurar")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if *archiveID == 0 {
+			return fmt.Errorf("--id é obrigatório")
+		}
+		return restoreInteractions(db, cfg, *archiveID)
+	case "list-archives":
+		return listArchives(db, cfg)
+	case "rebuild-affinity":
+		return rebuildAffinity(db, cfg)
+	default:
+		return fmt.Errorf("comando desconhecido: %s", name)
+	}
+}
+
+// backfillRollups recalcula os rollups do período e em seguida agrega as
+// interações que ainda estão depois do watermark
+func backfillRollups(db *gorm.DB, cfg config.Config, from, to *time.Time) error {
+	if err := database.AutoMigrate(db); err != nil {
+		return err
+	}
+
+	rollupService := service.NewRollupService(
+		repository.NewRollupRepository(db),
+		repository.NewArchiveRepository(db),
+		repository.NewContentRepository(db),
+		repository.NewUserRepository(db),
+	)
+
+	rebuilt, err := rollupService.Backfill(from, to)
+	if err != nil {
+		return err
+	}
+	log.Printf("[rollups] %d interações reagregadas\n", rebuilt)
+
+	caughtUp, err := rollupService.CatchUp()
+	if err != nil {
+		return err
+	}
+	log.Printf("[rollups] %d interações novas agregadas\n", caughtUp)
+	return nil
+}
+
+// archiveInteractions aplica a política de retenção (ou o corte informado)
+func archiveInteractions(db *gorm.DB, cfg config.Config, before *time.Time) error {
+	retentionService, err := newRetentionService(db, cfg)
+	if err != nil {
+		return err
+	}
+
+	var archives []models.InteractionArchive
+	switch {
+	case before != nil:
+		archives, err = retentionService.ArchiveBefore(*before)
+	case retentionService.Enabled():
+		archives, err = retentionService.Run()
+	default:
+		return fmt.Errorf("INTERACTION_RETENTION_MONTHS não configurado; informe --before")
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, archive := range archives {
+		log.Printf("[retention] arquivamento %d: %d interações de %s (%s)\n",
+			archive.ID, archive.Rows, archive.RangeStart.Format("2006-01"), archive.Storage)
+	}
+	log.Printf("[retention] %d períodos arquivados\n", len(archives))
+	return nil
+}
+
+// restoreInteractions devolve um período arquivado para user_interactions
+func restoreInteractions(db *gorm.DB, cfg config.Config, archiveID uint) error {
+	retentionService, err := newRetentionService(db, cfg)
+	if err != nil {
+		return err
+	}
+
+	archive, restored, err := retentionService.Restore(archiveID)
+	if err != nil {
+		return err
+	}
+	log.Printf("[retention] arquivamento %d (%s): %d de %d interações restauradas\n",
+		archive.ID, archive.RangeStart.Format("2006-01"), restored, archive.Rows)
+	return nil
+}
+
+// listArchives imprime os arquivamentos mais recentes
+func listArchives(db *gorm.DB, cfg config.Config) error {
+	retentionService, err := newRetentionService(db, cfg)
+	if err != nil {
+		return err
+	}
+
+	archives, total, err := retentionService.ListArchives(1, 100)
+	if err != nil {
+		return err
+	}
+	for _, archive := range archives {
+		fmt.Printf("%d\t%s\t%s\t%d\t%s\t%s\n",
+			archive.ID, archive.RangeStart.Format("2006-01"), archive.Storage,
+			archive.Rows, archive.Status, archive.Path)
+	}
+	fmt.Printf("total: %d\n", total)
+	return nil
+}
+
+func newRetentionService(db *gorm.DB, cfg config.Config) (service.RetentionService, error) {
+	if err := database.AutoMigrate(db); err != nil {
+		return nil, err
+	}
+	return service.NewRetentionService(
+		repository.NewArchiveRepository(db),
+		repository.NewRollupRepository(db),
+		repository.NewUserRepository(db),
+		repository.NewContentRepository(db),
+		retentionPolicy(cfg),
+	), nil
+}
+
+// retentionPolicy monta a política de retenção a partir da configuração
+func retentionPolicy(cfg config.Config) service.RetentionPolicy {
+	return service.RetentionPolicy{
+		Months:      cfg.InteractionRetentionMonths,
+		Storage:     cfg.InteractionArchiveStorage,
+		Dir:         cfg.InteractionArchiveDir,
+		RestoreHold: cfg.InteractionRestoreHold,
+	}
+}
+
+// trustedProxies retorna a lista de proxies confiáveis configurada; lista
+// vazia faz o gin ignorar X-Forwarded-For
+func trustedProxies(cfg config.Config) []string {
+	var proxies []string
+	for _, proxy := range strings.Split(cfg.TrustedProxies, ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			proxies = append(proxies, proxy)
+		}
+	}
+	return proxies
+}
+
+// rebuildAffinity recalcula todas as afinidades com os pesos configurados
+func rebuildAffinity(db *gorm.DB, cfg config.Config) error {
+	if err := database.AutoMigrate(db); err != nil {
+		return err
+	}
+	policy, err := newAffinityPolicy(cfg)
+	if err != nil {
+		return err
+	}
+
+	affinityService := service.NewAffinityService(
+		repository.NewAffinityRepository(db),
+		repository.NewRollupRepository(db),
+		repository.NewUserRepository(db),
+		repository.NewContentRepository(db),
+		policy,
+	)
+	rebuilt, err := affinityService.Rebuild()
+	if err != nil {
+		return err
+	}
+	log.Printf("[affinity] %d pares recalculados\n", rebuilt)
+	return nil
+}
+
+// newAffinityPolicy monta os pesos e a meia-vida das afinidades a partir da configuração
+func newAffinityPolicy(cfg config.Config) (service.AffinityPolicy, error) {
+	weights, err := service.ParseAffinityWeights(cfg.AffinityWeights)
+	if err != nil {
+		return service.AffinityPolicy{}, fmt.Errorf("AFFINITY_WEIGHTS inválido: %w", err)
+	}
+	return service.AffinityPolicy{Weights: weights, HalfLife: cfg.AffinityHalfLife}, nil
+}
+
+// recommenderClientPolicy monta timeouts, retentativas, hedge e circuit
+// breaker do cliente do motor Python a partir da configuração
+func recommenderClientPolicy(cfg config.Config) (service.RecommenderClientPolicy, error) {
+	timeouts, err := service.ParseMethodTimeouts(cfg.RecommenderMethodTimeouts)
+	if err != nil {
+		return service.RecommenderClientPolicy{}, fmt.Errorf("RECOMMENDER_METHOD_TIMEOUTS inválido: %w", err)
+	}
+	return service.RecommenderClientPolicy{
+		BaseURL:                 strings.TrimRight(cfg.RecommenderURL, "/"),
+		Timeouts:                timeouts,
+		DefaultTimeout:          cfg.RecommenderTimeout,
+		DeliveryTimeout:         cfg.RecommenderDeliveryTimeout,
+		MaxRetries:              cfg.RecommenderMaxRetries,
+		RetryBaseBackoff:        cfg.RecommenderRetryBaseBackoff,
+		RetryMaxBackoff:         cfg.RecommenderRetryMaxBackoff,
+		HedgeDelay:              cfg.RecommenderHedgeDelay,
+		BreakerFailureThreshold: cfg.RecommenderBreakerFailures,
+		BreakerOpenTimeout:      cfg.RecommenderBreakerOpenTimeout,
+		BreakerHalfOpenProbes:   cfg.RecommenderBreakerHalfOpenProbes,
+	}, nil
+}
+
+// newRecommendationCache cria o cache de recomendações escolhido em RECOMMENDATION_CACHE
+func newRecommendationCache(cfg config.Config) (service.RecommendationCache, error) {
+	policy := service.RecommendationCachePolicy{
+		TTL:   cfg.RecommendationCacheTTL,
+		Stale: cfg.RecommendationCacheStale,
+	}
+	switch cfg.RecommendationCache {
+	case "", "memory":
+		return service.NewRecommendationCache(cache.NewLRU(cfg.RecommendationCacheSize), policy), nil
+	case "none":
+		return service.NewRecommendationCache(nil, policy), nil
+	default:
+		return nil, fmt.Errorf("RECOMMENDATION_CACHE inválido: %q (use memory ou none)", cfg.RecommendationCache)
+	}
+}
+
+// fallbackPolicy monta o ranking usado quando o motor de recomendação falha
+func fallbackPolicy(cfg config.Config, affinity service.AffinityPolicy) service.FallbackPolicy {
+	return service.FallbackPolicy{
+		PopularityWindow: cfg.FallbackPopularityWindow,
+		Weights:          affinity.PopularityWeights(),
+		RecencyHalfLife:  cfg.FallbackRecencyHalfLife,
+		RecencyWeight:    cfg.FallbackRecencyWeight,
+	}
+}
+
+// outboxPolicy monta as tentativas e o backoff do outbox a partir da configuração
+func outboxPolicy(cfg config.Config) service.OutboxPolicy {
+	return service.OutboxPolicy{
+		MaxAttempts:        cfg.OutboxMaxAttempts,
+		BaseBackoff:        cfg.OutboxBaseBackoff,
+		MaxBackoff:         cfg.OutboxMaxBackoff,
+		DeliveredRetention: cfg.OutboxDeliveredRetention,
+	}
+}
+
+// newEventBus abre o barramento de eventos escolhido em EVENT_BUS
+func newEventBus(cfg config.Config) (eventbus.Bus, error) {
+	switch cfg.EventBus {
+	case "", "inprocess":
+		return eventbus.NewInProcessBus(), nil
+	case "embedded":
+		return eventbus.NewEmbeddedBroker(eventbus.BrokerOptions{
+			Dir:         cfg.EventBusDir,
+			MaxMessages: cfg.EventBusMaxMessages,
+			MaxAge:      cfg.EventBusMaxAge,
+		})
+	default:
+		return nil, fmt.Errorf("EVENT_BUS inválido: %q (use inprocess ou embedded)", cfg.EventBus)
+	}
+}
+
+// interactionDelivery escolhe, a partir de RECOMMENDER_DELIVERY, como o
+// outbox entrega as interações ao motor Python
+func interactionDelivery(
+	cfg config.Config,
+	bus eventbus.Bus,
+	recommendationService service.RecommendationService,
+) (service.InteractionDelivery, error) {
+	switch cfg.RecommenderDelivery {
+	case "", service.RecommenderDeliveryHTTP:
+		return recommendationService, nil
+	case service.RecommenderDeliveryBus:
+		// O motor consome por pull, então as mensagens precisam ficar no broker
+		if _, ok := bus.(eventbus.Stream); !ok {
+			return nil, errors.New("RECOMMENDER_DELIVERY=bus exige EVENT_BUS=embedded")
+		}
+		return service.NewBusInteractionDelivery(bus), nil
+	default:
+		return nil, fmt.Errorf("RECOMMENDER_DELIVERY inválido: %q (use http ou bus)", cfg.RecommenderDelivery)
+	}
+}
+
+// webhookPolicy monta as tentativas, o backoff e o timeout dos webhooks a partir da configuração
+func webhookPolicy(cfg config.Config) service.WebhookPolicy {
+	return service.WebhookPolicy{
+		MaxAttempts: cfg.WebhookMaxAttempts,
+		BaseBackoff: cfg.WebhookBaseBackoff,
+		MaxBackoff:  cfg.WebhookMaxBackoff,
+		Timeout:     cfg.WebhookTimeout,
+	}
+}
+
+func parseDayFlag(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	return &day, nil
+}
@@ -15,11 +15,13 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"backend-go/config"
 	"backend-go/database"
 	_ "backend-go/docs"
 	"backend-go/handler"
+	"backend-go/middleware"
 	"backend-go/repository"
 	"backend-go/routes"
 	"backend-go/service"
@@ -43,6 +45,14 @@ func main() {
 		log.Fatalf("erro ao conectar no banco: %v", err)
 	}
 
+	// Subcomandos de manutenção (ex.: repair-orphans --delete, backfill-rollups)
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1], os.Args[2:], db, cfg); err != nil {
+			log.Fatalf("erro ao executar %s: %v", os.Args[1], err)
+		}
+		return
+	}
+
 	// Migração e seeds
 	if err := database.AutoMigrate(db); err != nil {
 		log.Fatalf("erro ao migrar: %v", err)
@@ -56,26 +66,293 @@ func main() {
 	userService := service.NewUserService(userRepo)
 	userHandler := handler.NewUserHandler(userService)
 
+	// Barramento de eventos (conteúdos, interações e recomendações)
+	bus, err := newEventBus(cfg)
+	if err != nil {
+		log.Fatalf("erro ao abrir barramento de eventos: %v", err)
+	}
+	eventBusHandler := handler.NewEventBusHandler(bus)
+
+	// Injeção de dependências - Webhooks (eventos de conteúdos e interações)
+	// Os eventos chegam pelo outbox, gravado na transação de cada escrita
+	outboxRepo := repository.NewOutboxRepository(db)
+	webhookRepo := repository.NewWebhookRepository(db)
+	webhookService := service.NewWebhookService(webhookRepo, outboxRepo, webhookPolicy(cfg))
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+
+	// Injeção de dependências - Stream de eventos (SSE)
+	eventStreamService := service.NewEventStreamService(service.StreamPolicy{
+		BufferSize:       cfg.EventStreamBuffer,
+		ClientBufferSize: cfg.EventStreamClientBuffer,
+	})
+	eventStreamHandler := handler.NewEventStreamHandler(eventStreamService, cfg.EventStreamHeartbeat)
+
+	// Injeção de dependências - Cache de recomendações
+	recommendationCache, err := newRecommendationCache(cfg)
+	if err != nil {
+		log.Fatalf("erro ao configurar cache de recomendações: %v", err)
+	}
+	recommendationCacheHandler := handler.NewRecommendationCacheHandler(recommendationCache)
+
+	// Eventos de domínio vão para o stream e o cache de recomendações, que
+	// descarta as listas de quem interagiu. O barramento e os webhooks os
+	// recebem pelo outbox.
+	eventEmitter := service.NewMultiEmitter(eventStreamService, recommendationCache)
+
 	// Injeção de dependências - Contents
 	contentRepo := repository.NewContentRepository(db)
-	contentService := service.NewContentService(contentRepo)
-	contentHandler := handler.NewContentHandler(contentService)
-
-	// Injeção de dependências - Recommendations (criado antes para ser injetado em Interactions)
-	recommendationService := service.NewRecommendationService()
-	recommendationHandler := handler.NewRecommendationHandler(recommendationService)
+	contentService := service.NewContentService(contentRepo, eventEmitter)
 
 	// Injeção de dependências - Interactions
 	interactionRepo := repository.NewInteractionRepository(db)
-	interactionService := service.NewInteractionService(interactionRepo)
-	interactionHandler := handler.NewInteractionHandler(interactionService, recommendationService)
+	interactionService := service.NewInteractionService(interactionRepo, userRepo, contentRepo, service.DefaultAbusePolicy(), eventEmitter)
+	interactionHandler := handler.NewInteractionHandler(interactionService)
+
+	// Injeção de dependências - Bookmarks (usado para marcar conteúdos salvos)
+	bookmarkRepo := repository.NewBookmarkRepository(db)
+	bookmarkService := service.NewBookmarkService(bookmarkRepo, interactionService)
+	bookmarkHandler := handler.NewBookmarkHandler(bookmarkService)
+
+	contentHandler := handler.NewContentHandler(contentService, bookmarkService)
+
+	// Injeção de dependências - Collections
+	collectionRepo := repository.NewCollectionRepository(db)
+	collectionService := service.NewCollectionService(collectionRepo, contentRepo, userRepo)
+	collectionHandler := handler.NewCollectionHandler(collectionService)
+
+	// Pesos de afinidade, compartilhados com a popularidade do fallback e o item-item
+	affinityPolicy, err := newAffinityPolicy(cfg)
+	if err != nil {
+		log.Fatalf("erro na configuração de afinidade: %v", err)
+	}
+	rollupRepo := repository.NewRollupRepository(db)
+
+	// Injeção de dependências - Recomendações item-item em processo (method=item_cf)
+	itemCFService := service.NewItemCFService(rollupRepo, affinityPolicy)
+	itemCFHandler := handler.NewItemCFHandler(itemCFService)
+
+	// Injeção de dependências - Ranking de popularidade e recência (fallback do motor)
+	rankingRepo := repository.NewRankingRepository(db)
+	fallbackRanker := service.NewFallbackRanker(rankingRepo, fallbackPolicy(cfg, affinityPolicy))
+
+	// Injeção de dependências - Cliente do motor Python
+	clientPolicy, err := recommenderClientPolicy(cfg)
+	if err != nil {
+		log.Fatalf("erro na configuração do motor de recomendação: %v", err)
+	}
+	recommenderClient := service.NewRecommenderClient(clientPolicy)
+	recommenderHandler := handler.NewRecommenderHandler(recommenderClient)
+
+	// Injeção de dependências - Histórico de recomendações servidas
+	recommendationRepo := repository.NewRecommendationRepository(db)
+	recommendationHistoryService := service.NewRecommendationHistoryService(recommendationRepo, userRepo, cfg.RecommendationHistoryRetention)
+
+	// Injeção de dependências - Recommendations
+	recommendationService := service.NewRecommendationService(recommenderClient, contentRepo, collectionRepo, itemCFService, fallbackRanker, recommendationHistoryService, recommendationCache)
+	recommendationHandler := handler.NewRecommendationHandler(recommendationService, recommendationHistoryService, contentService, bookmarkService)
+
+	// Injeção de dependências - Outbox (notificações ao motor Python)
+	delivery, err := interactionDelivery(cfg, bus, recommendationService)
+	if err != nil {
+		log.Fatalf("erro na configuração do outbox: %v", err)
+	}
+	outboxService := service.NewOutboxService(outboxRepo, delivery, outboxPolicy(cfg))
+	eventBusRelay := service.NewEventBusRelay(outboxRepo, bus, outboxPolicy(cfg))
+	outboxHandler := handler.NewOutboxHandler(outboxService)
+
+	// Injeção de dependências - Moderation
+	reportRepo := repository.NewReportRepository(db)
+	moderationService := service.NewModerationService(reportRepo, contentRepo, userRepo, cfg.ReportHideThreshold)
+	moderationHandler := handler.NewModerationHandler(moderationService)
+
+	// Injeção de dependências - Comments
+	commentRepo := repository.NewCommentRepository(db)
+	commentService := service.NewCommentService(commentRepo, contentRepo, interactionService)
+	commentHandler := handler.NewCommentHandler(commentService)
+
+	// Injeção de dependências - Idempotency
+	idempotencyRepo := repository.NewIdempotencyRepository(db)
+	idempotencyService := service.NewIdempotencyService(idempotencyRepo, cfg.IdempotencyTTL)
+
+	// Injeção de dependências - Analytics (rollups diários de interações)
+	archiveRepo := repository.NewArchiveRepository(db)
+	rollupService := service.NewRollupService(rollupRepo, archiveRepo, contentRepo, userRepo)
+	analyticsHandler := handler.NewAnalyticsHandler(rollupService)
+
+	// Injeção de dependências - Afinidade usuário-conteúdo
+	affinityRepo := repository.NewAffinityRepository(db)
+	affinityService := service.NewAffinityService(affinityRepo, rollupRepo, userRepo, contentRepo, affinityPolicy)
+	affinityHandler := handler.NewAffinityHandler(affinityService)
+
+	// Injeção de dependências - Retenção de interações
+	retentionService := service.NewRetentionService(archiveRepo, rollupRepo, userRepo, contentRepo, retentionPolicy(cfg))
+
+	// Limites de escrita em /interactions
+	ipRateLimiter := middleware.NewRateLimiter(cfg.InteractionIPRatePerMinute, cfg.InteractionIPRateBurst)
+	userRateLimiter := middleware.NewRateLimiter(cfg.InteractionUserRatePerMinute, cfg.InteractionUserRateBurst)
 
 	router := routes.NewRouter(
 		userHandler,
 		contentHandler,
 		interactionHandler,
 		recommendationHandler,
+		moderationHandler,
+		commentHandler,
+		collectionHandler,
+		bookmarkHandler,
+		analyticsHandler,
+		affinityHandler,
+		outboxHandler,
+		webhookHandler,
+		eventStreamHandler,
+		eventBusHandler,
+		itemCFHandler,
+		recommenderHandler,
+		recommendationCacheHandler,
+		idempotencyService,
+		ipRateLimiter,
+		userRateLimiter,
 	).SetupRoutes()
+	if err := router.SetTrustedProxies(trustedProxies(cfg)); err != nil {
+		log.Fatalf("TRUSTED_PROXIES inválido: %v", err)
+	}
+
+	// Limpa periodicamente as chaves de idempotência expiradas
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, err := idempotencyService.PurgeExpired(); err != nil {
+				log.Printf("erro ao limpar chaves de idempotência: %v", err)
+			}
+		}
+	}()
+
+	// Remove periodicamente as recomendações servidas fora da janela de retenção
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			if _, err := recommendationHistoryService.Purge(); err != nil {
+				log.Printf("erro ao limpar histórico de recomendações: %v", err)
+			}
+		}
+	}()
+
+	// Mantém os rollups diários em dia a partir do watermark
+	go func() {
+		ticker := time.NewTicker(cfg.RollupInterval)
+		defer ticker.Stop()
+		for {
+			if _, err := rollupService.CatchUp(); err != nil {
+				log.Printf("erro ao agregar rollups de interações: %v", err)
+			}
+			<-ticker.C
+		}
+	}()
+
+	// Atualiza as afinidades dos pares com interações novas
+	go func() {
+		ticker := time.NewTicker(cfg.AffinityInterval)
+		defer ticker.Stop()
+		for {
+			if _, err := affinityService.CatchUp(); err != nil {
+				log.Printf("erro ao atualizar afinidades: %v", err)
+			}
+			<-ticker.C
+		}
+	}()
+
+	// Carrega o modelo item-item, incorpora as interações novas e o recalcula
+	// por completo periodicamente
+	go func() {
+		if _, err := itemCFService.Rebuild(); err != nil {
+			log.Printf("erro ao calcular modelo item-item: %v", err)
+		}
+		catchUp := time.NewTicker(cfg.ItemCFInterval)
+		defer catchUp.Stop()
+		rebuild := time.NewTicker(cfg.ItemCFRebuildInterval)
+		defer rebuild.Stop()
+		for {
+			select {
+			case <-catchUp.C:
+				if _, err := itemCFService.CatchUp(); err != nil {
+					log.Printf("erro ao atualizar modelo item-item: %v", err)
+				}
+			case <-rebuild.C:
+				if _, err := itemCFService.Rebuild(); err != nil {
+					log.Printf("erro ao recalcular modelo item-item: %v", err)
+				}
+			}
+		}
+	}()
+
+	// Entrega os eventos do outbox ao motor Python e ao barramento. O worker é
+	// parado antes de fechar o banco; o que ficar pendente é entregue na
+	// próxima inicialização.
+	stopOutbox := make(chan struct{})
+	outboxDone := make(chan struct{})
+	go func() {
+		defer close(outboxDone)
+		ticker := time.NewTicker(cfg.OutboxInterval)
+		defer ticker.Stop()
+		purge := time.NewTicker(time.Hour)
+		defer purge.Stop()
+		for {
+			if _, err := outboxService.Dispatch(); err != nil {
+				log.Printf("erro ao entregar eventos do outbox: %v", err)
+			}
+			if _, err := eventBusRelay.Dispatch(); err != nil {
+				log.Printf("erro ao publicar eventos do outbox no barramento: %v", err)
+			}
+			select {
+			case <-stopOutbox:
+				return
+			case <-purge.C:
+				if _, err := outboxService.PurgeDelivered(); err != nil {
+					log.Printf("erro ao limpar eventos entregues do outbox: %v", err)
+				}
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	// Entrega os webhooks pendentes aos parceiros
+	stopWebhooks := make(chan struct{})
+	webhooksDone := make(chan struct{})
+	go func() {
+		defer close(webhooksDone)
+		ticker := time.NewTicker(cfg.WebhookInterval)
+		defer ticker.Stop()
+		for {
+			if _, err := webhookService.Dispatch(); err != nil {
+				log.Printf("erro ao entregar webhooks: %v", err)
+			}
+			select {
+			case <-stopWebhooks:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	// Arquiva diariamente as interações fora da janela de retenção
+	if retentionService.Enabled() {
+		go func() {
+			ticker := time.NewTicker(24 * time.Hour)
+			defer ticker.Stop()
+			for range ticker.C {
+				archives, err := retentionService.Run()
+				if err != nil {
+					log.Printf("erro ao arquivar interações: %v", err)
+				}
+				for _, archive := range archives {
+					log.Printf("[retention] %d interações de %s arquivadas (%s)",
+						archive.Rows, archive.RangeStart.Format("2006-01"), archive.Storage)
+				}
+			}
+		}()
+	}
 
 	// Sobe o servidor em goroutine para permitir shutdown graceful
 	go func() {
@@ -93,6 +370,17 @@ func main() {
 
 	log.Println("desligando servidor...")
 
+	// Aguarda as entregas em andamento do outbox e dos webhooks terminarem
+	close(stopOutbox)
+	close(stopWebhooks)
+	<-outboxDone
+	<-webhooksDone
+
+	// Grava o estado do barramento depois que o outbox parou de publicar
+	if err := bus.Close(); err != nil {
+		log.Printf("erro ao fechar barramento de eventos: %v", err)
+	}
+
 	// Fecha conexões do GORM
 	if sqlDB, err := db.DB(); err == nil {
 		_ = sqlDB.Close()
@@ -17,6 +17,92 @@ type Config struct {
 	DBSSL    string `mapstructure:"DB_SSLMODE"`
 	DBDriver string `mapstructure:"DB_DRIVER"`
 	TimeZone string `mapstructure:"TZ"`
+
+	ReportHideThreshold int           `mapstructure:"REPORT_HIDE_THRESHOLD"`
+	IdempotencyTTL      time.Duration `mapstructure:"IDEMPOTENCY_TTL"`
+	RollupInterval      time.Duration `mapstructure:"ROLLUP_INTERVAL"`
+
+	// Retenção de interações (0 meses desativa o arquivamento agendado)
+	InteractionRetentionMonths int           `mapstructure:"INTERACTION_RETENTION_MONTHS"`
+	InteractionArchiveStorage  string        `mapstructure:"INTERACTION_ARCHIVE_STORAGE"`
+	InteractionArchiveDir      string        `mapstructure:"INTERACTION_ARCHIVE_DIR"`
+	InteractionRestoreHold     time.Duration `mapstructure:"INTERACTION_RESTORE_HOLD"`
+
+	// Limites de escrita em /interactions (token bucket por usuário e por IP)
+	InteractionUserRatePerMinute int `mapstructure:"INTERACTION_USER_RATE_PER_MINUTE"`
+	InteractionUserRateBurst     int `mapstructure:"INTERACTION_USER_RATE_BURST"`
+	InteractionIPRatePerMinute   int `mapstructure:"INTERACTION_IP_RATE_PER_MINUTE"`
+	InteractionIPRateBurst       int `mapstructure:"INTERACTION_IP_RATE_BURST"`
+
+	// Proxies confiáveis para X-Forwarded-For, separados por vírgula (IPs ou
+	// CIDRs). Vazio: o IP do cliente é sempre o da conexão, e o header não é
+	// usado pelos limites por IP.
+	TrustedProxies string `mapstructure:"TRUSTED_PROXIES"`
+
+	// Afinidade usuário-conteúdo: pesos por tipo de interação ("view=1,like=3")
+	// e meia-vida do decaimento exponencial
+	AffinityWeights  string        `mapstructure:"AFFINITY_WEIGHTS"`
+	AffinityHalfLife time.Duration `mapstructure:"AFFINITY_HALF_LIFE"`
+	AffinityInterval time.Duration `mapstructure:"AFFINITY_INTERVAL"`
+
+	// Recomendações item-item em processo: atualização incremental e recálculo completo
+	ItemCFInterval        time.Duration `mapstructure:"ITEM_CF_INTERVAL"`
+	ItemCFRebuildInterval time.Duration `mapstructure:"ITEM_CF_REBUILD_INTERVAL"`
+
+	// Cliente do motor Python: timeouts por método ("similarity=3s"), retentativas,
+	// hedge e circuit breaker
+	RecommenderURL                   string        `mapstructure:"RECOMMENDER_URL"`
+	RecommenderTimeout               time.Duration `mapstructure:"RECOMMENDER_TIMEOUT"`
+	RecommenderMethodTimeouts        string        `mapstructure:"RECOMMENDER_METHOD_TIMEOUTS"`
+	RecommenderDeliveryTimeout       time.Duration `mapstructure:"RECOMMENDER_DELIVERY_TIMEOUT"`
+	RecommenderMaxRetries            int           `mapstructure:"RECOMMENDER_MAX_RETRIES"`
+	RecommenderRetryBaseBackoff      time.Duration `mapstructure:"RECOMMENDER_RETRY_BASE_BACKOFF"`
+	RecommenderRetryMaxBackoff       time.Duration `mapstructure:"RECOMMENDER_RETRY_MAX_BACKOFF"`
+	RecommenderHedgeDelay            time.Duration `mapstructure:"RECOMMENDER_HEDGE_DELAY"`
+	RecommenderBreakerFailures       int           `mapstructure:"RECOMMENDER_BREAKER_FAILURES"`
+	RecommenderBreakerOpenTimeout    time.Duration `mapstructure:"RECOMMENDER_BREAKER_OPEN_TIMEOUT"`
+	RecommenderBreakerHalfOpenProbes int           `mapstructure:"RECOMMENDER_BREAKER_HALF_OPEN_PROBES"`
+
+	// Cache de recomendações: "memory" (LRU) ou "none"
+	RecommendationCache      string        `mapstructure:"RECOMMENDATION_CACHE"`
+	RecommendationCacheSize  int           `mapstructure:"RECOMMENDATION_CACHE_SIZE"`
+	RecommendationCacheTTL   time.Duration `mapstructure:"RECOMMENDATION_CACHE_TTL"`
+	RecommendationCacheStale time.Duration `mapstructure:"RECOMMENDATION_CACHE_STALE"`
+
+	// Retenção do histórico de recomendações servidas (0 mantém tudo)
+	RecommendationHistoryRetention time.Duration `mapstructure:"RECOMMENDATION_HISTORY_RETENTION"`
+
+	// Ranking de popularidade e recência usado quando o motor Python falha
+	FallbackPopularityWindow time.Duration `mapstructure:"FALLBACK_POPULARITY_WINDOW"`
+	FallbackRecencyHalfLife  time.Duration `mapstructure:"FALLBACK_RECENCY_HALF_LIFE"`
+	FallbackRecencyWeight    float64       `mapstructure:"FALLBACK_RECENCY_WEIGHT"`
+
+	// Outbox de notificações ao motor Python
+	OutboxInterval           time.Duration `mapstructure:"OUTBOX_INTERVAL"`
+	OutboxMaxAttempts        int           `mapstructure:"OUTBOX_MAX_ATTEMPTS"`
+	OutboxBaseBackoff        time.Duration `mapstructure:"OUTBOX_BASE_BACKOFF"`
+	OutboxMaxBackoff         time.Duration `mapstructure:"OUTBOX_MAX_BACKOFF"`
+	OutboxDeliveredRetention time.Duration `mapstructure:"OUTBOX_DELIVERED_RETENTION"`
+
+	// Entrega de webhooks aos parceiros
+	WebhookInterval    time.Duration `mapstructure:"WEBHOOK_INTERVAL"`
+	WebhookMaxAttempts int           `mapstructure:"WEBHOOK_MAX_ATTEMPTS"`
+	WebhookBaseBackoff time.Duration `mapstructure:"WEBHOOK_BASE_BACKOFF"`
+	WebhookMaxBackoff  time.Duration `mapstructure:"WEBHOOK_MAX_BACKOFF"`
+	WebhookTimeout     time.Duration `mapstructure:"WEBHOOK_TIMEOUT"`
+
+	// Stream (SSE) de atividade em tempo real
+	EventStreamBuffer       int           `mapstructure:"EVENT_STREAM_BUFFER"`
+	EventStreamClientBuffer int           `mapstructure:"EVENT_STREAM_CLIENT_BUFFER"`
+	EventStreamHeartbeat    time.Duration `mapstructure:"EVENT_STREAM_HEARTBEAT"`
+
+	// Barramento de eventos: "inprocess" ou "embedded" (broker com stream em disco)
+	EventBus            string        `mapstructure:"EVENT_BUS"`
+	EventBusDir         string        `mapstructure:"EVENT_BUS_DIR"`
+	EventBusMaxMessages int           `mapstructure:"EVENT_BUS_MAX_MESSAGES"`
+	EventBusMaxAge      time.Duration `mapstructure:"EVENT_BUS_MAX_AGE"`
+	// Entrega das interações do outbox ao motor Python: "http" ou "bus"
+	RecommenderDelivery string `mapstructure:"RECOMMENDER_DELIVERY"`
 }
 
 func LoadConfig(path string) (Config, error) {
@@ -30,6 +116,60 @@ func LoadConfig(path string) (Config, error) {
 	viper.SetDefault("DB_SSLMODE", "disable")
 	viper.SetDefault("DB_DRIVER", "mysql")
 	viper.SetDefault("TZ", "UTC")
+	viper.SetDefault("REPORT_HIDE_THRESHOLD", 3)
+	viper.SetDefault("IDEMPOTENCY_TTL", "24h")
+	viper.SetDefault("ROLLUP_INTERVAL", "5m")
+	viper.SetDefault("INTERACTION_RETENTION_MONTHS", 0)
+	viper.SetDefault("INTERACTION_ARCHIVE_STORAGE", "table")
+	viper.SetDefault("INTERACTION_ARCHIVE_DIR", "archive")
+	viper.SetDefault("INTERACTION_RESTORE_HOLD", "168h")
+	viper.SetDefault("INTERACTION_USER_RATE_PER_MINUTE", 60)
+	viper.SetDefault("INTERACTION_USER_RATE_BURST", 20)
+	viper.SetDefault("INTERACTION_IP_RATE_PER_MINUTE", 300)
+	viper.SetDefault("INTERACTION_IP_RATE_BURST", 100)
+	viper.SetDefault("TRUSTED_PROXIES", "")
+	viper.SetDefault("AFFINITY_WEIGHTS", "view=1,like=3,dislike=-4,rating=1.5,share=2,comment=2,bookmark=2.5")
+	viper.SetDefault("AFFINITY_HALF_LIFE", "720h")
+	viper.SetDefault("AFFINITY_INTERVAL", "1m")
+	viper.SetDefault("ITEM_CF_INTERVAL", "30s")
+	viper.SetDefault("ITEM_CF_REBUILD_INTERVAL", "6h")
+	viper.SetDefault("RECOMMENDER_URL", "http://recommender:8000")
+	viper.SetDefault("RECOMMENDER_TIMEOUT", "3s")
+	viper.SetDefault("RECOMMENDER_METHOD_TIMEOUTS", "popularity=1s")
+	viper.SetDefault("RECOMMENDER_DELIVERY_TIMEOUT", "10s")
+	viper.SetDefault("RECOMMENDER_MAX_RETRIES", 2)
+	viper.SetDefault("RECOMMENDER_RETRY_BASE_BACKOFF", "100ms")
+	viper.SetDefault("RECOMMENDER_RETRY_MAX_BACKOFF", "1s")
+	viper.SetDefault("RECOMMENDER_HEDGE_DELAY", "500ms")
+	viper.SetDefault("RECOMMENDER_BREAKER_FAILURES", 5)
+	viper.SetDefault("RECOMMENDER_BREAKER_OPEN_TIMEOUT", "30s")
+	viper.SetDefault("RECOMMENDER_BREAKER_HALF_OPEN_PROBES", 1)
+	viper.SetDefault("RECOMMENDATION_CACHE", "memory")
+	viper.SetDefault("RECOMMENDATION_CACHE_SIZE", 10000)
+	viper.SetDefault("RECOMMENDATION_CACHE_TTL", "5m")
+	viper.SetDefault("RECOMMENDATION_CACHE_STALE", "10m")
+	viper.SetDefault("RECOMMENDATION_HISTORY_RETENTION", "2160h")
+	viper.SetDefault("FALLBACK_POPULARITY_WINDOW", "720h")
+	viper.SetDefault("FALLBACK_RECENCY_HALF_LIFE", "336h")
+	viper.SetDefault("FALLBACK_RECENCY_WEIGHT", 0.5)
+	viper.SetDefault("OUTBOX_INTERVAL", "2s")
+	viper.SetDefault("OUTBOX_MAX_ATTEMPTS", 12)
+	viper.SetDefault("OUTBOX_BASE_BACKOFF", "5s")
+	viper.SetDefault("OUTBOX_MAX_BACKOFF", "1h")
+	viper.SetDefault("OUTBOX_DELIVERED_RETENTION", "168h")
+	viper.SetDefault("WEBHOOK_INTERVAL", "5s")
+	viper.SetDefault("WEBHOOK_MAX_ATTEMPTS", 8)
+	viper.SetDefault("WEBHOOK_BASE_BACKOFF", "30s")
+	viper.SetDefault("WEBHOOK_MAX_BACKOFF", "6h")
+	viper.SetDefault("WEBHOOK_TIMEOUT", "10s")
+	viper.SetDefault("EVENT_STREAM_BUFFER", 1000)
+	viper.SetDefault("EVENT_STREAM_CLIENT_BUFFER", 64)
+	viper.SetDefault("EVENT_STREAM_HEARTBEAT", "15s")
+	viper.SetDefault("EVENT_BUS", "inprocess")
+	viper.SetDefault("EVENT_BUS_DIR", "data/eventbus")
+	viper.SetDefault("EVENT_BUS_MAX_MESSAGES", 100000)
+	viper.SetDefault("EVENT_BUS_MAX_AGE", "168h")
+	viper.SetDefault("RECOMMENDER_DELIVERY", "http")
 
 	var cfg Config
 	if err := viper.ReadInConfig(); err != nil {
@@ -43,6 +183,31 @@ func LoadConfig(path string) (Config, error) {
 		cfg.DBPass = viper.GetString("DB_PASS")
 	}
 
+	if cfg.RollupInterval <= 0 {
+		cfg.RollupInterval = 5 * time.Minute
+	}
+	if cfg.AffinityInterval <= 0 {
+		cfg.AffinityInterval = time.Minute
+	}
+	if cfg.ItemCFInterval <= 0 {
+		cfg.ItemCFInterval = 30 * time.Second
+	}
+	if cfg.ItemCFRebuildInterval <= 0 {
+		cfg.ItemCFRebuildInterval = 6 * time.Hour
+	}
+	if cfg.RecommenderTimeout <= 0 {
+		cfg.RecommenderTimeout = 3 * time.Second
+	}
+	if cfg.FallbackPopularityWindow <= 0 {
+		cfg.FallbackPopularityWindow = 30 * 24 * time.Hour
+	}
+	if cfg.OutboxInterval <= 0 {
+		cfg.OutboxInterval = 2 * time.Second
+	}
+	if cfg.WebhookInterval <= 0 {
+		cfg.WebhookInterval = 5 * time.Second
+	}
+
 	loc, err := time.LoadLocation(cfg.TimeZone)
 	if err != nil {
 		return Config{}, fmt.Errorf("timezone inválido: %w", err)
@@ -67,13 +67,45 @@ func NewDatabase(cfg config.Config) (*gorm.DB, error) {
 	return db, nil
 }
 
+// AutoMigrate cria e atualiza as tabelas. Recusa a migração se houver dados
+// que impeçam as restrições dos modelos (ver checkMigration).
 func AutoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(
+	if err := checkMigration(db); err != nil {
+		return err
+	}
+	if err := db.AutoMigrate(
 		&models.User{},
 		&models.Content{},
 		&models.Category{},
 		&models.UserInteraction{},
 		&models.Recommendation{},
 		&models.ContentCategory{},
-	)
+		&models.ContentReport{},
+		&models.ModerationDecision{},
+		&models.Comment{},
+		&models.Collection{},
+		&models.CollectionItem{},
+		&models.Bookmark{},
+		&models.IdempotencyKey{},
+		&models.UserReaction{},
+		&models.ContentDailyStat{},
+		&models.UserDailyStat{},
+		&models.RollupWatermark{},
+		&models.RollupGap{},
+		&models.InteractionArchive{},
+		&models.ArchivedInteraction{},
+		&models.UserContentAffinity{},
+		&models.OutboxEvent{},
+		&models.WebhookSubscription{},
+		&models.WebhookDelivery{},
+	); err != nil {
+		return err
+	}
+	if err := backfillReceivedAt(db); err != nil {
+		return err
+	}
+	if err := backfillRatedAt(db); err != nil {
+		return err
+	}
+	return backfillHiddenReason(db)
 }
@@ -0,0 +1,65 @@
+package database
+
+import (
+	"fmt"
+
+	"backend-go/models"
+
+	"gorm.io/gorm"
+)
+
+// duplicateReportsFilter seleciona as denúncias repetidas de um mesmo usuário
+// para um mesmo conteúdo, preservando a mais antiga de cada par
+const duplicateReportsFilter = "id NOT IN (SELECT keep_id FROM (SELECT MIN(id) AS keep_id FROM content_reports GROUP BY content_id, user_id) AS kept)"
+
+// checkMigration recusa a migração enquanto houver dados que impedem a
+// criação das restrições dos modelos, indicando o comando que os corrige
+func checkMigration(db *gorm.DB) error {
+	report, err := countOrphans(db)
+	if err != nil {
+		return err
+	}
+	if report.Interactions > 0 || report.Recommendations > 0 {
+		return fmt.Errorf("%d interações e %d recomendações órfãs (usuário ou conteúdo inexistente) impedem as chaves estrangeiras; rode \"repair-orphans --delete\" antes de subir o servidor", report.Interactions, report.Recommendations)
+	}
+	if report.DuplicateReports > 0 {
+		return fmt.Errorf("%d denúncias duplicadas (mesmo usuário e conteúdo) impedem o índice único de content_reports; rode \"repair-orphans --delete\" antes de subir o servidor", report.DuplicateReports)
+	}
+	return nil
+}
+
+// countDuplicateReports conta as denúncias que seriam removidas por duplicateReportsFilter
+func countDuplicateReports(db *gorm.DB) (int64, error) {
+	var count int64
+	if !db.Migrator().HasTable("content_reports") {
+		return 0, nil
+	}
+	err := db.Table("content_reports").Where(duplicateReportsFilter).Count(&count).Error
+	return count, err
+}
+
+// backfillReceivedAt preenche received_at das interações gravadas antes da
+// coluna existir com o próprio created_at
+func backfillReceivedAt(db *gorm.DB) error {
+	return db.Model(&models.UserInteraction{}).
+		Where("received_at IS NULL").
+		Update("received_at", gorm.Expr("created_at")).Error
+}
+
+// backfillRatedAt preenche rated_at das notas gravadas antes da coluna
+// existir com reacted_at, a marca única usada até então
+func backfillRatedAt(db *gorm.DB) error {
+	return db.Model(&models.UserReaction{}).
+		Where("rated_at IS NULL AND rating IS NOT NULL").
+		Update("rated_at", gorm.Expr("reacted_at")).Error
+}
+
+// backfillHiddenReason marca como ocultação automática os conteúdos ocultos
+// antes de hidden_reason existir cuja única decisão foi o auto_hide
+func backfillHiddenReason(db *gorm.DB) error {
+	return db.Model(&models.Content{}).
+		Where("hidden = ? AND hidden_reason IS NULL", true).
+		Where("id IN (SELECT content_id FROM moderation_decisions WHERE action = ?)", models.ModerationActionAutoHide).
+		Where("id NOT IN (SELECT content_id FROM moderation_decisions WHERE action = ?)", models.ModerationActionAction).
+		Update("hidden_reason", models.HiddenReasonReports).Error
+}
@@ -0,0 +1,103 @@
+package database
+
+import (
+	"log"
+
+	"gorm.io/gorm"
+)
+
+// OrphanReport resume os registros que referenciam usuários ou conteúdos
+// inexistentes e as denúncias duplicadas
+type OrphanReport struct {
+	Interactions     int64
+	Recommendations  int64
+	DuplicateReports int64
+	Deleted          bool
+}
+
+const (
+	orphanInteractionsFilter    = "user_id NOT IN (SELECT id FROM users) OR content_id NOT IN (SELECT id FROM contents)"
+	orphanRecommendationsFilter = "user_id NOT IN (SELECT id FROM users)"
+)
+
+// RepairOrphans conta (e, se deleteRows for true, remove) as interações e
+// recomendações órfãs e as denúncias duplicadas. Deve rodar antes do
+// AutoMigrate, pois as chaves estrangeiras e os índices únicos não podem ser
+// criados enquanto houver esses registros.
+func RepairOrphans(db *gorm.DB, deleteRows bool) (OrphanReport, error) {
+	migrator := db.Migrator()
+	if !migrator.HasTable("users") || !migrator.HasTable("contents") {
+		log.Println("[repair] tabelas ainda não existem, nada a verificar")
+		return OrphanReport{}, nil
+	}
+
+	report, err := countOrphans(db)
+	if err != nil {
+		return report, err
+	}
+
+	log.Printf("[repair] interações órfãs: %d, recomendações órfãs: %d, denúncias duplicadas: %d\n",
+		report.Interactions, report.Recommendations, report.DuplicateReports)
+
+	if !deleteRows || (report.Interactions == 0 && report.Recommendations == 0 && report.DuplicateReports == 0) {
+		return report, nil
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if report.Interactions > 0 {
+			if err := tx.Exec("DELETE FROM user_interactions WHERE " + orphanInteractionsFilter).Error; err != nil {
+				return err
+			}
+		}
+		if report.Recommendations > 0 {
+			if err := tx.Exec("DELETE FROM recommendations WHERE " + orphanRecommendationsFilter).Error; err != nil {
+				return err
+			}
+		}
+		if report.DuplicateReports > 0 {
+			if err := tx.Exec("DELETE FROM content_reports WHERE " + duplicateReportsFilter).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return report, err
+	}
+
+	report.Deleted = true
+	log.Println("[repair] registros órfãos removidos")
+	return report, nil
+}
+
+// countOrphans conta, sem alterar nada, os registros que RepairOrphans removeria
+func countOrphans(db *gorm.DB) (OrphanReport, error) {
+	var report OrphanReport
+
+	migrator := db.Migrator()
+	if !migrator.HasTable("users") || !migrator.HasTable("contents") {
+		return report, nil
+	}
+
+	if migrator.HasTable("user_interactions") {
+		if err := db.Table("user_interactions").
+			Where(orphanInteractionsFilter).
+			Count(&report.Interactions).Error; err != nil {
+			return report, err
+		}
+	}
+	if migrator.HasTable("recommendations") {
+		if err := db.Table("recommendations").
+			Where(orphanRecommendationsFilter).
+			Count(&report.Recommendations).Error; err != nil {
+			return report, err
+		}
+	}
+
+	duplicates, err := countDuplicateReports(db)
+	if err != nil {
+		return report, err
+	}
+	report.DuplicateReports = duplicates
+	return report, nil
+}
@@ -46,6 +46,7 @@ func seedUser(db *gorm.DB) error {
 		Name:     "Administrador",
 		Email:    email,
 		Password: string(hashedPassword),
+		Role:     models.RoleAdmin,
 	}
 	if err := db.Create(&user).Error; err != nil {
 		return err
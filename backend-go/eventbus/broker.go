@@ -0,0 +1,667 @@
+package eventbus
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	streamFileName = "stream.ndjson"
+	stateFileName  = "state.json"
+	// Mensagens entregues por vez às assinaturas internas
+	brokerSubscriberBatch = 100
+	// Limite de mensagens por Fetch
+	maxFetchBatch = 1000
+	// Intervalo da limpeza das mensagens fora da retenção
+	brokerJanitorInterval = time.Minute
+	// Espera entre novas tentativas de um handler com erro
+	brokerRetryBase = time.Second
+	brokerRetryMax  = time.Minute
+)
+
+// BrokerOptions define onde o broker embutido guarda as mensagens e por quanto tempo
+type BrokerOptions struct {
+	Dir string
+	// Mensagens mantidas no stream; as mais antigas são descartadas
+	MaxMessages int
+	// Mensagens mais antigas que isso são descartadas (0 desativa)
+	MaxAge time.Duration
+}
+
+// brokerState é gravado em state.json. LastSeq é mantido mesmo quando o
+// stream fica vazio, para que a sequência nunca seja reutilizada.
+type brokerState struct {
+	LastSeq   uint64            `json:"last_seq"`
+	Consumers map[string]uint64 `json:"consumers"`
+}
+
+type embeddedBroker struct {
+	opts     BrokerOptions
+	mu       sync.Mutex
+	file     *os.File
+	messages []Message
+	state    brokerState
+	// Fechado e recriado a cada publicação para acordar quem espera mensagens
+	notify chan struct{}
+	// Serializa as compactações, que escrevem o arquivo novo fora de mu
+	compactMu sync.Mutex
+	// Pede ao janitor uma compactação antes do próximo intervalo
+	compactNow chan struct{}
+	// O stream pode ter uma linha incompleta no fim e precisa ser reescrito
+	// antes de aceitar novas mensagens
+	rewrite bool
+	closed  bool
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+}
+
+type brokerSubscription struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewEmbeddedBroker abre (ou cria) o stream em opts.Dir. As mensagens são
+// gravadas em NDJSON com número de sequência crescente, e a posição de cada
+// consumidor durável é guardada em state.json; a entrega é "pelo menos uma
+// vez". O broker implementa Stream, para consumo externo por pull.
+func NewEmbeddedBroker(opts BrokerOptions) (Bus, error) {
+	if opts.Dir == "" {
+		opts.Dir = "data/eventbus"
+	}
+	if opts.MaxMessages < 1 {
+		opts.MaxMessages = 100000
+	}
+	if err := os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("erro ao criar diretório do broker: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &embeddedBroker{
+		opts:       opts,
+		state:      brokerState{Consumers: make(map[string]uint64)},
+		notify:     make(chan struct{}),
+		compactNow: make(chan struct{}, 1),
+		ctx:        ctx,
+		cancel:     cancel,
+	}
+	discarded, err := b.load()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	// Com linhas descartadas, reescreve para que a próxima mensagem não seja
+	// anexada a uma linha incompleta
+	err = b.compact(time.Now(), discarded > 0)
+	b.mu.Lock()
+	if err == nil && b.file == nil {
+		err = b.openLocked()
+	}
+	b.mu.Unlock()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	b.wg.Add(1)
+	go b.janitor()
+	return b, nil
+}
+
+// load lê o estado e as mensagens gravadas. Uma linha corrompida (ex.: a
+// última, se o processo caiu durante a escrita) é descartada; retorna
+// quantas linhas foram descartadas.
+func (b *embeddedBroker) load() (int, error) {
+	raw, err := os.ReadFile(filepath.Join(b.opts.Dir, stateFileName))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return 0, fmt.Errorf("erro ao ler estado do broker: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(raw, &b.state); err != nil {
+			return 0, fmt.Errorf("erro ao ler estado do broker: %w", err)
+		}
+		if b.state.Consumers == nil {
+			b.state.Consumers = make(map[string]uint64)
+		}
+	}
+
+	f, err := os.Open(filepath.Join(b.opts.Dir, streamFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("erro ao abrir stream: %w", err)
+	}
+	defer f.Close()
+
+	discarded := 0
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var msg Message
+		err := json.Unmarshal(scanner.Bytes(), &msg)
+		if err != nil || len(b.messages) > 0 && msg.Seq <= b.messages[len(b.messages)-1].Seq {
+			log.Printf("[eventbus] linha inválida descartada em %s", streamFileName)
+			discarded++
+			continue
+		}
+		b.messages = append(b.messages, msg)
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, fmt.Errorf("erro ao ler stream: %w", err)
+	}
+	if n := len(b.messages); n > 0 && b.messages[n-1].Seq > b.state.LastSeq {
+		b.state.LastSeq = b.messages[n-1].Seq
+	}
+	return discarded, nil
+}
+
+// openLocked abre o arquivo do stream para acréscimo
+func (b *embeddedBroker) openLocked() error {
+	f, err := os.OpenFile(filepath.Join(b.opts.Dir, streamFileName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("erro ao abrir stream: %w", err)
+	}
+	b.file = f
+	return nil
+}
+
+// Publish grava a mensagem no stream e sincroniza o arquivo com o disco antes
+// de torná-la visível: a sequência só avança e os consumidores só são
+// acordados depois do fsync, então nenhuma mensagem confirmada ou entregue
+// se perde numa queda. Se a escrita ou o fsync falham, o arquivo volta ao
+// tamanho anterior e a mensagem não é publicada. A retenção é aplicada pelo
+// janitor, fora de Publish.
+func (b *embeddedBroker) Publish(subject string, data []byte) error {
+	if err := ValidateSubject(subject); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrClosed
+	}
+	if b.rewrite {
+		b.requestCompactLocked()
+		return errors.New("stream aguardando reescrita após falha de gravação")
+	}
+
+	msg := Message{Seq: b.state.LastSeq + 1, Subject: subject, Data: data, PublishedAt: time.Now()}
+	line, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar mensagem: %w", err)
+	}
+	if err := b.appendLocked(append(line, '\n')); err != nil {
+		return err
+	}
+
+	b.state.LastSeq = msg.Seq
+	b.messages = append(b.messages, msg)
+	close(b.notify)
+	b.notify = make(chan struct{})
+
+	// Compacta só quando passa 10% do limite, não a cada mensagem
+	if len(b.messages) > b.opts.MaxMessages+b.opts.MaxMessages/10 {
+		b.requestCompactLocked()
+	}
+	return nil
+}
+
+// appendLocked acrescenta a linha ao stream e sincroniza o arquivo. Em caso
+// de falha, trunca o arquivo de volta ao tamanho anterior; se o arquivo não
+// pode ser posicionado ou truncado, o stream é reescrito a partir da memória antes da próxima
+// publicação, para que a próxima linha não seja anexada a uma incompleta.
+func (b *embeddedBroker) appendLocked(line []byte) error {
+	offset, err := b.file.Seek(0, io.SeekEnd)
+	if err != nil {
+		b.rewrite = true
+		b.requestCompactLocked()
+		return fmt.Errorf("erro ao gravar mensagem: %w", err)
+	}
+	_, err = b.file.Write(line)
+	if err == nil {
+		if err = b.file.Sync(); err != nil {
+			err = fmt.Errorf("erro ao sincronizar stream: %w", err)
+		}
+	} else {
+		err = fmt.Errorf("erro ao gravar mensagem: %w", err)
+	}
+	if err == nil {
+		return nil
+	}
+
+	if truncErr := b.file.Truncate(offset); truncErr != nil {
+		log.Printf("[eventbus] erro ao desfazer gravação no stream, reescrevendo: %v", truncErr)
+		b.rewrite = true
+		b.requestCompactLocked()
+	}
+	return err
+}
+
+// requestCompactLocked pede ao janitor uma compactação antes do próximo intervalo
+func (b *embeddedBroker) requestCompactLocked() {
+	select {
+	case b.compactNow <- struct{}{}:
+	default:
+	}
+}
+
+// janitor aplica periodicamente a retenção, e antes do intervalo quando o
+// stream passa do limite de mensagens
+func (b *embeddedBroker) janitor() {
+	defer b.wg.Done()
+	ticker := time.NewTicker(brokerJanitorInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.ctx.Done():
+			return
+		case <-ticker.C:
+		case <-b.compactNow:
+		}
+		if err := b.compact(time.Now(), false); err != nil {
+			log.Printf("[eventbus] erro ao aplicar retenção: %v", err)
+		}
+	}
+}
+
+// expiredLocked conta as mensagens do início do stream fora da retenção
+func (b *embeddedBroker) expiredLocked(now time.Time) int {
+	drop := 0
+	if len(b.messages) > b.opts.MaxMessages {
+		drop = len(b.messages) - b.opts.MaxMessages
+	}
+	if b.opts.MaxAge > 0 {
+		for drop < len(b.messages) && now.Sub(b.messages[drop].PublishedAt) > b.opts.MaxAge {
+			drop++
+		}
+	}
+	return drop
+}
+
+// compact descarta as mensagens fora da retenção trocando o stream por um
+// arquivo novo; com force, reescreve o arquivo mesmo sem nada a descartar.
+// As mensagens mantidas são gravadas sem segurar b.mu, para não bloquear
+// Publish e Fetch; sob o lock ficam apenas as mensagens publicadas durante
+// a escrita e a troca do arquivo. A troca é feita por rename, para que uma
+// queda no meio não corrompa o stream.
+func (b *embeddedBroker) compact(now time.Time, force bool) error {
+	b.compactMu.Lock()
+	defer b.compactMu.Unlock()
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	drop := b.expiredLocked(now)
+	if drop == 0 && !force && !b.rewrite {
+		b.mu.Unlock()
+		return nil
+	}
+	// Publish só acrescenta ao fim e só compact remove do início, então as
+	// mensagens já gravadas em kept não mudam enquanto o lock está livre
+	kept := b.messages[drop:]
+	b.mu.Unlock()
+
+	tmp, err := os.CreateTemp(b.opts.Dir, streamFileName+".*")
+	if err != nil {
+		return fmt.Errorf("erro ao reescrever stream: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	w := bufio.NewWriter(tmp)
+	if err := encodeMessages(w, kept); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		tmp.Close()
+		return nil
+	}
+	if err := encodeMessages(w, b.messages[drop+len(kept):]); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("erro ao reescrever stream: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("erro ao reescrever stream: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("erro ao reescrever stream: %w", err)
+	}
+
+	if b.file != nil {
+		b.file.Close()
+		b.file = nil
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(b.opts.Dir, streamFileName)); err != nil {
+		return fmt.Errorf("erro ao reescrever stream: %w", err)
+	}
+	if err := syncDir(b.opts.Dir); err != nil {
+		return err
+	}
+	b.messages = append([]Message(nil), b.messages[drop:]...)
+	if err := b.openLocked(); err != nil {
+		return err
+	}
+	b.rewrite = false
+	return b.saveStateLocked()
+}
+
+// encodeMessages grava as mensagens em NDJSON
+func encodeMessages(w io.Writer, msgs []Message) error {
+	encoder := json.NewEncoder(w)
+	for i := range msgs {
+		if err := encoder.Encode(&msgs[i]); err != nil {
+			return fmt.Errorf("erro ao reescrever stream: %w", err)
+		}
+	}
+	return nil
+}
+
+// syncDir sincroniza o diretório, para que um rename sobreviva a uma queda
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("erro ao sincronizar diretório do broker: %w", err)
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("erro ao sincronizar diretório do broker: %w", err)
+	}
+	return nil
+}
+
+// saveStateLocked grava a sequência e a posição dos consumidores
+func (b *embeddedBroker) saveStateLocked() error {
+	raw, err := json.Marshal(b.state)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar estado do broker: %w", err)
+	}
+	path := filepath.Join(b.opts.Dir, stateFileName)
+	f, err := os.OpenFile(path+".tmp", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("erro ao gravar estado do broker: %w", err)
+	}
+	if _, err := f.Write(raw); err != nil {
+		f.Close()
+		return fmt.Errorf("erro ao gravar estado do broker: %w", err)
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return fmt.Errorf("erro ao gravar estado do broker: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("erro ao gravar estado do broker: %w", err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return fmt.Errorf("erro ao gravar estado do broker: %w", err)
+	}
+	return nil
+}
+
+// collectLocked retorna até batch mensagens posteriores a after que casam
+// com o padrão, e até onde a posição pode avançar sem pular nenhuma delas
+func (b *embeddedBroker) collectLocked(after uint64, pattern string, batch int) ([]Message, uint64) {
+	i := sort.Search(len(b.messages), func(i int) bool { return b.messages[i].Seq > after })
+	skipTo := after
+	var msgs []Message
+	for ; i < len(b.messages) && len(msgs) < batch; i++ {
+		if MatchSubject(pattern, b.messages[i].Subject) {
+			msgs = append(msgs, b.messages[i])
+		} else if len(msgs) == 0 {
+			skipTo = b.messages[i].Seq
+		}
+	}
+	return msgs, skipTo
+}
+
+// wait espera mensagens posteriores à posição retornada por position. As
+// que não casam com o padrão são puladas via skip. Ambos rodam sob o lock.
+func (b *embeddedBroker) wait(ctx context.Context, pattern string, batch int, position func() uint64, skip func(uint64)) ([]Message, error) {
+	for {
+		b.mu.Lock()
+		if b.closed {
+			b.mu.Unlock()
+			return nil, ErrClosed
+		}
+		after := position()
+		msgs, skipTo := b.collectLocked(after, pattern, batch)
+		if skipTo > after {
+			skip(skipTo)
+		}
+		notify := b.notify
+		b.mu.Unlock()
+
+		if len(msgs) > 0 {
+			return msgs, nil
+		}
+		select {
+		case <-ctx.Done():
+			return []Message{}, nil
+		case <-notify:
+		}
+	}
+}
+
+// Fetch retorna as próximas mensagens do consumidor durável. Um consumidor
+// novo começa nas mensagens publicadas a partir da sua criação. Mensagens não confirmadas são entregues de
+// novo no próximo Fetch.
+func (b *embeddedBroker) Fetch(ctx context.Context, durable, pattern string, batch int) ([]Message, error) {
+	if err := ValidateDurable(durable); err != nil {
+		return nil, err
+	}
+	if err := ValidatePattern(pattern); err != nil {
+		return nil, err
+	}
+	if batch < 1 {
+		batch = 1
+	}
+	if batch > maxFetchBatch {
+		batch = maxFetchBatch
+	}
+
+	b.mu.Lock()
+	if !b.closed {
+		b.registerConsumerLocked(durable)
+	}
+	b.mu.Unlock()
+
+	return b.wait(ctx, pattern, batch,
+		func() uint64 { return b.state.Consumers[durable] },
+		func(seq uint64) {
+			b.state.Consumers[durable] = seq
+			if err := b.saveStateLocked(); err != nil {
+				log.Printf("[eventbus] %v", err)
+			}
+		},
+	)
+}
+
+// registerConsumerLocked cria o consumidor durável, se ainda não existe, na
+// posição atual do stream, para que um nome novo não reprocesse o histórico
+func (b *embeddedBroker) registerConsumerLocked(durable string) {
+	if _, ok := b.state.Consumers[durable]; ok {
+		return
+	}
+	b.state.Consumers[durable] = b.state.LastSeq
+	if err := b.saveStateLocked(); err != nil {
+		log.Printf("[eventbus] %v", err)
+	}
+}
+
+// Ack confirma as mensagens do consumidor até seq
+func (b *embeddedBroker) Ack(durable string, seq uint64) error {
+	if err := ValidateDurable(durable); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrClosed
+	}
+	if seq > b.state.LastSeq {
+		return fmt.Errorf("seq %d ainda não foi publicada", seq)
+	}
+	if seq <= b.state.Consumers[durable] {
+		return nil
+	}
+	b.state.Consumers[durable] = seq
+	return b.saveStateLocked()
+}
+
+// Info retorna a faixa de sequências guardadas e a posição dos consumidores
+func (b *embeddedBroker) Info() StreamInfo {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	info := StreamInfo{
+		FirstSeq:  b.state.LastSeq + 1,
+		LastSeq:   b.state.LastSeq,
+		Messages:  len(b.messages),
+		Consumers: make(map[string]uint64, len(b.state.Consumers)),
+	}
+	if len(b.messages) > 0 {
+		info.FirstSeq = b.messages[0].Seq
+	}
+	for durable, seq := range b.state.Consumers {
+		info.Consumers[durable] = seq
+	}
+	return info
+}
+
+// Subscribe entrega as mensagens ao handler numa goroutine própria. Com
+// durable, a posição é confirmada após cada lote processado e sobrevive a
+// restarts; um erro do handler faz a mensagem ser entregue de novo após uma
+// espera crescente.
+func (b *embeddedBroker) Subscribe(pattern, durable string, handler Handler) (Subscription, error) {
+	if err := ValidatePattern(pattern); err != nil {
+		return nil, err
+	}
+	if durable != "" {
+		if err := ValidateDurable(durable); err != nil {
+			return nil, err
+		}
+	}
+
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil, ErrClosed
+	}
+	after := b.state.LastSeq
+	if durable != "" {
+		b.registerConsumerLocked(durable)
+		after = b.state.Consumers[durable]
+	}
+	b.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(b.ctx)
+	sub := &brokerSubscription{cancel: cancel, done: make(chan struct{})}
+	b.wg.Add(1)
+	go func() {
+		defer b.wg.Done()
+		defer close(sub.done)
+		b.consume(ctx, pattern, durable, after, handler)
+	}()
+	return sub, nil
+}
+
+func (b *embeddedBroker) consume(ctx context.Context, pattern, durable string, after uint64, handler Handler) {
+	retry := brokerRetryBase
+	for {
+		msgs, err := b.wait(ctx, pattern, brokerSubscriberBatch,
+			func() uint64 { return after },
+			func(seq uint64) { after = seq },
+		)
+		if err != nil || ctx.Err() != nil {
+			return
+		}
+
+		failed := false
+		for i := range msgs {
+			if err := handler(&msgs[i]); err != nil {
+				log.Printf("[eventbus] erro ao processar %s (seq %d), nova tentativa em %s: %v",
+					msgs[i].Subject, msgs[i].Seq, retry, err)
+				failed = true
+				break
+			}
+			after = msgs[i].Seq
+		}
+		if durable != "" {
+			if err := b.Ack(durable, after); err != nil && !errors.Is(err, ErrClosed) {
+				log.Printf("[eventbus] erro ao confirmar %s: %v", durable, err)
+			}
+		}
+
+		if !failed {
+			retry = brokerRetryBase
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(retry):
+		}
+		if retry *= 2; retry > brokerRetryMax {
+			retry = brokerRetryMax
+		}
+	}
+}
+
+// Unsubscribe encerra a assinatura e espera o handler em andamento terminar
+func (s *brokerSubscription) Unsubscribe() {
+	s.cancel()
+	<-s.done
+}
+
+// Close encerra as assinaturas internas e grava o estado
+func (b *embeddedBroker) Close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	close(b.notify)
+	b.mu.Unlock()
+
+	b.cancel()
+	b.wg.Wait()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var err error
+	if b.file != nil {
+		if syncErr := b.file.Sync(); syncErr != nil {
+			err = syncErr
+		}
+		if closeErr := b.file.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	if saveErr := b.saveStateLocked(); saveErr != nil && err == nil {
+		err = saveErr
+	}
+	return err
+}
@@ -0,0 +1,188 @@
+package eventbus
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// crashCopy copia os arquivos do broker para um diretório novo, como o disco
+// ficaria se o processo caísse agora, sem Close
+func crashCopy(t *testing.T, dir, tail string) string {
+	t.Helper()
+	next := t.TempDir()
+	for _, name := range []string{streamFileName, stateFileName} {
+		raw, err := os.ReadFile(filepath.Join(dir, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name == streamFileName {
+			raw = append(raw, tail...)
+		}
+		if err := os.WriteFile(filepath.Join(next, name), raw, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return next
+}
+
+func TestEmbeddedBroker(t *testing.T) {
+	type step struct {
+		// publish: publica n mensagens; fetch: busca do consumidor "c1" e
+		// confere as sequências; ack: confirma até seq; crash: reabre a partir
+		// de uma cópia do disco (com tail no fim do stream); compact: aplica a
+		// retenção em now+after; break: fecha o arquivo do stream por baixo
+		op      string
+		n       int
+		seq     uint64
+		tail    string
+		after   time.Duration
+		want    []uint64
+		wantErr bool
+	}
+
+	tests := []struct {
+		name         string
+		opts         BrokerOptions
+		steps        []step
+		wantFirst    uint64
+		wantLast     uint64
+		wantMessages int
+	}{
+		{
+			name:         "reinício preserva mensagens e sequência",
+			steps:        []step{{op: "publish", n: 3}, {op: "crash"}, {op: "publish", n: 1}},
+			wantFirst:    1,
+			wantLast:     4,
+			wantMessages: 4,
+		},
+		{
+			name: "linha incompleta do fim é descartada",
+			steps: []step{
+				{op: "publish", n: 2}, {op: "crash", tail: `{"seq":3,"sub`},
+				{op: "publish", n: 1}, {op: "crash"},
+			},
+			wantFirst:    1,
+			wantLast:     3,
+			wantMessages: 3,
+		},
+		{
+			name: "confirmação sobrevive ao reinício",
+			steps: []step{
+				{op: "fetch"}, {op: "publish", n: 3},
+				{op: "fetch", want: []uint64{1, 2, 3}}, {op: "ack", seq: 2},
+				{op: "crash"}, {op: "fetch", want: []uint64{3}},
+			},
+			wantFirst:    1,
+			wantLast:     3,
+			wantMessages: 3,
+		},
+		{
+			name: "confirmação além da última mensagem é recusada",
+			steps: []step{
+				{op: "fetch"}, {op: "publish", n: 1}, {op: "ack", seq: 2, wantErr: true},
+			},
+			wantFirst:    1,
+			wantLast:     1,
+			wantMessages: 1,
+		},
+		{
+			name: "compactação mantém as mais novas e a sequência",
+			opts: BrokerOptions{MaxMessages: 10},
+			steps: []step{
+				{op: "publish", n: 15}, {op: "compact"}, {op: "crash"}, {op: "publish", n: 1},
+			},
+			wantFirst:    6,
+			wantLast:     16,
+			wantMessages: 11,
+		},
+		{
+			name: "stream esvaziado pela idade não reutiliza a sequência",
+			opts: BrokerOptions{MaxAge: time.Hour},
+			steps: []step{
+				{op: "publish", n: 2}, {op: "compact", after: 2 * time.Hour},
+				{op: "crash"}, {op: "publish", n: 1},
+			},
+			wantFirst:    3,
+			wantLast:     3,
+			wantMessages: 1,
+		},
+		{
+			name: "falha de gravação não publica nem avança a sequência",
+			steps: []step{
+				{op: "fetch"}, {op: "publish", n: 1}, {op: "break"},
+				{op: "publish", n: 1, wantErr: true},
+				{op: "fetch", want: []uint64{1}},
+				{op: "compact"}, {op: "publish", n: 1}, {op: "crash"},
+			},
+			wantFirst:    1,
+			wantLast:     2,
+			wantMessages: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			open := func(dir string) *embeddedBroker {
+				opts := tt.opts
+				opts.Dir = dir
+				bus, err := NewEmbeddedBroker(opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				t.Cleanup(func() { bus.Close() })
+				return bus.(*embeddedBroker)
+			}
+			b := open(dir)
+
+			for i, st := range tt.steps {
+				var err error
+				switch st.op {
+				case "publish":
+					for j := 0; j < st.n && err == nil; j++ {
+						err = b.Publish("content.created", []byte(fmt.Sprintf(`{"n":%d}`, j)))
+					}
+				case "fetch":
+					ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+					var msgs []Message
+					msgs, err = b.Fetch(ctx, "c1", ">", 10)
+					cancel()
+					got := make([]uint64, len(msgs))
+					for j, msg := range msgs {
+						got[j] = msg.Seq
+					}
+					if fmt.Sprint(got) != fmt.Sprint(append([]uint64{}, st.want...)) {
+						t.Fatalf("passo %d: fetch = %v, esperado %v", i, got, st.want)
+					}
+				case "ack":
+					err = b.Ack("c1", st.seq)
+				case "crash":
+					dir = crashCopy(t, dir, st.tail)
+					b = open(dir)
+				case "compact":
+					err = b.compact(time.Now().Add(st.after), false)
+				case "break":
+					b.mu.Lock()
+					b.file.Close()
+					b.mu.Unlock()
+				}
+				if (err != nil) != st.wantErr {
+					t.Fatalf("passo %d (%s): erro %v, esperado erro: %v", i, st.op, err, st.wantErr)
+				}
+			}
+
+			info := b.Info()
+			if info.FirstSeq != tt.wantFirst || info.LastSeq != tt.wantLast || info.Messages != tt.wantMessages {
+				t.Errorf("stream = primeira %d, última %d, %d mensagens; esperado %d, %d, %d",
+					info.FirstSeq, info.LastSeq, info.Messages, tt.wantFirst, tt.wantLast, tt.wantMessages)
+			}
+		})
+	}
+}
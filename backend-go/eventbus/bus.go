@@ -0,0 +1,135 @@
+// Package eventbus define o barramento de eventos de domínio do backend.
+//
+// Há duas implementações: o barramento em processo, que apenas repassa as
+// mensagens aos assinantes locais, e o broker embutido, que grava as mensagens
+// em disco com número de sequência e mantém a posição de consumidores
+// duráveis, no estilo do JetStream. Só o broker embutido expõe as mensagens a
+// consumidores externos (Stream), como o motor Python.
+//
+// Subjects são tokens separados por ponto (ex.: content.published.v1). Nos
+// padrões de assinatura, "*" casa com um token e ">" com um ou mais tokens
+// finais.
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Message é uma mensagem publicada no barramento
+type Message struct {
+	Seq         uint64          `json:"seq"`
+	Subject     string          `json:"subject"`
+	Data        json.RawMessage `json:"data" swaggertype:"object"`
+	PublishedAt time.Time       `json:"published_at"`
+}
+
+// Handler processa uma mensagem entregue a uma assinatura. No broker
+// embutido, um erro faz a mensagem ser entregue de novo.
+type Handler func(msg *Message) error
+
+// Subscription é uma assinatura ativa
+type Subscription interface {
+	Unsubscribe()
+}
+
+// Bus publica mensagens e as entrega aos assinantes cujo padrão casa com o subject
+type Bus interface {
+	Publish(subject string, data []byte) error
+	// Subscribe registra um handler para o padrão informado. durable
+	// identifica um consumidor que retoma de onde parou; vazio recebe apenas
+	// as mensagens publicadas a partir da assinatura. O barramento em
+	// processo ignora durable.
+	Subscribe(pattern, durable string, handler Handler) (Subscription, error)
+	Close() error
+}
+
+// Stream é implementado pelos barramentos que guardam as mensagens e
+// permitem o consumo por pull com confirmação (ack) cumulativa
+type Stream interface {
+	// Fetch retorna até batch mensagens posteriores à última confirmada pelo
+	// consumidor. Sem mensagens, espera novas até ctx terminar.
+	Fetch(ctx context.Context, durable, pattern string, batch int) ([]Message, error)
+	// Ack confirma todas as mensagens do consumidor até seq
+	Ack(durable string, seq uint64) error
+	Info() StreamInfo
+}
+
+// StreamInfo resume o conteúdo do stream e a posição dos consumidores
+type StreamInfo struct {
+	FirstSeq  uint64            `json:"first_seq"`
+	LastSeq   uint64            `json:"last_seq"`
+	Messages  int               `json:"messages"`
+	Consumers map[string]uint64 `json:"consumers"`
+}
+
+var (
+	// ErrClosed é retornado após o barramento ser fechado
+	ErrClosed = errors.New("barramento de eventos fechado")
+	// ErrNotDurable é retornado quando o barramento não guarda as mensagens
+	ErrNotDurable = errors.New("barramento em processo não expõe consumidores externos; use EVENT_BUS=embedded")
+)
+
+// ValidateSubject valida um subject de publicação
+func ValidateSubject(subject string) error {
+	if subject == "" {
+		return errors.New("subject vazio")
+	}
+	for _, token := range strings.Split(subject, ".") {
+		if token == "" || token == "*" || token == ">" || strings.ContainsAny(token, " \t\r\n") {
+			return errors.New("subject inválido: " + subject)
+		}
+	}
+	return nil
+}
+
+// ValidatePattern valida um padrão de assinatura
+func ValidatePattern(pattern string) error {
+	if pattern == "" {
+		return errors.New("padrão de subject vazio")
+	}
+	tokens := strings.Split(pattern, ".")
+	for i, token := range tokens {
+		if token == "" || strings.ContainsAny(token, " \t\r\n") {
+			return errors.New("padrão de subject inválido: " + pattern)
+		}
+		if token == ">" && i != len(tokens)-1 {
+			return errors.New("padrão de subject inválido: \">\" deve ser o último token")
+		}
+	}
+	return nil
+}
+
+// ValidateDurable valida o nome de um consumidor durável
+func ValidateDurable(durable string) error {
+	if durable == "" || len(durable) > 64 {
+		return errors.New("nome do consumidor deve ter entre 1 e 64 caracteres")
+	}
+	for _, r := range durable {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return errors.New("nome do consumidor aceita apenas letras, números, - e _")
+		}
+	}
+	return nil
+}
+
+// MatchSubject indica se o subject casa com o padrão
+func MatchSubject(pattern, subject string) bool {
+	patternTokens := strings.Split(pattern, ".")
+	subjectTokens := strings.Split(subject, ".")
+	for i, token := range patternTokens {
+		if token == ">" {
+			return len(subjectTokens) > i
+		}
+		if i >= len(subjectTokens) {
+			return false
+		}
+		if token != "*" && token != subjectTokens[i] {
+			return false
+		}
+	}
+	return len(patternTokens) == len(subjectTokens)
+}
@@ -0,0 +1,109 @@
+package eventbus
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// Mensagens pendentes por assinatura antes de novas serem descartadas
+const inProcessQueueSize = 256
+
+type inProcessBus struct {
+	mu     sync.RWMutex
+	seq    uint64
+	subs   map[*inProcessSubscription]struct{}
+	closed bool
+}
+
+type inProcessSubscription struct {
+	bus     *inProcessBus
+	pattern string
+	queue   chan Message
+}
+
+// NewInProcessBus cria um barramento que entrega as mensagens apenas aos
+// assinantes do próprio processo, sem persistência. Cada assinatura tem sua
+// própria fila e goroutine, então um handler lento não atrasa a publicação;
+// com a fila cheia, a mensagem é descartada para aquela assinatura.
+func NewInProcessBus() Bus {
+	return &inProcessBus{subs: make(map[*inProcessSubscription]struct{})}
+}
+
+// Publish entrega a mensagem às assinaturas cujo padrão casa com o subject
+func (b *inProcessBus) Publish(subject string, data []byte) error {
+	if err := ValidateSubject(subject); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return ErrClosed
+	}
+
+	b.seq++
+	msg := Message{Seq: b.seq, Subject: subject, Data: data, PublishedAt: time.Now()}
+	for sub := range b.subs {
+		if !MatchSubject(sub.pattern, subject) {
+			continue
+		}
+		select {
+		case sub.queue <- msg:
+		default:
+			log.Printf("[eventbus] fila da assinatura %s cheia, mensagem %d descartada", sub.pattern, msg.Seq)
+		}
+	}
+	return nil
+}
+
+// Subscribe registra o handler; durable é ignorado por não haver persistência
+func (b *inProcessBus) Subscribe(pattern, durable string, handler Handler) (Subscription, error) {
+	if err := ValidatePattern(pattern); err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil, ErrClosed
+	}
+
+	sub := &inProcessSubscription{bus: b, pattern: pattern, queue: make(chan Message, inProcessQueueSize)}
+	b.subs[sub] = struct{}{}
+	go func() {
+		for msg := range sub.queue {
+			msg := msg
+			if err := handler(&msg); err != nil {
+				log.Printf("[eventbus] erro ao processar %s (seq %d): %v", msg.Subject, msg.Seq, err)
+			}
+		}
+	}()
+	return sub, nil
+}
+
+// Close encerra todas as assinaturas
+func (b *inProcessBus) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return nil
+	}
+	b.closed = true
+	for sub := range b.subs {
+		delete(b.subs, sub)
+		close(sub.queue)
+	}
+	return nil
+}
+
+// Unsubscribe remove a assinatura; as mensagens já enfileiradas ainda são processadas
+func (s *inProcessSubscription) Unsubscribe() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+	if _, ok := s.bus.subs[s]; !ok {
+		return
+	}
+	delete(s.bus.subs, s)
+	close(s.queue)
+}
@@ -0,0 +1,134 @@
+package eventbus
+
+import (
+	"crypto/rand"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Origem gravada nos envelopes publicados pelo backend
+const EnvelopeSource = "backend-go"
+
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+// Schema é o JSON Schema de uma versão de um tipo de evento. O subject
+// publicado é "<tipo>.v<versão>", ex.: content.published.v1.
+type Schema struct {
+	Subject string          `json:"subject"`
+	Type    string          `json:"type"`
+	Version int             `json:"version"`
+	Schema  json.RawMessage `json:"schema" swaggertype:"object"`
+}
+
+// Envelope é o formato de todas as mensagens publicadas; Data segue o schema do subject
+type Envelope struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Version    int             `json:"version"`
+	Source     string          `json:"source"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data" swaggertype:"object"`
+}
+
+var (
+	schemas         = map[string]Schema{}
+	compiledSchemas = map[string]*jsonSchema{}
+	// Versão mais recente de cada tipo, usada na publicação
+	latestVersions = map[string]int{}
+)
+
+func init() {
+	entries, err := schemaFiles.ReadDir("schemas")
+	if err != nil {
+		panic(err)
+	}
+	for _, entry := range entries {
+		subject := strings.TrimSuffix(entry.Name(), ".json")
+		dot := strings.LastIndex(subject, ".v")
+		if dot < 0 {
+			panic("schema de evento sem versão: " + entry.Name())
+		}
+		version, err := strconv.Atoi(subject[dot+2:])
+		if err != nil {
+			panic("schema de evento sem versão: " + entry.Name())
+		}
+		raw, err := schemaFiles.ReadFile(path.Join("schemas", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+		compiled, err := parseSchema(raw)
+		if err != nil {
+			panic("schema de evento inválido: " + entry.Name() + ": " + err.Error())
+		}
+
+		eventType := subject[:dot]
+		schemas[subject] = Schema{Subject: subject, Type: eventType, Version: version, Schema: raw}
+		compiledSchemas[subject] = compiled
+		if version > latestVersions[eventType] {
+			latestVersions[eventType] = version
+		}
+	}
+}
+
+// Schemas lista os schemas registrados, ordenados por subject
+func Schemas() []Schema {
+	list := make([]Schema, 0, len(schemas))
+	for _, schema := range schemas {
+		list = append(list, schema)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Subject < list[j].Subject })
+	return list
+}
+
+// GetSchema busca o schema de um subject
+func GetSchema(subject string) (*Schema, error) {
+	schema, ok := schemas[subject]
+	if !ok {
+		return nil, errors.New("schema não encontrado")
+	}
+	return &schema, nil
+}
+
+// NewEnvelope monta a mensagem de um evento na versão mais recente do seu
+// schema, confere a mensagem contra o schema e retorna o subject em que ela
+// deve ser publicada
+func NewEnvelope(eventType string, data interface{}) (string, []byte, error) {
+	version, ok := latestVersions[eventType]
+	if !ok {
+		return "", nil, errors.New("tipo de evento sem schema: " + eventType)
+	}
+
+	rawData, err := json.Marshal(data)
+	if err != nil {
+		return "", nil, fmt.Errorf("erro ao serializar evento: %w", err)
+	}
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", nil, fmt.Errorf("erro ao gerar ID do evento: %w", err)
+	}
+	payload, err := json.Marshal(Envelope{
+		ID:         hex.EncodeToString(id),
+		Type:       eventType,
+		Version:    version,
+		Source:     EnvelopeSource,
+		OccurredAt: time.Now(),
+		Data:       rawData,
+	})
+	if err != nil {
+		return "", nil, fmt.Errorf("erro ao serializar evento: %w", err)
+	}
+	subject := fmt.Sprintf("%s.v%d", eventType, version)
+	if err := Validate(subject, payload); err != nil {
+		return "", nil, err
+	}
+	return subject, payload, nil
+}
@@ -0,0 +1,186 @@
+package eventbus
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// jsonSchema é o subconjunto de JSON Schema usado nos schemas dos eventos:
+// type, required, properties, items, const, minimum e format date-time.
+// Palavras-chave fora dele são recusadas ao carregar os schemas, para que um
+// schema novo não passe a ser aceito sem validação.
+type jsonSchema struct {
+	Type       string                 `json:"type"`
+	Format     string                 `json:"format"`
+	Const      json.RawMessage        `json:"const"`
+	Minimum    *float64               `json:"minimum"`
+	Required   []string               `json:"required"`
+	Properties map[string]*jsonSchema `json:"properties"`
+	Items      *jsonSchema            `json:"items"`
+}
+
+var supportedSchemaKeywords = map[string]bool{
+	"$schema": true, "$id": true, "title": true, "description": true,
+	"type": true, "format": true, "const": true, "minimum": true,
+	"required": true, "properties": true, "items": true,
+}
+
+// parseSchema lê um schema e confere se ele usa apenas as palavras-chave suportadas
+func parseSchema(raw []byte) (*jsonSchema, error) {
+	if err := checkSchemaKeywords(raw); err != nil {
+		return nil, err
+	}
+	var schema jsonSchema
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return nil, err
+	}
+	return &schema, nil
+}
+
+func checkSchemaKeywords(raw []byte) error {
+	var node map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &node); err != nil {
+		return err
+	}
+	for keyword, value := range node {
+		if !supportedSchemaKeywords[keyword] {
+			return fmt.Errorf("palavra-chave de schema não suportada: %s", keyword)
+		}
+		switch keyword {
+		case "items":
+			if err := checkSchemaKeywords(value); err != nil {
+				return err
+			}
+		case "properties":
+			var properties map[string]json.RawMessage
+			if err := json.Unmarshal(value, &properties); err != nil {
+				return err
+			}
+			for _, property := range properties {
+				if err := checkSchemaKeywords(property); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Validate confere a mensagem contra o schema do subject
+func Validate(subject string, payload []byte) error {
+	schema, ok := compiledSchemas[subject]
+	if !ok {
+		return errors.New("subject sem schema: " + subject)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("mensagem inválida para %s: %w", subject, err)
+	}
+	if err := schema.validate(value, "$"); err != nil {
+		return fmt.Errorf("mensagem inválida para %s: %w", subject, err)
+	}
+	return nil
+}
+
+func (s *jsonSchema) validate(value interface{}, path string) error {
+	if len(s.Const) > 0 {
+		var want interface{}
+		if err := json.Unmarshal(s.Const, &want); err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(normalizeNumber(value), want) {
+			return fmt.Errorf("%s deve ser %s", path, string(s.Const))
+		}
+	}
+
+	switch s.Type {
+	case "":
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s deve ser um objeto", path)
+		}
+		for _, field := range s.Required {
+			if _, ok := object[field]; !ok {
+				return fmt.Errorf("%s.%s é obrigatório", path, field)
+			}
+		}
+		fields := make([]string, 0, len(s.Properties))
+		for field := range s.Properties {
+			fields = append(fields, field)
+		}
+		// Ordem fixa, para que o erro retornado seja sempre o mesmo
+		sort.Strings(fields)
+		for _, field := range fields {
+			if fieldValue, ok := object[field]; ok {
+				if err := s.Properties[field].validate(fieldValue, path+"."+field); err != nil {
+					return err
+				}
+			}
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s deve ser uma lista", path)
+		}
+		if s.Items != nil {
+			for i, item := range items {
+				if err := s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s deve ser texto", path)
+		}
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, str); err != nil {
+				return fmt.Errorf("%s deve ser uma data RFC 3339", path)
+			}
+		}
+	case "integer", "number":
+		number, ok := value.(json.Number)
+		if !ok {
+			return fmt.Errorf("%s deve ser numérico", path)
+		}
+		if s.Type == "integer" {
+			if _, err := number.Int64(); err != nil {
+				return fmt.Errorf("%s deve ser inteiro", path)
+			}
+		}
+		f, err := number.Float64()
+		if err != nil {
+			return fmt.Errorf("%s deve ser numérico", path)
+		}
+		if s.Minimum != nil && f < *s.Minimum {
+			return fmt.Errorf("%s deve ser no mínimo %v", path, *s.Minimum)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s deve ser booleano", path)
+		}
+	default:
+		return fmt.Errorf("tipo de schema não suportado: %s", s.Type)
+	}
+	return nil
+}
+
+// normalizeNumber converte json.Number para float64, o tipo que
+// json.Unmarshal usa ao ler o const do schema
+func normalizeNumber(value interface{}) interface{} {
+	if number, ok := value.(json.Number); ok {
+		if f, err := number.Float64(); err == nil {
+			return f
+		}
+	}
+	return value
+}
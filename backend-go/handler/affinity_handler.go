@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"backend-go/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AffinityHandler struct {
+	service service.AffinityService
+}
+
+func NewAffinityHandler(service service.AffinityService) *AffinityHandler {
+	return &AffinityHandler{service: service}
+}
+
+// RegisterRoutes registra as rotas gerais de afinidade
+func (h *AffinityHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("/config", h.GetConfig)
+}
+
+// RegisterUserRoutes registra as rotas de afinidade dentro do grupo de usuários
+func (h *AffinityHandler) RegisterUserRoutes(rg *gin.RouterGroup) {
+	rg.GET("/:id/affinities", h.ListAffinities)
+	rg.GET("/:id/affinities/:content_id", h.GetAffinity)
+}
+
+// DTOs de Response
+type ListAffinitiesResponse struct {
+	Affinities []service.UserAffinity `json:"affinities"`
+	Total      int64                  `json:"total"`
+	Page       int                    `json:"page"`
+	Limit      int                    `json:"limit"`
+}
+
+type AffinityConfigResponse struct {
+	Weights       map[string]float64 `json:"weights"`
+	HalfLifeHours float64            `json:"half_life_hours"`
+	NeutralRating float64            `json:"neutral_rating"`
+}
+
+// GetConfig godoc
+// @Summary Pesos por tipo de interação e meia-vida usados nas afinidades
+// @Tags affinity
+// @Produce json
+// @Success 200 {object} AffinityConfigResponse
+// @Router /affinity/config [get]
+func (h *AffinityHandler) GetConfig(c *gin.Context) {
+	policy := h.service.Policy()
+	c.JSON(http.StatusOK, AffinityConfigResponse{
+		Weights:       policy.Weights,
+		HalfLifeHours: policy.HalfLife.Hours(),
+		NeutralRating: service.NeutralRating,
+	})
+}
+
+// ListAffinities godoc
+// @Summary Lista as afinidades de um usuário com conteúdos, da maior para a menor
+// @Tags affinity
+// @Produce json
+// @Param id path int true "ID do usuário"
+// @Param min_score query number false "Afinidade mínima"
+// @Param page query int false "Número da página" default(1)
+// @Param limit query int false "Itens por página" default(50)
+// @Success 200 {object} ListAffinitiesResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/{id}/affinities [get]
+func (h *AffinityHandler) ListAffinities(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de usuário inválido"})
+		return
+	}
+
+	var query service.AffinityQuery
+	query.Page, _ = strconv.Atoi(c.DefaultQuery("page", "1"))
+	query.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if raw := c.Query("min_score"); raw != "" {
+		minScore, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "min_score inválido"})
+			return
+		}
+		query.MinScore = &minScore
+	}
+
+	affinities, total, err := h.service.GetUserAffinities(uint(userID), query)
+	if err != nil {
+		c.JSON(affinityErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	query.Normalize()
+	c.JSON(http.StatusOK, ListAffinitiesResponse{
+		Affinities: affinities,
+		Total:      total,
+		Page:       query.Page,
+		Limit:      query.Limit,
+	})
+}
+
+// GetAffinity godoc
+// @Summary Afinidade atual de um usuário com um conteúdo
+// @Tags affinity
+// @Produce json
+// @Param id path int true "ID do usuário"
+// @Param content_id path int true "ID do conteúdo"
+// @Success 200 {object} service.UserAffinity
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/{id}/affinities/{content_id} [get]
+func (h *AffinityHandler) GetAffinity(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de usuário inválido"})
+		return
+	}
+	contentID, err := strconv.ParseUint(c.Param("content_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de conteúdo inválido"})
+		return
+	}
+
+	affinity, err := h.service.GetAffinity(uint(userID), uint(contentID))
+	if err != nil {
+		c.JSON(affinityErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, affinity)
+}
+
+// affinityErrorStatus traduz erros do serviço de afinidade em status HTTP
+func affinityErrorStatus(err error) int {
+	if errors.Is(err, service.ErrUserNotFound) || errors.Is(err, service.ErrContentNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
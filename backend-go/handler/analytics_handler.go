@@ -0,0 +1,290 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend-go/models"
+	"backend-go/repository"
+	"backend-go/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AnalyticsHandler struct {
+	service service.RollupService
+}
+
+func NewAnalyticsHandler(service service.RollupService) *AnalyticsHandler {
+	return &AnalyticsHandler{service: service}
+}
+
+// RegisterRoutes registra as rotas de analytics (lidas dos rollups diários)
+func (h *AnalyticsHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("/contents/top", h.GetTopContents)
+	rg.GET("/contents/:content_id/daily", h.GetContentDaily)
+	rg.GET("/users/:user_id/daily", h.GetUserDaily)
+	rg.GET("/rollups/status", h.GetRollupStatus)
+}
+
+// DTOs de Response
+type DailyStatResponse struct {
+	Day                string           `json:"day"`
+	Total              int64            `json:"total"`
+	InteractionsByType map[string]int64 `json:"interactions_by_type"`
+	AverageRating      *float64         `json:"average_rating,omitempty"`
+}
+
+type DailySeriesResponse struct {
+	From string              `json:"from"`
+	To   string              `json:"to"`
+	Days []DailyStatResponse `json:"days"`
+}
+
+type TopContentResponse struct {
+	ContentID     uint     `json:"content_id"`
+	Interactions  int64    `json:"interactions"`
+	AverageRating *float64 `json:"average_rating,omitempty"`
+}
+
+type TopContentsResponse struct {
+	From     string               `json:"from"`
+	To       string               `json:"to"`
+	Contents []TopContentResponse `json:"contents"`
+}
+
+const dayLayout = "2006-01-02"
+
+func newContentDailySeries(stats []models.ContentDailyStat, from, to time.Time) DailySeriesResponse {
+	var days []DailyStatResponse
+	var ratingSum float64
+	var ratingCount int64
+	for i := range stats {
+		day := stats[i].Day.Format(dayLayout)
+		if len(days) == 0 || days[len(days)-1].Day != day {
+			days = append(days, DailyStatResponse{Day: day, InteractionsByType: map[string]int64{}})
+			ratingSum, ratingCount = 0, 0
+		}
+		current := &days[len(days)-1]
+		current.InteractionsByType[stats[i].InteractionType] += stats[i].Interactions
+		current.Total += stats[i].Interactions
+		ratingSum += stats[i].RatingSum
+		ratingCount += stats[i].RatingCount
+		current.AverageRating = averageRating(ratingSum, ratingCount)
+	}
+	return newDailySeries(days, from, to)
+}
+
+func newUserDailySeries(stats []models.UserDailyStat, from, to time.Time) DailySeriesResponse {
+	var days []DailyStatResponse
+	for i := range stats {
+		day := stats[i].Day.Format(dayLayout)
+		if len(days) == 0 || days[len(days)-1].Day != day {
+			days = append(days, DailyStatResponse{Day: day, InteractionsByType: map[string]int64{}})
+		}
+		current := &days[len(days)-1]
+		current.InteractionsByType[stats[i].InteractionType] += stats[i].Interactions
+		current.Total += stats[i].Interactions
+	}
+	return newDailySeries(days, from, to)
+}
+
+func newDailySeries(days []DailyStatResponse, from, to time.Time) DailySeriesResponse {
+	if days == nil {
+		days = []DailyStatResponse{}
+	}
+	return DailySeriesResponse{
+		From: from.Format(dayLayout),
+		To:   to.Format(dayLayout),
+		Days: days,
+	}
+}
+
+func newTopContentsResponse(totals []repository.ContentTotals, from, to time.Time) TopContentsResponse {
+	contents := make([]TopContentResponse, len(totals))
+	for i, total := range totals {
+		contents[i] = TopContentResponse{
+			ContentID:     total.ContentID,
+			Interactions:  total.Interactions,
+			AverageRating: averageRating(total.RatingSum, total.RatingCount),
+		}
+	}
+	return TopContentsResponse{
+		From:     from.Format(dayLayout),
+		To:       to.Format(dayLayout),
+		Contents: contents,
+	}
+}
+
+func averageRating(sum float64, count int64) *float64 {
+	if count == 0 {
+		return nil
+	}
+	avg := sum / float64(count)
+	return &avg
+}
+
+// GetContentDaily godoc
+// @Summary Série diária de interações de um conteúdo
+// @Tags analytics
+// @Produce json
+// @Param content_id path int true "ID do conteúdo"
+// @Param from query string false "Primeiro dia (YYYY-MM-DD), padrão: 30 dias atrás"
+// @Param to query string false "Dia final, exclusivo (YYYY-MM-DD), padrão: amanhã"
+// @Success 200 {object} DailySeriesResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /analytics/contents/{content_id}/daily [get]
+func (h *AnalyticsHandler) GetContentDaily(c *gin.Context) {
+	contentID, err := strconv.ParseUint(c.Param("content_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de conteúdo inválido"})
+		return
+	}
+
+	from, to, err := parseDayRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	stats, err := h.service.GetContentDaily(uint(contentID), from, to)
+	if err != nil {
+		c.JSON(analyticsErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newContentDailySeries(stats, from, to))
+}
+
+// GetUserDaily godoc
+// @Summary Série diária de interações de um usuário
+// @Tags analytics
+// @Produce json
+// @Param user_id path int true "ID do usuário"
+// @Param from query string false "Primeiro dia (YYYY-MM-DD), padrão: 30 dias atrás"
+// @Param to query string false "Dia final, exclusivo (YYYY-MM-DD), padrão: amanhã"
+// @Success 200 {object} DailySeriesResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /analytics/users/{user_id}/daily [get]
+func (h *AnalyticsHandler) GetUserDaily(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de usuário inválido"})
+		return
+	}
+
+	from, to, err := parseDayRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	stats, err := h.service.GetUserDaily(uint(userID), from, to)
+	if err != nil {
+		c.JSON(analyticsErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newUserDailySeries(stats, from, to))
+}
+
+// GetTopContents godoc
+// @Summary Conteúdos com mais interações no período
+// @Tags analytics
+// @Produce json
+// @Param from query string false "Primeiro dia (YYYY-MM-DD), padrão: 30 dias atrás"
+// @Param to query string false "Dia final, exclusivo (YYYY-MM-DD), padrão: amanhã"
+// @Param types query string false "Tipos de interação separados por vírgula"
+// @Param limit query int false "Quantidade de conteúdos" default(20)
+// @Success 200 {object} TopContentsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /analytics/contents/top [get]
+func (h *AnalyticsHandler) GetTopContents(c *gin.Context) {
+	from, to, err := parseDayRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var types []string
+	if raw := c.Query("types"); raw != "" {
+		for _, t := range strings.Split(raw, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				types = append(types, t)
+			}
+		}
+	}
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	totals, err := h.service.GetTopContents(from, to, types, limit)
+	if err != nil {
+		c.JSON(analyticsErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newTopContentsResponse(totals, from, to))
+}
+
+// GetRollupStatus godoc
+// @Summary Estado do job de agregação dos rollups
+// @Tags analytics
+// @Produce json
+// @Success 200 {object} service.RollupStatus
+// @Failure 500 {object} map[string]string
+// @Router /analytics/rollups/status [get]
+func (h *AnalyticsHandler) GetRollupStatus(c *gin.Context) {
+	status, err := h.service.Status()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// parseDayRange lê from/to da query string; por padrão, os últimos 30 dias
+// incluindo hoje
+func parseDayRange(c *gin.Context) (time.Time, time.Time, error) {
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	to := today.AddDate(0, 0, 1)
+
+	if raw := c.Query("to"); raw != "" {
+		parsed, err := time.ParseInLocation(dayLayout, raw, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("to inválido, use YYYY-MM-DD")
+		}
+		to = parsed
+	}
+
+	from := to.AddDate(0, 0, -30)
+	if raw := c.Query("from"); raw != "" {
+		parsed, err := time.ParseInLocation(dayLayout, raw, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, errors.New("from inválido, use YYYY-MM-DD")
+		}
+		from = parsed
+	}
+
+	return from, to, nil
+}
+
+// analyticsErrorStatus traduz erros do serviço de analytics em status HTTP
+func analyticsErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrUserNotFound), errors.Is(err, service.ErrContentNotFound):
+		return http.StatusNotFound
+	case strings.HasPrefix(err.Error(), "período"),
+		strings.HasPrefix(err.Error(), "tipo de interação inválido"):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,164 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"backend-go/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BookmarkHandler struct {
+	service service.BookmarkService
+}
+
+func NewBookmarkHandler(service service.BookmarkService) *BookmarkHandler {
+	return &BookmarkHandler{service: service}
+}
+
+// RegisterUserRoutes registra as rotas de conteúdos salvos dentro do grupo de usuários
+func (h *BookmarkHandler) RegisterUserRoutes(rg *gin.RouterGroup) {
+	rg.GET("/:id/bookmarks", h.ListBookmarks)
+	rg.POST("/:id/bookmarks/:content_id", h.AddBookmark)
+	rg.DELETE("/:id/bookmarks/:content_id", h.RemoveBookmark)
+}
+
+// DTOs de Response
+type BookmarkResponse struct {
+	UserID    uint      `json:"user_id"`
+	ContentID uint      `json:"content_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ListBookmarksResponse struct {
+	Contents []ContentResponse `json:"contents"`
+	Total    int64             `json:"total"`
+	Page     int               `json:"page"`
+	Limit    int               `json:"limit"`
+}
+
+// parseBookmarkParams lê os IDs de usuário e conteúdo da rota
+func parseBookmarkParams(c *gin.Context) (uint, uint, bool) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de usuário inválido"})
+		return 0, 0, false
+	}
+	contentID, err := strconv.ParseUint(c.Param("content_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de conteúdo inválido"})
+		return 0, 0, false
+	}
+	return uint(userID), uint(contentID), true
+}
+
+// AddBookmark godoc
+// @Summary Salva um conteúdo para ver depois
+// @Tags bookmarks
+// @Produce json
+// @Param id path int true "ID do usuário"
+// @Param content_id path int true "ID do conteúdo"
+// @Success 201 {object} BookmarkResponse
+// @Success 200 {object} BookmarkResponse "Conteúdo já estava salvo"
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /users/{id}/bookmarks/{content_id} [post]
+func (h *BookmarkHandler) AddBookmark(c *gin.Context) {
+	userID, contentID, ok := parseBookmarkParams(c)
+	if !ok {
+		return
+	}
+
+	bookmark, created, err := h.service.AddBookmark(userID, contentID)
+	if err != nil {
+		if err.Error() == "usuário não encontrado" || err.Error() == "conteúdo não encontrado" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := BookmarkResponse{
+		UserID:    bookmark.UserID,
+		ContentID: bookmark.ContentID,
+		CreatedAt: bookmark.CreatedAt,
+	}
+	if !created {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// RemoveBookmark godoc
+// @Summary Remove um conteúdo salvo
+// @Tags bookmarks
+// @Produce json
+// @Param id path int true "ID do usuário"
+// @Param content_id path int true "ID do conteúdo"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /users/{id}/bookmarks/{content_id} [delete]
+func (h *BookmarkHandler) RemoveBookmark(c *gin.Context) {
+	userID, contentID, ok := parseBookmarkParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemoveBookmark(userID, contentID); err != nil {
+		if err.Error() == "conteúdo não está salvo" {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "conteúdo removido dos salvos"})
+}
+
+// ListBookmarks godoc
+// @Summary Lista os conteúdos salvos de um usuário
+// @Tags bookmarks
+// @Produce json
+// @Param id path int true "ID do usuário"
+// @Param page query int false "Número da página" default(1)
+// @Param limit query int false "Itens por página" default(20)
+// @Success 200 {object} ListBookmarksResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /users/{id}/bookmarks [get]
+func (h *BookmarkHandler) ListBookmarks(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de usuário inválido"})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	bookmarks, total, err := h.service.ListBookmarks(uint(userID), page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	contents := make([]ContentResponse, len(bookmarks))
+	for i := range bookmarks {
+		contents[i] = newContentResponse(&bookmarks[i].Content)
+		contents[i].Bookmarked = true
+	}
+
+	c.JSON(http.StatusOK, ListBookmarksResponse{
+		Contents: contents,
+		Total:    total,
+		Page:     page,
+		Limit:    limit,
+	})
+}
@@ -0,0 +1,430 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"backend-go/models"
+	"backend-go/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CollectionHandler struct {
+	service service.CollectionService
+}
+
+func NewCollectionHandler(service service.CollectionService) *CollectionHandler {
+	return &CollectionHandler{service: service}
+}
+
+// RegisterRoutes registra as rotas do handler de coleções
+func (h *CollectionHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.POST("", h.CreateCollection)
+	rg.GET("", h.ListCollections)
+	rg.GET("/shared/:slug", h.GetSharedCollection)
+	rg.GET("/:id", h.GetCollection)
+	rg.PUT("/:id", h.UpdateCollection)
+	rg.DELETE("/:id", h.DeleteCollection)
+	rg.POST("/:id/items", h.AddItem)
+	rg.DELETE("/:id/items/:content_id", h.RemoveItem)
+	rg.PUT("/:id/items/order", h.ReorderItems)
+}
+
+// DTOs de Request
+type CreateCollectionRequest struct {
+	OwnerID     uint   `json:"owner_id" binding:"required"`
+	Name        string `json:"name" binding:"required,max=120"`
+	Description string `json:"description" binding:"max=1000"`
+	Visibility  string `json:"visibility" binding:"omitempty,oneof=public private"`
+	Editorial   bool   `json:"editorial"`
+}
+
+type UpdateCollectionRequest struct {
+	UserID      uint    `json:"user_id" binding:"required"`
+	Name        *string `json:"name,omitempty" binding:"omitempty,max=120"`
+	Description *string `json:"description,omitempty" binding:"omitempty,max=1000"`
+	Visibility  *string `json:"visibility,omitempty" binding:"omitempty,oneof=public private"`
+}
+
+type AddCollectionItemRequest struct {
+	UserID    uint `json:"user_id" binding:"required"`
+	ContentID uint `json:"content_id" binding:"required"`
+}
+
+type ReorderCollectionItemsRequest struct {
+	UserID     uint   `json:"user_id" binding:"required"`
+	ContentIDs []uint `json:"content_ids" binding:"required"`
+}
+
+// DTOs de Response
+type CollectionItemResponse struct {
+	Position int             `json:"position"`
+	AddedAt  time.Time       `json:"added_at"`
+	Content  ContentResponse `json:"content"`
+}
+
+type CollectionResponse struct {
+	ID          uint                     `json:"id"`
+	OwnerID     uint                     `json:"owner_id"`
+	Name        string                   `json:"name"`
+	Slug        string                   `json:"slug"`
+	Description string                   `json:"description"`
+	Visibility  string                   `json:"visibility"`
+	Editorial   bool                     `json:"editorial"`
+	CreatedAt   time.Time                `json:"created_at"`
+	UpdatedAt   time.Time                `json:"updated_at"`
+	Items       []CollectionItemResponse `json:"items,omitempty"`
+}
+
+type ListCollectionsResponse struct {
+	Collections []CollectionResponse `json:"collections"`
+	Total       int64                `json:"total"`
+	Page        int                  `json:"page"`
+	Limit       int                  `json:"limit"`
+}
+
+func newCollectionResponse(c *models.Collection) CollectionResponse {
+	items := make([]CollectionItemResponse, len(c.Items))
+	for i := range c.Items {
+		items[i] = CollectionItemResponse{
+			Position: c.Items[i].Position,
+			AddedAt:  c.Items[i].AddedAt,
+			Content:  newContentResponse(&c.Items[i].Content),
+		}
+	}
+
+	return CollectionResponse{
+		ID:          c.ID,
+		OwnerID:     c.OwnerID,
+		Name:        c.Name,
+		Slug:        c.Slug,
+		Description: c.Description,
+		Visibility:  c.Visibility,
+		Editorial:   c.Editorial,
+		CreatedAt:   c.CreatedAt,
+		UpdatedAt:   c.UpdatedAt,
+		Items:       items,
+	}
+}
+
+// collectionErrorStatus traduz erros do serviço de coleções em status HTTP
+func collectionErrorStatus(err error) int {
+	switch err.Error() {
+	case "coleção não encontrada", "conteúdo não encontrado", "usuário não encontrado", "conteúdo não está na coleção":
+		return http.StatusNotFound
+	case "apenas o dono pode alterar a coleção", "apenas editores podem criar coleções editoriais":
+		return http.StatusForbidden
+	case "conteúdo já está na coleção":
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// CreateCollection godoc
+// @Summary Cria uma nova coleção
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Param collection body CreateCollectionRequest true "Dados da coleção"
+// @Success 201 {object} CollectionResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /collections [post]
+func (h *CollectionHandler) CreateCollection(c *gin.Context) {
+	var req CreateCollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	collection, err := h.service.CreateCollection(service.CreateCollectionRequest{
+		OwnerID:     req.OwnerID,
+		Name:        req.Name,
+		Description: req.Description,
+		Visibility:  req.Visibility,
+		Editorial:   req.Editorial,
+	})
+	if err != nil {
+		c.JSON(collectionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, newCollectionResponse(collection))
+}
+
+// ListCollections godoc
+// @Summary Lista coleções públicas e as coleções do próprio usuário
+// @Tags collections
+// @Produce json
+// @Param viewer_id query int false "ID do usuário que está consultando"
+// @Param owner_id query int false "Filtro por dono"
+// @Param editorial query bool false "Apenas coleções editoriais"
+// @Param page query int false "Número da página" default(1)
+// @Param limit query int false "Itens por página" default(20)
+// @Success 200 {object} ListCollectionsResponse
+// @Failure 500 {object} map[string]string
+// @Router /collections [get]
+func (h *CollectionHandler) ListCollections(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	viewerID, _ := strconv.ParseUint(c.Query("viewer_id"), 10, 32)
+	editorialOnly, _ := strconv.ParseBool(c.Query("editorial"))
+
+	var ownerID *uint
+	if ownerParam, err := strconv.ParseUint(c.Query("owner_id"), 10, 32); err == nil {
+		owner := uint(ownerParam)
+		ownerID = &owner
+	}
+
+	collections, total, err := h.service.ListCollections(ownerID, uint(viewerID), editorialOnly, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	responses := make([]CollectionResponse, len(collections))
+	for i := range collections {
+		responses[i] = newCollectionResponse(&collections[i])
+	}
+
+	c.JSON(http.StatusOK, ListCollectionsResponse{
+		Collections: responses,
+		Total:       total,
+		Page:        page,
+		Limit:       limit,
+	})
+}
+
+// GetCollection godoc
+// @Summary Busca uma coleção pelo ID
+// @Tags collections
+// @Produce json
+// @Param id path int true "ID da coleção"
+// @Param viewer_id query int false "ID do usuário que está consultando"
+// @Success 200 {object} CollectionResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /collections/{id} [get]
+func (h *CollectionHandler) GetCollection(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+	viewerID, _ := strconv.ParseUint(c.Query("viewer_id"), 10, 32)
+
+	collection, err := h.service.GetCollection(uint(id), uint(viewerID))
+	if err != nil {
+		c.JSON(collectionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newCollectionResponse(collection))
+}
+
+// GetSharedCollection godoc
+// @Summary Busca uma coleção pública pelo link compartilhável
+// @Tags collections
+// @Produce json
+// @Param slug path string true "Slug da coleção"
+// @Success 200 {object} CollectionResponse
+// @Failure 404 {object} map[string]string
+// @Router /collections/shared/{slug} [get]
+func (h *CollectionHandler) GetSharedCollection(c *gin.Context) {
+	collection, err := h.service.GetSharedCollection(c.Param("slug"))
+	if err != nil {
+		c.JSON(collectionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newCollectionResponse(collection))
+}
+
+// UpdateCollection godoc
+// @Summary Atualiza uma coleção (somente o dono)
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Param id path int true "ID da coleção"
+// @Param collection body UpdateCollectionRequest true "Dados para atualização"
+// @Success 200 {object} CollectionResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /collections/{id} [put]
+func (h *CollectionHandler) UpdateCollection(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var req UpdateCollectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	collection, err := h.service.UpdateCollection(uint(id), req.UserID, service.UpdateCollectionRequest{
+		Name:        req.Name,
+		Description: req.Description,
+		Visibility:  req.Visibility,
+	})
+	if err != nil {
+		c.JSON(collectionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newCollectionResponse(collection))
+}
+
+// DeleteCollection godoc
+// @Summary Remove uma coleção (somente o dono)
+// @Tags collections
+// @Produce json
+// @Param id path int true "ID da coleção"
+// @Param user_id query int true "ID do dono"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /collections/{id} [delete]
+func (h *CollectionHandler) DeleteCollection(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Query("user_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de usuário inválido"})
+		return
+	}
+
+	if err := h.service.DeleteCollection(uint(id), uint(userID)); err != nil {
+		c.JSON(collectionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "coleção removida com sucesso"})
+}
+
+// AddItem godoc
+// @Summary Adiciona um conteúdo ao final da coleção
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Param id path int true "ID da coleção"
+// @Param item body AddCollectionItemRequest true "Conteúdo a adicionar"
+// @Success 200 {object} CollectionResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /collections/{id}/items [post]
+func (h *CollectionHandler) AddItem(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var req AddCollectionItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	collection, err := h.service.AddItem(uint(id), req.UserID, req.ContentID)
+	if err != nil {
+		c.JSON(collectionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newCollectionResponse(collection))
+}
+
+// RemoveItem godoc
+// @Summary Remove um conteúdo da coleção
+// @Tags collections
+// @Produce json
+// @Param id path int true "ID da coleção"
+// @Param content_id path int true "ID do conteúdo"
+// @Param user_id query int true "ID do dono"
+// @Success 200 {object} CollectionResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /collections/{id}/items/{content_id} [delete]
+func (h *CollectionHandler) RemoveItem(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	contentID, err := strconv.ParseUint(c.Param("content_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de conteúdo inválido"})
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Query("user_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de usuário inválido"})
+		return
+	}
+
+	collection, err := h.service.RemoveItem(uint(id), uint(userID), uint(contentID))
+	if err != nil {
+		c.JSON(collectionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newCollectionResponse(collection))
+}
+
+// ReorderItems godoc
+// @Summary Reordena os itens da coleção
+// @Tags collections
+// @Accept json
+// @Produce json
+// @Param id path int true "ID da coleção"
+// @Param order body ReorderCollectionItemsRequest true "IDs dos conteúdos na nova ordem"
+// @Success 200 {object} CollectionResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /collections/{id}/items/order [put]
+func (h *CollectionHandler) ReorderItems(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var req ReorderCollectionItemsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	collection, err := h.service.ReorderItems(uint(id), req.UserID, req.ContentIDs)
+	if err != nil {
+		c.JSON(collectionErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newCollectionResponse(collection))
+}
@@ -0,0 +1,284 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"backend-go/models"
+	"backend-go/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CommentHandler struct {
+	service service.CommentService
+}
+
+func NewCommentHandler(service service.CommentService) *CommentHandler {
+	return &CommentHandler{service: service}
+}
+
+// RegisterContentRoutes registra as rotas de comentários dentro do grupo de conteúdos
+func (h *CommentHandler) RegisterContentRoutes(rg *gin.RouterGroup) {
+	rg.POST("/:id/comments", h.CreateComment)
+	rg.GET("/:id/comments", h.ListComments)
+	rg.GET("/:id/comments/count", h.CountComments)
+}
+
+// RegisterRoutes registra as rotas de edição e remoção de comentários
+func (h *CommentHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.PUT("/:id", h.UpdateComment)
+	rg.DELETE("/:id", h.DeleteComment)
+}
+
+// DTOs de Request
+type CreateCommentRequest struct {
+	UserID   uint   `json:"user_id" binding:"required"`
+	ParentID *uint  `json:"parent_id,omitempty"`
+	Body     string `json:"body" binding:"required,max=2000"`
+}
+
+type UpdateCommentRequest struct {
+	UserID uint   `json:"user_id" binding:"required"`
+	Body   string `json:"body" binding:"required,max=2000"`
+}
+
+// DTOs de Response
+type CommentResponse struct {
+	ID        uint      `json:"id"`
+	ContentID uint      `json:"content_id"`
+	UserID    uint      `json:"user_id"`
+	ParentID  *uint     `json:"parent_id,omitempty"`
+	Body      string    `json:"body"`
+	Deleted   bool      `json:"deleted"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type CommentThreadResponse struct {
+	CommentResponse
+	Replies []CommentResponse `json:"replies"`
+}
+
+type ListCommentsResponse struct {
+	Comments []CommentThreadResponse `json:"comments"`
+	Total    int64                   `json:"total"`
+	Page     int                     `json:"page"`
+	Limit    int                     `json:"limit"`
+}
+
+type CommentCountResponse struct {
+	ContentID uint  `json:"content_id"`
+	Count     int64 `json:"count"`
+}
+
+func newCommentResponse(c *models.Comment) CommentResponse {
+	return CommentResponse{
+		ID:        c.ID,
+		ContentID: c.ContentID,
+		UserID:    c.UserID,
+		ParentID:  c.ParentID,
+		Body:      c.Body,
+		Deleted:   c.Deleted,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+	}
+}
+
+// commentErrorStatus traduz erros do serviço de comentários em status HTTP
+func commentErrorStatus(err error) int {
+	switch err.Error() {
+	case "conteúdo não encontrado", "comentário não encontrado":
+		return http.StatusNotFound
+	case "apenas o autor pode alterar o comentário":
+		return http.StatusForbidden
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// commentListErrorStatus traduz erros das consultas de comentários em status HTTP
+func commentListErrorStatus(err error) int {
+	if err.Error() == "ID inválido" {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// CreateComment godoc
+// @Summary Comenta um conteúdo ou responde a um comentário
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path int true "ID do conteúdo"
+// @Param comment body CreateCommentRequest true "Dados do comentário"
+// @Success 201 {object} CommentResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /contents/{id}/comments [post]
+func (h *CommentHandler) CreateComment(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var req CreateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := h.service.CreateComment(uint(id), req.UserID, req.ParentID, req.Body)
+	if err != nil {
+		c.JSON(commentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	// A interação "comment" gravada pelo serviço notifica o motor Python via outbox
+	c.JSON(http.StatusCreated, newCommentResponse(comment))
+}
+
+// ListComments godoc
+// @Summary Lista os comentários de um conteúdo em threads
+// @Tags comments
+// @Produce json
+// @Param id path int true "ID do conteúdo"
+// @Param page query int false "Número da página" default(1)
+// @Param limit query int false "Threads por página" default(20)
+// @Success 200 {object} ListCommentsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /contents/{id}/comments [get]
+func (h *CommentHandler) ListComments(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	threads, total, err := h.service.ListContentComments(uint(id), page, limit)
+	if err != nil {
+		c.JSON(commentListErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	responses := make([]CommentThreadResponse, len(threads))
+	for i := range threads {
+		replies := make([]CommentResponse, len(threads[i].Replies))
+		for j := range threads[i].Replies {
+			replies[j] = newCommentResponse(&threads[i].Replies[j])
+		}
+		responses[i] = CommentThreadResponse{
+			CommentResponse: newCommentResponse(&threads[i].Comment),
+			Replies:         replies,
+		}
+	}
+
+	c.JSON(http.StatusOK, ListCommentsResponse{
+		Comments: responses,
+		Total:    total,
+		Page:     page,
+		Limit:    limit,
+	})
+}
+
+// CountComments godoc
+// @Summary Retorna o número de comentários de um conteúdo
+// @Tags comments
+// @Produce json
+// @Param id path int true "ID do conteúdo"
+// @Success 200 {object} CommentCountResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /contents/{id}/comments/count [get]
+func (h *CommentHandler) CountComments(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	count, err := h.service.CountContentComments(uint(id))
+	if err != nil {
+		c.JSON(commentListErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, CommentCountResponse{ContentID: uint(id), Count: count})
+}
+
+// UpdateComment godoc
+// @Summary Edita um comentário (somente o autor)
+// @Tags comments
+// @Accept json
+// @Produce json
+// @Param id path int true "ID do comentário"
+// @Param comment body UpdateCommentRequest true "Novo texto"
+// @Success 200 {object} CommentResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /comments/{id} [put]
+func (h *CommentHandler) UpdateComment(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var req UpdateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	comment, err := h.service.UpdateComment(uint(id), req.UserID, req.Body)
+	if err != nil {
+		c.JSON(commentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newCommentResponse(comment))
+}
+
+// DeleteComment godoc
+// @Summary Remove um comentário (somente o autor)
+// @Tags comments
+// @Produce json
+// @Param id path int true "ID do comentário"
+// @Param user_id query int true "ID do autor"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Router /comments/{id} [delete]
+func (h *CommentHandler) DeleteComment(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Query("user_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de usuário inválido"})
+		return
+	}
+
+	if err := h.service.DeleteComment(uint(id), uint(userID)); err != nil {
+		c.JSON(commentErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "comentário removido com sucesso"})
+}
@@ -12,11 +12,15 @@ import (
 )
 
 type ContentHandler struct {
-	service service.ContentService
+	service         service.ContentService
+	bookmarkService service.BookmarkService
 }
 
-func NewContentHandler(service service.ContentService) *ContentHandler {
-	return &ContentHandler{service: service}
+func NewContentHandler(service service.ContentService, bookmarkService service.BookmarkService) *ContentHandler {
+	return &ContentHandler{
+		service:         service,
+		bookmarkService: bookmarkService,
+	}
 }
 
 // RegisterRoutes registra as rotas do handler de conteúdo
@@ -52,8 +56,10 @@ type ContentResponse struct {
 	Description string             `json:"description"`
 	Type        string             `json:"type"`
 	ReleaseDate time.Time          `json:"release_date"`
+	Hidden      bool               `json:"hidden"`
 	CreatedAt   time.Time          `json:"created_at"`
 	Categories  []CategoryResponse `json:"categories,omitempty"`
+	Bookmarked  bool               `json:"bookmarked,omitempty"`
 }
 
 type CategoryResponse struct {
@@ -84,6 +90,7 @@ func newContentResponse(c *models.Content) ContentResponse {
 		Description: c.Description,
 		Type:        c.Type,
 		ReleaseDate: c.ReleaseDate,
+		Hidden:      c.Hidden,
 		CreatedAt:   c.CreatedAt,
 		Categories:  categories,
 	}
@@ -129,6 +136,7 @@ func (h *ContentHandler) CreateContent(c *gin.Context) {
 // @Tags contents
 // @Produce json
 // @Param id path int true "ID do conteúdo"
+// @Param user_id query int false "Marca se o conteúdo está salvo por este usuário"
 // @Success 200 {object} ContentResponse
 // @Failure 400 {object} map[string]string
 // @Failure 404 {object} map[string]string
@@ -151,7 +159,17 @@ func (h *ContentHandler) GetContentByID(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, newContentResponse(content))
+	response := newContentResponse(content)
+	if userID, err := strconv.ParseUint(c.Query("user_id"), 10, 32); err == nil {
+		bookmarked, err := h.bookmarkService.BookmarkedSet(uint(userID), []uint{content.ID})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		response.Bookmarked = bookmarked[content.ID]
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
 // ListContents godoc
@@ -161,6 +179,7 @@ func (h *ContentHandler) GetContentByID(c *gin.Context) {
 // @Param page query int false "Número da página" default(1)
 // @Param limit query int false "Itens por página" default(20)
 // @Param type query string false "Filtro por tipo (article, video, podcast, book, course)"
+// @Param user_id query int false "Marca os conteúdos salvos por este usuário"
 // @Success 200 {object} ListContentsResponse
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
@@ -187,6 +206,22 @@ func (h *ContentHandler) ListContents(c *gin.Context) {
 		contentResponses[i] = newContentResponse(&content)
 	}
 
+	// Marca os conteúdos já salvos pelo usuário, se informado
+	if userID, err := strconv.ParseUint(c.Query("user_id"), 10, 32); err == nil {
+		ids := make([]uint, len(contents))
+		for i := range contents {
+			ids[i] = contents[i].ID
+		}
+		bookmarked, err := h.bookmarkService.BookmarkedSet(uint(userID), ids)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		for i := range contentResponses {
+			contentResponses[i].Bookmarked = bookmarked[contentResponses[i].ID]
+		}
+	}
+
 	response := ListContentsResponse{
 		Contents: contentResponses,
 		Total:    total,
@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend-go/eventbus"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Espera máxima de um pull sem mensagens
+const maxBusFetchWait = 60 * time.Second
+
+type EventBusHandler struct {
+	bus eventbus.Bus
+}
+
+func NewEventBusHandler(bus eventbus.Bus) *EventBusHandler {
+	return &EventBusHandler{bus: bus}
+}
+
+// RegisterRoutes registra as rotas de schemas e de consumo do barramento
+func (h *EventBusHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("/schemas", h.ListSchemas)
+	rg.GET("/schemas/:subject", h.GetSchema)
+	rg.GET("/stream", h.GetStreamInfo)
+	rg.GET("/consumers/:durable/messages", h.FetchMessages)
+	rg.POST("/consumers/:durable/ack", h.AckMessages)
+}
+
+// DTOs de Request
+type AckMessagesRequest struct {
+	Seq uint64 `json:"seq" binding:"required,min=1"`
+}
+
+// DTOs de Response
+type FetchMessagesResponse struct {
+	Messages []eventbus.Message `json:"messages"`
+	Count    int                `json:"count"`
+}
+
+// ListSchemas godoc
+// @Summary Lista os schemas (JSON Schema) dos eventos publicados no barramento
+// @Description Cada mensagem é um envelope {id, type, version, source, occurred_at, data} publicado no subject "<type>.v<version>".
+// @Tags bus
+// @Produce json
+// @Success 200 {array} eventbus.Schema
+// @Router /bus/schemas [get]
+func (h *EventBusHandler) ListSchemas(c *gin.Context) {
+	c.JSON(http.StatusOK, eventbus.Schemas())
+}
+
+// GetSchema godoc
+// @Summary Retorna o JSON Schema de um subject
+// @Tags bus
+// @Produce json
+// @Param subject path string true "Subject (ex.: content.published.v1)"
+// @Success 200 {object} map[string]interface{}
+// @Failure 404 {object} map[string]string
+// @Router /bus/schemas/{subject} [get]
+func (h *EventBusHandler) GetSchema(c *gin.Context) {
+	schema, err := eventbus.GetSchema(c.Param("subject"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	c.Data(http.StatusOK, "application/schema+json", schema.Schema)
+}
+
+// GetStreamInfo godoc
+// @Summary Faixa de sequências guardadas no broker e posição dos consumidores
+// @Tags bus
+// @Produce json
+// @Success 200 {object} eventbus.StreamInfo
+// @Failure 501 {object} map[string]string
+// @Router /bus/stream [get]
+func (h *EventBusHandler) GetStreamInfo(c *gin.Context) {
+	stream, ok := h.bus.(eventbus.Stream)
+	if !ok {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": eventbus.ErrNotDurable.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, stream.Info())
+}
+
+// FetchMessages godoc
+// @Summary Busca as próximas mensagens de um consumidor durável (pull)
+// @Description Retorna as mensagens posteriores à última confirmada pelo consumidor; sem mensagens, espera até wait.
+// @Description Um consumidor novo começa nas mensagens publicadas a partir da sua criação. Mensagens não confirmadas são entregues de novo,
+// @Description então o processamento deve tolerar repetições (use o id do envelope). Requer EVENT_BUS=embedded.
+// @Tags bus
+// @Produce json
+// @Param durable path string true "Nome do consumidor"
+// @Param subject query string false "Padrão de subject (* casa um token, > os restantes)" default(>)
+// @Param batch query int false "Máximo de mensagens" default(100) maximum(1000)
+// @Param wait query string false "Espera sem mensagens (ex.: 20s, máximo 60s)" default(0s)
+// @Success 200 {object} FetchMessagesResponse
+// @Failure 400 {object} map[string]string
+// @Failure 501 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /bus/consumers/{durable}/messages [get]
+func (h *EventBusHandler) FetchMessages(c *gin.Context) {
+	stream, ok := h.bus.(eventbus.Stream)
+	if !ok {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": eventbus.ErrNotDurable.Error()})
+		return
+	}
+
+	batch, err := strconv.Atoi(c.DefaultQuery("batch", "100"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "batch inválido"})
+		return
+	}
+	wait, err := time.ParseDuration(c.DefaultQuery("wait", "0s"))
+	if err != nil || wait < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "wait inválido, use uma duração como 20s"})
+		return
+	}
+	if wait > maxBusFetchWait {
+		wait = maxBusFetchWait
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), wait)
+	defer cancel()
+
+	messages, err := stream.Fetch(ctx, c.Param("durable"), c.DefaultQuery("subject", ">"), batch)
+	if err != nil {
+		c.JSON(eventBusErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, FetchMessagesResponse{Messages: messages, Count: len(messages)})
+}
+
+// AckMessages godoc
+// @Summary Confirma as mensagens de um consumidor durável até seq (cumulativo)
+// @Tags bus
+// @Accept json
+// @Produce json
+// @Param durable path string true "Nome do consumidor"
+// @Param ack body AckMessagesRequest true "Última sequência processada"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} map[string]string
+// @Failure 501 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /bus/consumers/{durable}/ack [post]
+func (h *EventBusHandler) AckMessages(c *gin.Context) {
+	stream, ok := h.bus.(eventbus.Stream)
+	if !ok {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": eventbus.ErrNotDurable.Error()})
+		return
+	}
+
+	var req AckMessagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	durable := c.Param("durable")
+	if err := stream.Ack(durable, req.Seq); err != nil {
+		c.JSON(eventBusErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"consumer": durable, "acked_seq": stream.Info().Consumers[durable]})
+}
+
+// eventBusErrorStatus traduz erros do barramento em status HTTP
+func eventBusErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, eventbus.ErrClosed):
+		return http.StatusServiceUnavailable
+	case strings.HasPrefix(err.Error(), "nome do consumidor"),
+		strings.HasPrefix(err.Error(), "padrão de subject"),
+		strings.HasPrefix(err.Error(), "seq "):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend-go/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Evento enviado quando parte dos eventos pedidos via Last-Event-ID já saiu
+// do histórico; o cliente deve recarregar o estado completo
+const streamGapEvent = "stream.gap"
+
+type EventStreamHandler struct {
+	service   service.EventStreamService
+	heartbeat time.Duration
+}
+
+func NewEventStreamHandler(service service.EventStreamService, heartbeat time.Duration) *EventStreamHandler {
+	if heartbeat <= 0 {
+		heartbeat = 15 * time.Second
+	}
+	return &EventStreamHandler{service: service, heartbeat: heartbeat}
+}
+
+// RegisterRoutes registra as rotas do stream de eventos
+func (h *EventStreamHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("/stream", h.Stream)
+}
+
+// Stream godoc
+// @Summary Stream (SSE) de interações e alterações de conteúdos em tempo real
+// @Description Cada mensagem traz id, event (tipo) e data (JSON do evento). Para retomar após uma queda, reconecte
+// @Description enviando o header Last-Event-ID (ou o parâmetro last_event_id) com o último ID recebido; os eventos
+// @Description ainda no histórico são reenviados. Se parte deles já foi descartada, um evento stream.gap é enviado antes.
+// @Description Conexões que não acompanham o ritmo dos eventos são encerradas e devem reconectar com Last-Event-ID.
+// @Tags events
+// @Produce text/event-stream
+// @Param types query string false "Tipos de evento separados por vírgula (content.published, content.updated, content.deleted, interaction.created)"
+// @Param content_id query int false "Apenas eventos deste conteúdo"
+// @Param last_event_id query int false "Alternativa ao header Last-Event-ID"
+// @Success 200 {object} service.StreamEvent
+// @Failure 400 {object} map[string]string
+// @Router /events/stream [get]
+func (h *EventStreamHandler) Stream(c *gin.Context) {
+	var contentID uint
+	if raw := c.Query("content_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil || id == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "content_id inválido"})
+			return
+		}
+		contentID = uint(id)
+	}
+
+	filter, err := service.ParseStreamFilter(c.Query("types"), contentID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var lastEventID uint64
+	rawLastID := c.GetHeader("Last-Event-ID")
+	if rawLastID == "" {
+		rawLastID = c.Query("last_event_id")
+	}
+	if rawLastID != "" {
+		lastEventID, err = strconv.ParseUint(strings.TrimSpace(rawLastID), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Last-Event-ID inválido"})
+			return
+		}
+	}
+
+	sub, replay, missed := h.service.Subscribe(filter, lastEventID)
+	defer h.service.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	if missed {
+		fmt.Fprintf(c.Writer, "event: %s\ndata: {}\n\n", streamGapEvent)
+	}
+	for _, event := range replay {
+		writeStreamEvent(c, event)
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-sub.Events:
+			if !ok {
+				// Cliente lento demais: encerra para que reconecte com Last-Event-ID
+				return
+			}
+			writeStreamEvent(c, event)
+			c.Writer.Flush()
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": ping\n\n")
+			c.Writer.Flush()
+		}
+	}
+}
+
+// writeStreamEvent escreve um evento no formato SSE; data é o evento completo em JSON
+func writeStreamEvent(c *gin.Context, event *service.StreamEvent) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+}
@@ -1,8 +1,14 @@
 package handler
 
 import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"backend-go/models"
 	"backend-go/service"
@@ -11,24 +17,22 @@ import (
 )
 
 type InteractionHandler struct {
-	service              service.InteractionService
-	recommendationService service.RecommendationService
+	service service.InteractionService
 }
 
-func NewInteractionHandler(
-	service service.InteractionService,
-	recommendationService service.RecommendationService,
-) *InteractionHandler {
-	return &InteractionHandler{
-		service:              service,
-		recommendationService: recommendationService,
-	}
+func NewInteractionHandler(service service.InteractionService) *InteractionHandler {
+	return &InteractionHandler{service: service}
 }
 
 func (h *InteractionHandler) RegisterRoutes(rg *gin.RouterGroup) {
 	rg.POST("", h.CreateInteraction)
+	rg.POST("/batch", h.CreateInteractionsBatch)
 	rg.GET("/user/:user_id", h.GetUserInteractions)
 	rg.GET("/content/:content_id", h.GetContentInteractions)
+	rg.GET("/flagged", h.GetFlaggedInteractions)
+	rg.GET("/reactions/:user_id/:content_id", h.GetReaction)
+	rg.PUT("/reactions/:user_id/:content_id", h.UpdateReaction)
+	rg.DELETE("/reactions/:user_id/:content_id", h.RetractReaction)
 }
 
 // DTOs de Request
@@ -37,27 +41,59 @@ type CreateInteractionRequest struct {
 	ContentID       uint     `json:"content_id" binding:"required"`
 	InteractionType string   `json:"interaction_type" binding:"required,oneof=view like dislike rating share comment"`
 	Rating          *float64 `json:"rating,omitempty"`
+	SessionID       *string  `json:"session_id,omitempty" binding:"omitempty,max=64"`
+	Device          *string  `json:"device,omitempty" binding:"omitempty,max=32"`
+	AppVersion      *string  `json:"app_version,omitempty" binding:"omitempty,max=32"`
+	Surface         *string  `json:"surface,omitempty" binding:"omitempty,oneof=home search recs collection"`
+	Position        *int     `json:"position,omitempty" binding:"omitempty,min=0,max=1000"`
+	DwellMs         *int     `json:"dwell_ms,omitempty" binding:"omitempty,min=0,max=86400000"`
 }
 
 // DTO de Response
 type InteractionResponse struct {
-	ID              uint     `json:"id"`
-	UserID          uint     `json:"user_id"`
-	ContentID       uint     `json:"content_id"`
-	InteractionType string   `json:"interaction_type"`
-	Rating          *float64 `json:"rating,omitempty"`
-	CreatedAt       string   `json:"created_at"`
+	ID              uint             `json:"id"`
+	UserID          uint             `json:"user_id"`
+	ContentID       uint             `json:"content_id"`
+	InteractionType string           `json:"interaction_type"`
+	Rating          *float64         `json:"rating,omitempty"`
+	SessionID       *string          `json:"session_id,omitempty"`
+	Device          *string          `json:"device,omitempty"`
+	AppVersion      *string          `json:"app_version,omitempty"`
+	Surface         *string          `json:"surface,omitempty"`
+	Position        *int             `json:"position,omitempty"`
+	DwellMs         *int             `json:"dwell_ms,omitempty"`
+	CreatedAt       string           `json:"created_at"`
+	Content         *ContentResponse `json:"content,omitempty"`
+	User            *userResponse    `json:"user,omitempty"`
 }
 
 func newInteractionResponse(interaction *models.UserInteraction) InteractionResponse {
-	return InteractionResponse{
+	resp := InteractionResponse{
 		ID:              interaction.ID,
 		UserID:          interaction.UserID,
 		ContentID:       interaction.ContentID,
 		InteractionType: interaction.InteractionType,
 		Rating:          interaction.Rating,
+		SessionID:       interaction.SessionID,
+		Device:          interaction.Device,
+		AppVersion:      interaction.AppVersion,
+		Surface:         interaction.Surface,
+		Position:        interaction.Position,
+		DwellMs:         interaction.DwellMs,
 		CreatedAt:       interaction.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}
+
+	// Relações só vêm preenchidas quando carregadas via preload
+	if interaction.Content.ID != 0 {
+		content := newContentResponse(&interaction.Content)
+		resp.Content = &content
+	}
+	if interaction.User.ID != 0 {
+		user := newUserResponse(&interaction.User)
+		resp.User = &user
+	}
+
+	return resp
 }
 
 // CreateInteraction godoc
@@ -68,6 +104,8 @@ func newInteractionResponse(interaction *models.UserInteraction) InteractionResp
 // @Param interaction body CreateInteractionRequest true "Dados da interação"
 // @Success 201 {object} InteractionResponse
 // @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 429 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /interactions [post]
 func (h *InteractionHandler) CreateInteraction(c *gin.Context) {
@@ -77,36 +115,143 @@ func (h *InteractionHandler) CreateInteraction(c *gin.Context) {
 		return
 	}
 
+	meta := service.InteractionMetadata{
+		SessionID:  req.SessionID,
+		Device:     req.Device,
+		AppVersion: req.AppVersion,
+		Surface:    req.Surface,
+		Position:   req.Position,
+		DwellMs:    req.DwellMs,
+	}
+
 	interaction, err := h.service.CreateInteraction(
 		req.UserID,
 		req.ContentID,
 		req.InteractionType,
 		req.Rating,
+		meta,
 	)
 	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) || errors.Is(err, service.ErrContentNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	// O motor Python é notificado pelo outbox, gravado junto com a interação
 	c.JSON(http.StatusCreated, newInteractionResponse(interaction))
-	
-	// Notificar motor Python em background (não bloqueia resposta)
-	go func() {
-		_ = h.recommendationService.NotifyNewInteraction(
-			req.UserID,
-			req.ContentID,
-			req.InteractionType,
-			req.Rating,
-		)
-	}()
+}
+
+// DTO de Response do lote
+type BatchInteractionsResponse struct {
+	Results   []service.BatchInteractionResult `json:"results"`
+	Created   int                              `json:"created"`
+	Duplicate int                              `json:"duplicate"`
+	Invalid   int                              `json:"invalid"`
+	Failed    int                              `json:"failed"`
+}
+
+// Tamanho máximo do corpo de um lote (5 MB)
+const maxBatchBodyBytes = 5 << 20
+
+// CreateInteractionsBatch godoc
+// @Summary Registra um lote de interações enfileiradas offline
+// @Description Aceita um array JSON ou NDJSON (Content-Type: application/x-ndjson).
+// @Description Cada item traz client_event_id e, opcionalmente, client_timestamp. Além dos tipos de POST /interactions, aceita as retratações unlike, undislike e unrate.
+// @Description A resposta informa o status de cada item: created, duplicate, invalid (malformado ou reprovado na validação; não reenviar) ou failed (pode ser reenviado).
+// @Description Um item inválido não rejeita o lote; 400 só é retornado se o corpo não for um array/NDJSON ou se o lote estiver vazio ou acima de 500 itens.
+// @Tags interactions
+// @Accept json
+// @Accept x-ndjson
+// @Produce json
+// @Param interactions body []service.BatchInteractionItem true "Interações do lote"
+// @Success 200 {object} BatchInteractionsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 429 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /interactions/batch [post]
+func (h *InteractionHandler) CreateInteractionsBatch(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBatchBodyBytes)
+
+	items, err := decodeBatchItems(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	results, err := h.service.CreateInteractionsBatch(items)
+	if err != nil {
+		if errors.Is(err, service.ErrEmptyBatch) || errors.Is(err, service.ErrBatchTooLarge) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := BatchInteractionsResponse{Results: results}
+	for _, result := range results {
+		switch result.Status {
+		case service.BatchItemCreated:
+			response.Created++
+		case service.BatchItemDuplicate:
+			response.Duplicate++
+		case service.BatchItemInvalid:
+			response.Invalid++
+		case service.BatchItemFailed:
+			response.Failed++
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// decodeBatchItems separa os itens do lote, em array JSON ou NDJSON conforme o
+// Content-Type. Cada item é validado pelo serviço.
+func decodeBatchItems(c *gin.Context) ([]json.RawMessage, error) {
+	var items []json.RawMessage
+
+	if !strings.Contains(c.ContentType(), "ndjson") {
+		if err := json.NewDecoder(c.Request.Body).Decode(&items); err != nil {
+			return nil, err
+		}
+		return items, nil
+	}
+
+	scanner := bufio.NewScanner(c.Request.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		items = append(items, json.RawMessage(append([]byte(nil), line...)))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
 }
 
 // GetUserInteractions godoc
-// @Summary Lista todas as interações de um usuário
+// @Summary Lista o histórico de interações de um usuário
 // @Tags interactions
 // @Produce json
 // @Param user_id path int true "ID do usuário"
+// @Param from query string false "Início do período (RFC3339 ou YYYY-MM-DD)"
+// @Param to query string false "Fim do período: exclusivo em RFC3339; YYYY-MM-DD inclui o dia inteiro"
+// @Param types query string false "Tipos de interação separados por vírgula"
+// @Param min_rating query number false "Nota mínima"
+// @Param page query int false "Página" default(1)
+// @Param limit query int false "Itens por página" default(50)
+// @Param preload query bool false "Inclui o conteúdo de cada interação" default(true)
 // @Success 200 {array} InteractionResponse
+// @Header 200 {integer} X-Total-Count "Total de interações que atendem aos filtros"
+// @Header 200 {integer} X-Page "Página retornada"
+// @Header 200 {integer} X-Limit "Itens por página"
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /interactions/user/{user_id} [get]
@@ -118,26 +263,37 @@ func (h *InteractionHandler) GetUserInteractions(c *gin.Context) {
 		return
 	}
 
-	interactions, err := h.service.GetUserInteractions(uint(userID))
+	query, err := parseInteractionQuery(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	responses := make([]InteractionResponse, len(interactions))
-	for i := range interactions {
-		responses[i] = newInteractionResponse(&interactions[i])
+	interactions, total, err := h.service.GetUserInteractions(uint(userID), query)
+	if err != nil {
+		c.JSON(interactionQueryErrorStatus(err), gin.H{"error": err.Error()})
+		return
 	}
 
-	c.JSON(http.StatusOK, responses)
+	writeInteractionList(c, interactions, total, query)
 }
 
 // GetContentInteractions godoc
-// @Summary Lista todas as interações de um conteúdo
+// @Summary Lista o histórico de interações de um conteúdo
 // @Tags interactions
 // @Produce json
 // @Param content_id path int true "ID do conteúdo"
+// @Param from query string false "Início do período (RFC3339 ou YYYY-MM-DD)"
+// @Param to query string false "Fim do período: exclusivo em RFC3339; YYYY-MM-DD inclui o dia inteiro"
+// @Param types query string false "Tipos de interação separados por vírgula"
+// @Param min_rating query number false "Nota mínima"
+// @Param page query int false "Página" default(1)
+// @Param limit query int false "Itens por página" default(50)
+// @Param preload query bool false "Inclui o usuário de cada interação" default(true)
 // @Success 200 {array} InteractionResponse
+// @Header 200 {integer} X-Total-Count "Total de interações que atendem aos filtros"
+// @Header 200 {integer} X-Page "Página retornada"
+// @Header 200 {integer} X-Limit "Itens por página"
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /interactions/content/{content_id} [get]
@@ -149,17 +305,326 @@ func (h *InteractionHandler) GetContentInteractions(c *gin.Context) {
 		return
 	}
 
-	interactions, err := h.service.GetContentInteractions(uint(contentID))
+	query, err := parseInteractionQuery(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	interactions, total, err := h.service.GetContentInteractions(uint(contentID), query)
+	if err != nil {
+		c.JSON(interactionQueryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	writeInteractionList(c, interactions, total, query)
+}
+
+// DTO de Response de uma interação sinalizada como abuso
+type FlaggedInteractionResponse struct {
+	InteractionResponse
+	FlagReason string `json:"flag_reason"`
+}
+
+type ListFlaggedInteractionsResponse struct {
+	Interactions []FlaggedInteractionResponse `json:"interactions"`
+	Total        int64                        `json:"total"`
+	Page         int                          `json:"page"`
+	Limit        int                          `json:"limit"`
+}
+
+// GetFlaggedInteractions godoc
+// @Summary Lista as interações sinalizadas como abuso
+// @Description Interações sinalizadas ficam gravadas, mas não entram nas estatísticas nem são repassadas ao motor de recomendação.
+// @Tags interactions
+// @Produce json
+// @Param reason query string false "Motivo" Enums(burst, rating_bomb, re_rating)
+// @Param from query string false "Início do período (RFC3339 ou YYYY-MM-DD)"
+// @Param to query string false "Fim do período: exclusivo em RFC3339; YYYY-MM-DD inclui o dia inteiro"
+// @Param types query string false "Tipos de interação separados por vírgula"
+// @Param page query int false "Página" default(1)
+// @Param limit query int false "Itens por página" default(50)
+// @Param preload query bool false "Inclui o conteúdo de cada interação" default(true)
+// @Success 200 {object} ListFlaggedInteractionsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /interactions/flagged [get]
+func (h *InteractionHandler) GetFlaggedInteractions(c *gin.Context) {
+	query, err := parseInteractionQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	interactions, total, err := h.service.GetFlaggedInteractions(c.Query("reason"), query)
+	if err != nil {
+		status := interactionQueryErrorStatus(err)
+		if strings.HasPrefix(err.Error(), "motivo inválido") {
+			status = http.StatusBadRequest
+		}
+		c.JSON(status, gin.H{"error": err.Error()})
 		return
 	}
 
+	responses := make([]FlaggedInteractionResponse, len(interactions))
+	for i := range interactions {
+		responses[i] = FlaggedInteractionResponse{InteractionResponse: newInteractionResponse(&interactions[i])}
+		if interactions[i].FlagReason != nil {
+			responses[i].FlagReason = *interactions[i].FlagReason
+		}
+	}
+	query.Normalize()
+
+	c.JSON(http.StatusOK, ListFlaggedInteractionsResponse{
+		Interactions: responses,
+		Total:        total,
+		Page:         query.Page,
+		Limit:        query.Limit,
+	})
+}
+
+// parseInteractionQuery lê os filtros e a paginação da query string
+func parseInteractionQuery(c *gin.Context) (service.InteractionQuery, error) {
+	var query service.InteractionQuery
+
+	query.Page, _ = strconv.Atoi(c.DefaultQuery("page", "1"))
+	query.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	from, err := parseTimeParam(c.Query("from"))
+	if err != nil {
+		return query, errors.New("from inválido, use RFC3339 ou YYYY-MM-DD")
+	}
+	query.From = from
+
+	to, err := parseEndTimeParam(c.Query("to"))
+	if err != nil {
+		return query, errors.New("to inválido, use RFC3339 ou YYYY-MM-DD")
+	}
+	query.To = to
+
+	if types := c.Query("types"); types != "" {
+		for _, t := range strings.Split(types, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				query.Types = append(query.Types, t)
+			}
+		}
+	}
+
+	if minRating := c.Query("min_rating"); minRating != "" {
+		value, err := strconv.ParseFloat(minRating, 64)
+		if err != nil {
+			return query, errors.New("min_rating inválido")
+		}
+		query.MinRating = &value
+	}
+
+	if preload := c.Query("preload"); preload != "" {
+		value, err := strconv.ParseBool(preload)
+		if err != nil {
+			return query, errors.New("preload inválido")
+		}
+		query.SkipPreload = !value
+	}
+
+	return query, nil
+}
+
+// parseTimeParam aceita datas em RFC3339 ou apenas o dia (YYYY-MM-DD, em UTC)
+func parseTimeParam(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+// parseEndTimeParam lê o fim exclusivo de um período. Um dia sem horário
+// (YYYY-MM-DD) inclui o dia inteiro, ou seja, termina no início do dia seguinte.
+func parseEndTimeParam(value string) (*time.Time, error) {
+	t, err := parseTimeParam(value)
+	if err != nil || t == nil {
+		return t, err
+	}
+	if _, err := time.Parse("2006-01-02", value); err == nil {
+		end := t.AddDate(0, 0, 1)
+		return &end, nil
+	}
+	return t, nil
+}
+
+// interactionQueryErrorStatus mapeia erros de validação da consulta para 400
+func interactionQueryErrorStatus(err error) int {
+	msg := err.Error()
+	switch {
+	case msg == "from deve ser anterior a to",
+		msg == "min_rating deve estar entre 1.0 e 5.0",
+		strings.HasPrefix(msg, "tipo de interação inválido"):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// writeInteractionList responde com o array de interações, no mesmo formato de
+// antes da paginação; total e paginação efetiva vão nos cabeçalhos
+func writeInteractionList(c *gin.Context, interactions []models.UserInteraction, total int64, query service.InteractionQuery) {
 	responses := make([]InteractionResponse, len(interactions))
 	for i := range interactions {
 		responses[i] = newInteractionResponse(&interactions[i])
 	}
 
+	// Reflete os valores efetivos de paginação aplicados pelo service
+	query.Normalize()
+
+	c.Header("X-Total-Count", strconv.FormatInt(total, 10))
+	c.Header("X-Page", strconv.Itoa(query.Page))
+	c.Header("X-Limit", strconv.Itoa(query.Limit))
 	c.JSON(http.StatusOK, responses)
 }
 
+// DTO de Request para alterar a reação atual
+type UpdateReactionRequest struct {
+	Reaction *string  `json:"reaction,omitempty" binding:"omitempty,oneof=like dislike"`
+	Rating   *float64 `json:"rating,omitempty" binding:"omitempty,min=1,max=5"`
+}
+
+// DTO de Response do estado atual da reação
+type ReactionResponse struct {
+	UserID    uint       `json:"user_id"`
+	ContentID uint       `json:"content_id"`
+	Reaction  *string    `json:"reaction"`
+	Rating    *float64   `json:"rating"`
+	ReactedAt *time.Time `json:"reacted_at,omitempty"`
+	RatedAt   *time.Time `json:"rated_at,omitempty"`
+}
+
+func newReactionResponse(reaction *models.UserReaction) ReactionResponse {
+	response := ReactionResponse{
+		UserID:    reaction.UserID,
+		ContentID: reaction.ContentID,
+		Reaction:  reaction.Reaction,
+		Rating:    reaction.Rating,
+	}
+	if !reaction.ReactedAt.IsZero() {
+		response.ReactedAt = &reaction.ReactedAt
+	}
+	if !reaction.RatedAt.IsZero() {
+		response.RatedAt = &reaction.RatedAt
+	}
+	return response
+}
+
+// parseReactionParams lê os IDs de usuário e conteúdo da rota
+func parseReactionParams(c *gin.Context) (uint, uint, bool) {
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de usuário inválido"})
+		return 0, 0, false
+	}
+	contentID, err := strconv.ParseUint(c.Param("content_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de conteúdo inválido"})
+		return 0, 0, false
+	}
+	return uint(userID), uint(contentID), true
+}
+
+// GetReaction godoc
+// @Summary Retorna a reação atual (like/dislike e nota) de um usuário a um conteúdo
+// @Tags interactions
+// @Produce json
+// @Param user_id path int true "ID do usuário"
+// @Param content_id path int true "ID do conteúdo"
+// @Success 200 {object} ReactionResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /interactions/reactions/{user_id}/{content_id} [get]
+func (h *InteractionHandler) GetReaction(c *gin.Context) {
+	userID, contentID, ok := parseReactionParams(c)
+	if !ok {
+		return
+	}
+
+	reaction, err := h.service.GetReaction(userID, contentID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newReactionResponse(reaction))
+}
+
+// UpdateReaction godoc
+// @Summary Troca a reação (like/dislike) e/ou a nota de um usuário a um conteúdo
+// @Tags interactions
+// @Accept json
+// @Produce json
+// @Param user_id path int true "ID do usuário"
+// @Param content_id path int true "ID do conteúdo"
+// @Param reaction body UpdateReactionRequest true "Nova reação e/ou nota"
+// @Success 200 {object} ReactionResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /interactions/reactions/{user_id}/{content_id} [put]
+func (h *InteractionHandler) UpdateReaction(c *gin.Context) {
+	userID, contentID, ok := parseReactionParams(c)
+	if !ok {
+		return
+	}
+
+	var req UpdateReactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	reaction, _, err := h.service.UpdateReaction(userID, contentID, req.Reaction, req.Rating)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) || errors.Is(err, service.ErrContentNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newReactionResponse(reaction))
+}
+
+// RetractReaction godoc
+// @Summary Desfaz uma reação (unlike, undislike ou remoção da nota)
+// @Tags interactions
+// @Produce json
+// @Param user_id path int true "ID do usuário"
+// @Param content_id path int true "ID do conteúdo"
+// @Param type query string false "Reação a desfazer; vazio desfaz todas" Enums(like, dislike, rating)
+// @Success 200 {object} ReactionResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /interactions/reactions/{user_id}/{content_id} [delete]
+func (h *InteractionHandler) RetractReaction(c *gin.Context) {
+	userID, contentID, ok := parseReactionParams(c)
+	if !ok {
+		return
+	}
+
+	reaction, _, err := h.service.RetractReaction(userID, contentID, c.Query("type"))
+	if err != nil {
+		if errors.Is(err, service.ErrNoReaction) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newReactionResponse(reaction))
+}
@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"net/http"
+
+	"backend-go/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ItemCFHandler struct {
+	service service.ItemCFService
+}
+
+func NewItemCFHandler(service service.ItemCFService) *ItemCFHandler {
+	return &ItemCFHandler{service: service}
+}
+
+// RegisterRoutes registra as rotas administrativas do modelo item-item
+func (h *ItemCFHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("", h.GetStats)
+	rg.POST("/rebuild", h.Rebuild)
+}
+
+// GetStats godoc
+// @Summary Tamanho e atualização do modelo item-item em memória (method=item_cf)
+// @Tags admin
+// @Produce json
+// @Success 200 {object} service.ItemCFStats
+// @Router /admin/item-cf [get]
+func (h *ItemCFHandler) GetStats(c *gin.Context) {
+	c.JSON(http.StatusOK, h.service.Stats())
+}
+
+// Rebuild godoc
+// @Summary Recalcula o modelo item-item a partir de todo o log de interações
+// @Tags admin
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 500 {object} map[string]string
+// @Router /admin/item-cf/rebuild [post]
+func (h *ItemCFHandler) Rebuild(c *gin.Context) {
+	read, err := h.service.Rebuild()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"interactions": read,
+		"stats":        h.service.Stats(),
+	})
+}
@@ -0,0 +1,275 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"backend-go/models"
+	"backend-go/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ModerationHandler struct {
+	service service.ModerationService
+}
+
+func NewModerationHandler(service service.ModerationService) *ModerationHandler {
+	return &ModerationHandler{service: service}
+}
+
+// RegisterContentRoutes registra a rota de denúncia dentro do grupo de conteúdos
+func (h *ModerationHandler) RegisterContentRoutes(rg *gin.RouterGroup) {
+	rg.POST("/:id/reports", h.ReportContent)
+}
+
+// RegisterRoutes registra as rotas da fila de moderação
+func (h *ModerationHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("/reports", h.ListReports)
+	rg.POST("/reports/:id/dismiss", h.DismissReport)
+	rg.POST("/reports/:id/action", h.ActionReport)
+	rg.GET("/contents/:content_id/decisions", h.GetContentDecisions)
+}
+
+// DTOs de Request
+type CreateReportRequest struct {
+	UserID  uint   `json:"user_id" binding:"required"`
+	Reason  string `json:"reason" binding:"required,oneof=outdated wrong inappropriate"`
+	Details string `json:"details" binding:"max=1000"`
+}
+
+type ResolveReportRequest struct {
+	ModeratorID uint   `json:"moderator_id" binding:"required"`
+	Note        string `json:"note" binding:"max=1000"`
+}
+
+// DTOs de Response
+type ReportResponse struct {
+	ID         uint       `json:"id"`
+	ContentID  uint       `json:"content_id"`
+	UserID     uint       `json:"user_id"`
+	Reason     string     `json:"reason"`
+	Details    string     `json:"details"`
+	Status     string     `json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+type ListReportsResponse struct {
+	Reports []ReportResponse `json:"reports"`
+	Total   int64            `json:"total"`
+	Page    int              `json:"page"`
+	Limit   int              `json:"limit"`
+}
+
+type ModerationDecisionResponse struct {
+	ID          uint      `json:"id"`
+	ContentID   uint      `json:"content_id"`
+	ReportID    *uint     `json:"report_id,omitempty"`
+	ModeratorID *uint     `json:"moderator_id,omitempty"`
+	Action      string    `json:"action"`
+	Note        string    `json:"note"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func newReportResponse(r *models.ContentReport) ReportResponse {
+	return ReportResponse{
+		ID:         r.ID,
+		ContentID:  r.ContentID,
+		UserID:     r.UserID,
+		Reason:     r.Reason,
+		Details:    r.Details,
+		Status:     r.Status,
+		CreatedAt:  r.CreatedAt,
+		ResolvedAt: r.ResolvedAt,
+	}
+}
+
+func newModerationDecisionResponse(d *models.ModerationDecision) ModerationDecisionResponse {
+	return ModerationDecisionResponse{
+		ID:          d.ID,
+		ContentID:   d.ContentID,
+		ReportID:    d.ReportID,
+		ModeratorID: d.ModeratorID,
+		Action:      d.Action,
+		Note:        d.Note,
+		CreatedAt:   d.CreatedAt,
+	}
+}
+
+// moderationErrorStatus traduz erros do serviço de moderação em status HTTP
+func moderationErrorStatus(err error) int {
+	switch err.Error() {
+	case "conteúdo não encontrado", "denúncia não encontrada", "usuário não encontrado":
+		return http.StatusNotFound
+	case "usuário sem permissão de moderação":
+		return http.StatusForbidden
+	case "denúncia já foi revisada", "usuário já denunciou este conteúdo":
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// ReportContent godoc
+// @Summary Denuncia um conteúdo desatualizado, errado ou inapropriado
+// @Description Cada usuário denuncia um conteúdo uma única vez. O conteúdo é ocultado quando usuários distintos em número suficiente têm denúncias em aberto.
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param id path int true "ID do conteúdo"
+// @Param report body CreateReportRequest true "Dados da denúncia"
+// @Success 201 {object} ReportResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /contents/{id}/reports [post]
+func (h *ModerationHandler) ReportContent(c *gin.Context) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var req CreateReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.service.ReportContent(uint(id), req.UserID, req.Reason, req.Details)
+	if err != nil {
+		c.JSON(moderationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, newReportResponse(report))
+}
+
+// ListReports godoc
+// @Summary Lista a fila de moderação
+// @Tags moderation
+// @Produce json
+// @Param status query string false "Filtro por status (open, dismissed, actioned)"
+// @Param page query int false "Número da página" default(1)
+// @Param limit query int false "Itens por página" default(20)
+// @Success 200 {object} ListReportsResponse
+// @Failure 500 {object} map[string]string
+// @Router /moderation/reports [get]
+func (h *ModerationHandler) ListReports(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	var status *string
+	if statusParam := c.Query("status"); statusParam != "" {
+		status = &statusParam
+	}
+
+	reports, total, err := h.service.ListReports(status, page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	responses := make([]ReportResponse, len(reports))
+	for i := range reports {
+		responses[i] = newReportResponse(&reports[i])
+	}
+
+	c.JSON(http.StatusOK, ListReportsResponse{
+		Reports: responses,
+		Total:   total,
+		Page:    page,
+		Limit:   limit,
+	})
+}
+
+// DismissReport godoc
+// @Summary Descarta uma denúncia
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param id path int true "ID da denúncia"
+// @Param decision body ResolveReportRequest true "Moderador e nota"
+// @Success 200 {object} ReportResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /moderation/reports/{id}/dismiss [post]
+func (h *ModerationHandler) DismissReport(c *gin.Context) {
+	h.resolveReport(c, h.service.DismissReport)
+}
+
+// ActionReport godoc
+// @Summary Acata uma denúncia e mantém o conteúdo oculto
+// @Tags moderation
+// @Accept json
+// @Produce json
+// @Param id path int true "ID da denúncia"
+// @Param decision body ResolveReportRequest true "Moderador e nota"
+// @Success 200 {object} ReportResponse
+// @Failure 400 {object} map[string]string
+// @Failure 403 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Router /moderation/reports/{id}/action [post]
+func (h *ModerationHandler) ActionReport(c *gin.Context) {
+	h.resolveReport(c, h.service.ActionReport)
+}
+
+func (h *ModerationHandler) resolveReport(c *gin.Context, resolve func(reportID, moderatorID uint, note string) (*models.ContentReport, error)) {
+	idParam := c.Param("id")
+	id, err := strconv.ParseUint(idParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var req ResolveReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := resolve(uint(id), req.ModeratorID, req.Note)
+	if err != nil {
+		c.JSON(moderationErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newReportResponse(report))
+}
+
+// GetContentDecisions godoc
+// @Summary Lista o histórico de decisões de moderação de um conteúdo
+// @Tags moderation
+// @Produce json
+// @Param content_id path int true "ID do conteúdo"
+// @Success 200 {array} ModerationDecisionResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /moderation/contents/{content_id}/decisions [get]
+func (h *ModerationHandler) GetContentDecisions(c *gin.Context) {
+	contentIDParam := c.Param("content_id")
+	contentID, err := strconv.ParseUint(contentIDParam, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de conteúdo inválido"})
+		return
+	}
+
+	decisions, err := h.service.GetContentDecisions(uint(contentID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	responses := make([]ModerationDecisionResponse, len(decisions))
+	for i := range decisions {
+		responses[i] = newModerationDecisionResponse(&decisions[i])
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"backend-go/models"
+	"backend-go/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OutboxHandler struct {
+	service service.OutboxService
+}
+
+func NewOutboxHandler(service service.OutboxService) *OutboxHandler {
+	return &OutboxHandler{service: service}
+}
+
+// RegisterRoutes registra as rotas administrativas do outbox
+func (h *OutboxHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("", h.GetStatus)
+	rg.GET("/events", h.ListEvents)
+	rg.POST("/events/:id/retry", h.RetryEvent)
+}
+
+// DTOs de Response
+type ListOutboxEventsResponse struct {
+	Events []models.OutboxEvent `json:"events"`
+	Total  int64                `json:"total"`
+	Page   int                  `json:"page"`
+	Limit  int                  `json:"limit"`
+}
+
+// GetStatus godoc
+// @Summary Estado do outbox de notificações (pendentes, dead e atraso)
+// @Tags admin
+// @Produce json
+// @Success 200 {object} service.OutboxStatus
+// @Failure 500 {object} map[string]string
+// @Router /admin/outbox [get]
+func (h *OutboxHandler) GetStatus(c *gin.Context) {
+	status, err := h.service.Status()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// ListEvents godoc
+// @Summary Lista os eventos do outbox
+// @Tags admin
+// @Produce json
+// @Param status query string false "Status do evento" Enums(pending, delivered, dead)
+// @Param page query int false "Número da página" default(1)
+// @Param limit query int false "Itens por página" default(50)
+// @Success 200 {object} ListOutboxEventsResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/outbox/events [get]
+func (h *OutboxHandler) ListEvents(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	events, total, err := h.service.ListEvents(c.Query("status"), page, limit)
+	if err != nil {
+		c.JSON(outboxErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, ListOutboxEventsResponse{
+		Events: events,
+		Total:  total,
+		Page:   page,
+		Limit:  limit,
+	})
+}
+
+// RetryEvent godoc
+// @Summary Reenfileira um evento dead do outbox
+// @Tags admin
+// @Produce json
+// @Param id path int true "ID do evento"
+// @Success 200 {object} models.OutboxEvent
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/outbox/events/{id}/retry [post]
+func (h *OutboxHandler) RetryEvent(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	event, err := h.service.Retry(uint(id))
+	if err != nil {
+		c.JSON(outboxErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, event)
+}
+
+// outboxErrorStatus traduz erros do serviço de outbox em status HTTP
+func outboxErrorStatus(err error) int {
+	switch {
+	case strings.HasSuffix(err.Error(), "não encontrado"):
+		return http.StatusNotFound
+	case strings.HasPrefix(err.Error(), "status inválido"):
+		return http.StatusBadRequest
+	case strings.HasPrefix(err.Error(), "apenas eventos"):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
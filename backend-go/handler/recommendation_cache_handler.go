@@ -0,0 +1,58 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"backend-go/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RecommendationCacheHandler struct {
+	cache service.RecommendationCache
+}
+
+func NewRecommendationCacheHandler(cache service.RecommendationCache) *RecommendationCacheHandler {
+	return &RecommendationCacheHandler{cache: cache}
+}
+
+// RegisterRoutes registra as rotas administrativas do cache de recomendações
+func (h *RecommendationCacheHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("", h.GetStats)
+	rg.DELETE("/users/:user_id", h.InvalidateUser)
+}
+
+// GetStats godoc
+// @Summary Métricas do cache de recomendações
+// @Description hit_rate considera as listas servidas do cache, frescas (hits) ou em revalidação (stale_hits), sobre o total de consultas.
+// @Tags admin
+// @Produce json
+// @Success 200 {object} service.RecommendationCacheStats
+// @Router /admin/recommendation-cache [get]
+func (h *RecommendationCacheHandler) GetStats(c *gin.Context) {
+	c.JSON(http.StatusOK, h.cache.Stats())
+}
+
+// InvalidateUser godoc
+// @Summary Descarta as recomendações em cache de um usuário
+// @Tags admin
+// @Produce json
+// @Param user_id path int true "ID do usuário"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/recommendation-cache/users/{user_id} [delete]
+func (h *RecommendationCacheHandler) InvalidateUser(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de usuário inválido"})
+		return
+	}
+
+	if err := h.cache.Invalidate(uint(userID)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "recomendações do usuário descartadas do cache"})
+}
@@ -1,47 +1,210 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"backend-go/models"
 	"backend-go/service"
 
 	"github.com/gin-gonic/gin"
 )
 
 type RecommendationHandler struct {
-	service service.RecommendationService
+	service         service.RecommendationService
+	historyService  service.RecommendationHistoryService
+	contentService  service.ContentService
+	bookmarkService service.BookmarkService
 }
 
-func NewRecommendationHandler(service service.RecommendationService) *RecommendationHandler {
-	return &RecommendationHandler{service: service}
+func NewRecommendationHandler(
+	service service.RecommendationService,
+	historyService service.RecommendationHistoryService,
+	contentService service.ContentService,
+	bookmarkService service.BookmarkService,
+) *RecommendationHandler {
+	return &RecommendationHandler{
+		service:         service,
+		historyService:  historyService,
+		contentService:  contentService,
+		bookmarkService: bookmarkService,
+	}
 }
 
 func (h *RecommendationHandler) RegisterRoutes(rg *gin.RouterGroup) {
 	rg.GET("/user/:user_id", h.GetRecommendations)
+	rg.GET("/user/:user_id/history", h.GetRecommendationHistory)
+}
+
+// RegisterAdminRoutes registra a consulta administrativa de recomendações servidas
+func (h *RecommendationHandler) RegisterAdminRoutes(rg *gin.RouterGroup) {
+	rg.GET("/:id", h.GetRecommendation)
 }
 
 // DTO de Request
 type GetRecommendationsRequest struct {
 	TopN   int    `form:"top_n" binding:"omitempty,min=1,max=50"`
-	Method string `form:"method" binding:"omitempty,oneof=similarity popularity"`
+	Method string `form:"method" binding:"omitempty,oneof=similarity popularity editorial item_cf"`
+	// Contexto da requisição, gravado no histórico
+	SessionID  *string `form:"session_id" binding:"omitempty,max=64"`
+	Device     *string `form:"device" binding:"omitempty,max=32"`
+	AppVersion *string `form:"app_version" binding:"omitempty,max=32"`
+	Surface    *string `form:"surface" binding:"omitempty,oneof=home search recs collection"`
+	// Campos de cada item, separados por vírgula; vazio retorna todos
+	Fields string `form:"fields"`
+	// Filtros de negócio; listas separadas por vírgula
+	Types           string `form:"types"`
+	CategoryIDs     string `form:"category_ids"`
+	ExcludeIDs      string `form:"exclude_ids"`
+	ExcludeViewed   bool   `form:"exclude_viewed"`
+	ExcludeDisliked bool   `form:"exclude_disliked"`
+	Available       bool   `form:"available"`
+}
+
+// filter monta os filtros de negócio da requisição
+func (req GetRecommendationsRequest) filter() (service.RecommendationFilter, error) {
+	filter := service.RecommendationFilter{
+		ExcludeViewed:   req.ExcludeViewed,
+		ExcludeDisliked: req.ExcludeDisliked,
+		AvailableOnly:   req.Available,
+	}
+	for _, t := range strings.Split(req.Types, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			filter.Types = append(filter.Types, t)
+		}
+	}
+
+	var err error
+	if filter.CategoryIDs, err = parseIDList(req.CategoryIDs, "category_ids"); err != nil {
+		return filter, err
+	}
+	if filter.ExcludeIDs, err = parseIDList(req.ExcludeIDs, "exclude_ids"); err != nil {
+		return filter, err
+	}
+	return filter, filter.Validate()
+}
+
+// parseIDList lê uma lista de IDs separados por vírgula
+func parseIDList(raw, param string) ([]uint, error) {
+	var ids []uint
+	for _, value := range strings.Split(raw, ",") {
+		if value = strings.TrimSpace(value); value == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil || id == 0 {
+			return nil, errors.New("ID inválido em " + param + ": " + value)
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids, nil
 }
 
 // DTO de Response
 type RecommendationResponse struct {
-	UserID         uint   `json:"user_id"`
-	ContentIDs     []uint `json:"content_ids"`
-	Method         string `json:"method"`
-	Count          int    `json:"count"`
+	RecommendationID     uint   `json:"recommendation_id,omitempty"`
+	UserID               uint   `json:"user_id"`
+	ContentIDs           []uint `json:"content_ids"`
+	Method               string `json:"method"`
+	RequestedMethod      string `json:"requested_method"`
+	Cache                string `json:"cache" enums:"hit,stale,miss,bypass"`
+	Count                int    `json:"count"`
+	BookmarkedContentIDs []uint `json:"bookmarked_content_ids"`
+	// []RecommendedContentResponse, reduzido aos campos pedidos em fields
+	Items []interface{} `json:"items" swaggertype:"array,object"`
+}
+
+// RecommendedContentResponse é um conteúdo recomendado com a pontuação do
+// método que o recomendou. Conteúdos que completaram a lista pelas coleções
+// editoriais não têm pontuação.
+type RecommendedContentResponse struct {
+	ContentResponse
+	Score *float64 `json:"score,omitempty"`
+}
+
+// Campos de RecommendedContentResponse que podem ser pedidos em fields
+var recommendedContentFields = map[string]bool{
+	"id":           true,
+	"title":        true,
+	"description":  true,
+	"type":         true,
+	"release_date": true,
+	"hidden":       true,
+	"created_at":   true,
+	"categories":   true,
+	"bookmarked":   true,
+	"score":        true,
+}
+
+// parseRecommendedContentFields valida a lista de campos pedida; nil pede
+// todos. O id é sempre incluído.
+func parseRecommendedContentFields(raw string) (map[string]bool, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	fields := map[string]bool{"id": true}
+	for _, field := range strings.Split(raw, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if !recommendedContentFields[field] {
+			return nil, errors.New("campo inválido em fields: " + field)
+		}
+		fields[field] = true
+	}
+	return fields, nil
+}
+
+// selectFields reduz o item aos campos pedidos
+func selectFields(item RecommendedContentResponse, fields map[string]bool) (interface{}, error) {
+	if fields == nil {
+		return item, nil
+	}
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	selected := make(map[string]json.RawMessage, len(fields))
+	for field := range fields {
+		if value, ok := all[field]; ok {
+			selected[field] = value
+		}
+	}
+	return selected, nil
 }
 
 // GetRecommendations godoc
 // @Summary Obtém recomendações para um usuário
+// @Description similarity e popularity são calculados pelo motor Python; editorial e item_cf (filtragem colaborativa item-item) são resolvidos no próprio backend.
+// @Description Se o motor Python falhar ou não responder a tempo, a resposta vem do ranking de popularidade e recência do backend, sem conteúdos já consumidos pelo usuário: method informa o método que de fato serviu (popularity_fallback) e requested_method o pedido.
+// @Description As listas ficam em cache por usuário, método e top_n e são descartadas quando o usuário registra uma interação; cache informa se a lista veio do cache (hit), do cache em revalidação (stale) ou foi calculada (miss). Listas do fallback não são guardadas.
+// @Description Os filtros de negócio (types, category_ids, exclude_viewed, exclude_disliked, exclude_ids, available) são aplicados depois da recuperação: com filtros, a lista é recuperada com mais candidatos para ainda entregar top_n conteúdos, e as coleções editoriais filtradas completam o que faltar.
+// @Description items traz os conteúdos recomendados, na ordem da recomendação, com categorias e a pontuação (score) do método; use fields para receber só alguns campos.
 // @Tags recommendations
 // @Produce json
 // @Param user_id path int true "ID do usuário"
 // @Param top_n query int false "Número de recomendações" default(10) minimum(1) maximum(50)
-// @Param method query string false "Método de recomendação" Enums(similarity, popularity) default(similarity)
+// @Param method query string false "Método de recomendação" Enums(similarity, popularity, editorial, item_cf) default(similarity)
+// @Param session_id query string false "Sessão do aplicativo"
+// @Param device query string false "Dispositivo"
+// @Param app_version query string false "Versão do aplicativo"
+// @Param surface query string false "Superfície onde a lista será exibida" Enums(home, search, recs, collection)
+// @Param fields query string false "Campos de cada item em items, separados por vírgula (id, title, description, type, release_date, hidden, created_at, categories, bookmarked, score); vazio retorna todos"
+// @Param types query string false "Tipos de conteúdo aceitos, separados por vírgula (article, video, podcast, book, course)"
+// @Param category_ids query string false "IDs de categorias aceitas, separados por vírgula"
+// @Param exclude_viewed query bool false "Exclui conteúdos já visualizados pelo usuário"
+// @Param exclude_disliked query bool false "Exclui conteúdos com dislike do usuário"
+// @Param exclude_ids query string false "IDs de conteúdos a excluir, separados por vírgula"
+// @Param available query bool false "Só conteúdos já lançados"
 // @Success 200 {object} RecommendationResponse
 // @Failure 400 {object} map[string]string
 // @Failure 500 {object} map[string]string
@@ -59,6 +222,16 @@ func (h *RecommendationHandler) GetRecommendations(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	fields, err := parseRecommendedContentFields(req.Fields)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	filter, err := req.filter()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
 	// Valores padrão
 	topN := req.TopN
@@ -70,7 +243,12 @@ func (h *RecommendationHandler) GetRecommendations(c *gin.Context) {
 		method = "similarity"
 	}
 
-	contentIDs, err := h.service.GetRecommendations(uint(userID), topN, method)
+	result, err := h.service.GetRecommendations(uint(userID), topN, method, filter, service.RecommendationContext{
+		SessionID:  req.SessionID,
+		Device:     req.Device,
+		AppVersion: req.AppVersion,
+		Surface:    req.Surface,
+	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error":   "Erro ao obter recomendações",
@@ -79,13 +257,194 @@ func (h *RecommendationHandler) GetRecommendations(c *gin.Context) {
 		return
 	}
 
+	contentIDs := result.ContentIDs
+
+	// Sinaliza quais recomendações o usuário já salvou
+	bookmarked, err := h.bookmarkService.BookmarkedSet(uint(userID), contentIDs)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	bookmarkedIDs := make([]uint, 0, len(bookmarked))
+	for _, id := range contentIDs {
+		if bookmarked[id] {
+			bookmarkedIDs = append(bookmarkedIDs, id)
+		}
+	}
+
+	// Carrega os conteúdos em uma consulta; categorias só se forem exibidas
+	contents, err := h.contentService.GetContentsByIDs(contentIDs, fields == nil || fields["categories"])
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	items := make([]interface{}, 0, len(contents))
+	for i := range contents {
+		item := RecommendedContentResponse{ContentResponse: newContentResponse(&contents[i])}
+		item.Bookmarked = bookmarked[contents[i].ID]
+		if score, ok := result.Scores[contents[i].ID]; ok {
+			item.Score = &score
+		}
+		selected, err := selectFields(item, fields)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		items = append(items, selected)
+	}
+
 	response := RecommendationResponse{
-		UserID:     uint(userID),
-		ContentIDs: contentIDs,
-		Method:     method,
-		Count:      len(contentIDs),
+		RecommendationID:     result.ID,
+		UserID:               uint(userID),
+		ContentIDs:           contentIDs,
+		Method:               result.Method,
+		RequestedMethod:      method,
+		Cache:                result.Cache,
+		Count:                len(contentIDs),
+		BookmarkedContentIDs: bookmarkedIDs,
+		Items:                items,
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
+// DTO de Response de uma recomendação servida
+type RecommendationHistoryResponse struct {
+	ID              uint               `json:"id"`
+	UserID          uint               `json:"user_id"`
+	ContentIDs      []uint             `json:"content_ids"`
+	Scores          map[string]float64 `json:"scores"`
+	Method          string             `json:"method"`
+	RequestedMethod string             `json:"requested_method"`
+	ModelVersion    string             `json:"model_version"`
+	SessionID       *string            `json:"session_id,omitempty"`
+	Device          *string            `json:"device,omitempty"`
+	AppVersion      *string            `json:"app_version,omitempty"`
+	Surface         *string            `json:"surface,omitempty"`
+	CreatedAt       time.Time          `json:"created_at"`
+}
+
+type ListRecommendationHistoryResponse struct {
+	Recommendations []RecommendationHistoryResponse `json:"recommendations"`
+	Total           int64                           `json:"total"`
+	Page            int                             `json:"page"`
+	Limit           int                             `json:"limit"`
+}
+
+func newRecommendationHistoryResponse(recommendation *models.Recommendation) RecommendationHistoryResponse {
+	response := RecommendationHistoryResponse{
+		ID:              recommendation.ID,
+		UserID:          recommendation.UserID,
+		ContentIDs:      []uint{},
+		Scores:          map[string]float64{},
+		Method:          recommendation.Method,
+		RequestedMethod: recommendation.RequestedMethod,
+		ModelVersion:    recommendation.ModelVersion,
+		SessionID:       recommendation.SessionID,
+		Device:          recommendation.Device,
+		AppVersion:      recommendation.AppVersion,
+		Surface:         recommendation.Surface,
+		CreatedAt:       recommendation.CreatedAt,
+	}
+	// Registros gravados antes do histórico completo podem não ter pontuações
+	if len(recommendation.RecommendedContentIDs) > 0 {
+		_ = json.Unmarshal(recommendation.RecommendedContentIDs, &response.ContentIDs)
+	}
+	if len(recommendation.Scores) > 0 {
+		_ = json.Unmarshal(recommendation.Scores, &response.Scores)
+	}
+	return response
+}
+
+// GetRecommendationHistory godoc
+// @Summary Lista as recomendações servidas a um usuário
+// @Description Cada item é uma lista servida por GET /recommendations/user/{user_id}, da mais recente para a mais antiga, com o método que de fato a gerou, a versão do modelo, as pontuações por conteúdo (os que vieram das coleções editoriais não têm) e o contexto da requisição.
+// @Tags recommendations
+// @Produce json
+// @Param user_id path int true "ID do usuário"
+// @Param from query string false "Início do período (RFC3339 ou YYYY-MM-DD)"
+// @Param to query string false "Fim do período: exclusivo em RFC3339; YYYY-MM-DD inclui o dia inteiro"
+// @Param page query int false "Página" default(1)
+// @Param limit query int false "Itens por página" default(20) maximum(100)
+// @Success 200 {object} ListRecommendationHistoryResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /recommendations/user/{user_id}/history [get]
+func (h *RecommendationHandler) GetRecommendationHistory(c *gin.Context) {
+	userID, err := strconv.ParseUint(c.Param("user_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de usuário inválido"})
+		return
+	}
+
+	var query service.RecommendationHistoryQuery
+	query.Page, _ = strconv.Atoi(c.DefaultQuery("page", "1"))
+	query.Limit, _ = strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if query.From, err = parseTimeParam(c.Query("from")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from inválido, use RFC3339 ou YYYY-MM-DD"})
+		return
+	}
+	if query.To, err = parseEndTimeParam(c.Query("to")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "to inválido, use RFC3339 ou YYYY-MM-DD"})
+		return
+	}
+
+	recommendations, total, err := h.historyService.ListByUser(uint(userID), query)
+	if err != nil {
+		c.JSON(recommendationHistoryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	responses := make([]RecommendationHistoryResponse, len(recommendations))
+	for i := range recommendations {
+		responses[i] = newRecommendationHistoryResponse(&recommendations[i])
+	}
+	query.Normalize()
+
+	c.JSON(http.StatusOK, ListRecommendationHistoryResponse{
+		Recommendations: responses,
+		Total:           total,
+		Page:            query.Page,
+		Limit:           query.Limit,
+	})
+}
+
+// GetRecommendation godoc
+// @Summary Busca uma recomendação servida pelo ID
+// @Tags admin
+// @Produce json
+// @Param id path int true "ID da recomendação (recommendation_id da resposta de recomendações)"
+// @Success 200 {object} RecommendationHistoryResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /admin/recommendations/{id} [get]
+func (h *RecommendationHandler) GetRecommendation(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID de recomendação inválido"})
+		return
+	}
+
+	recommendation, err := h.historyService.Get(uint(id))
+	if err != nil {
+		c.JSON(recommendationHistoryErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newRecommendationHistoryResponse(recommendation))
+}
+
+// recommendationHistoryErrorStatus traduz erros do histórico de recomendações em status HTTP
+func recommendationHistoryErrorStatus(err error) int {
+	msg := err.Error()
+	switch {
+	case errors.Is(err, service.ErrUserNotFound), strings.HasSuffix(msg, "não encontrada"):
+		return http.StatusNotFound
+	case msg == "ID inválido", msg == "from deve ser anterior a to":
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
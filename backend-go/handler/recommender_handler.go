@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"net/http"
+
+	"backend-go/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RecommenderHandler struct {
+	client service.RecommenderClient
+}
+
+func NewRecommenderHandler(client service.RecommenderClient) *RecommenderHandler {
+	return &RecommenderHandler{client: client}
+}
+
+// RegisterRoutes registra as rotas administrativas do cliente do motor Python
+func (h *RecommenderHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.GET("", h.GetStats)
+}
+
+// GetStats godoc
+// @Summary Métricas do cliente do motor de recomendação
+// @Description Estado do circuit breaker (closed, open, half_open), retentativas, requisições de hedge, chamadas recusadas com o circuito aberto e latência por operação (métodos de recomendação e entrega de interações).
+// @Tags admin
+// @Produce json
+// @Success 200 {object} service.RecommenderClientStats
+// @Router /admin/recommender [get]
+func (h *RecommenderHandler) GetStats(c *gin.Context) {
+	c.JSON(http.StatusOK, h.client.Stats())
+}
@@ -0,0 +1,328 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"backend-go/models"
+	"backend-go/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookHandler struct {
+	service service.WebhookService
+}
+
+func NewWebhookHandler(service service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{service: service}
+}
+
+// RegisterRoutes registra as rotas de assinaturas e entregas de webhooks
+func (h *WebhookHandler) RegisterRoutes(rg *gin.RouterGroup) {
+	rg.POST("", h.CreateWebhook)
+	rg.GET("", h.ListWebhooks)
+	rg.GET("/:id", h.GetWebhook)
+	rg.PUT("/:id", h.UpdateWebhook)
+	rg.DELETE("/:id", h.DeleteWebhook)
+	rg.GET("/:id/deliveries", h.ListDeliveries)
+	rg.GET("/deliveries/:delivery_id", h.GetDelivery)
+	rg.POST("/deliveries/:delivery_id/redeliver", h.Redeliver)
+}
+
+// DTOs de Response
+type WebhookResponse struct {
+	ID          uint      `json:"id"`
+	URL         string    `json:"url"`
+	EventTypes  []string  `json:"event_types"`
+	Description string    `json:"description,omitempty"`
+	Active      bool      `json:"active"`
+	Secret      string    `json:"secret,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type ListWebhooksResponse struct {
+	Webhooks []WebhookResponse `json:"webhooks"`
+	Total    int64             `json:"total"`
+	Page     int               `json:"page"`
+	Limit    int               `json:"limit"`
+}
+
+type ListWebhookDeliveriesResponse struct {
+	Deliveries []models.WebhookDelivery `json:"deliveries"`
+	Total      int64                    `json:"total"`
+	Page       int                      `json:"page"`
+	Limit      int                      `json:"limit"`
+}
+
+// newWebhookResponse monta a resposta sem o secret, que só é exibido na criação
+func newWebhookResponse(subscription *models.WebhookSubscription) WebhookResponse {
+	return WebhookResponse{
+		ID:          subscription.ID,
+		URL:         subscription.URL,
+		EventTypes:  strings.Split(subscription.EventTypes, ","),
+		Description: subscription.Description,
+		Active:      subscription.Active,
+		CreatedAt:   subscription.CreatedAt,
+		UpdatedAt:   subscription.UpdatedAt,
+	}
+}
+
+// CreateWebhook godoc
+// @Summary Cria uma assinatura de webhook
+// @Description Tipos de evento: content.published, content.updated, content.deleted, interaction.created ou "*" para todos.
+// @Description Cada entrega é assinada com HMAC-SHA256 de "<X-Webhook-Timestamp>.<corpo>" usando o secret, enviado em X-Webhook-Signature (sha256=<hex>).
+// @Description O secret é gerado se não for informado e só é retornado nesta resposta.
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param webhook body service.CreateWebhookRequest true "Dados da assinatura"
+// @Success 201 {object} WebhookResponse
+// @Failure 400 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req service.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	subscription, err := h.service.CreateSubscription(req)
+	if err != nil {
+		c.JSON(webhookErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	response := newWebhookResponse(subscription)
+	response.Secret = subscription.Secret
+	c.JSON(http.StatusCreated, response)
+}
+
+// ListWebhooks godoc
+// @Summary Lista as assinaturas de webhook
+// @Tags webhooks
+// @Produce json
+// @Param page query int false "Número da página" default(1)
+// @Param limit query int false "Itens por página" default(20)
+// @Success 200 {object} ListWebhooksResponse
+// @Failure 500 {object} map[string]string
+// @Router /webhooks [get]
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	subscriptions, total, err := h.service.ListSubscriptions(page, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	webhooks := make([]WebhookResponse, len(subscriptions))
+	for i := range subscriptions {
+		webhooks[i] = newWebhookResponse(&subscriptions[i])
+	}
+	c.JSON(http.StatusOK, ListWebhooksResponse{
+		Webhooks: webhooks,
+		Total:    total,
+		Page:     page,
+		Limit:    limit,
+	})
+}
+
+// GetWebhook godoc
+// @Summary Busca uma assinatura de webhook
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "ID da assinatura"
+// @Success 200 {object} WebhookResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /webhooks/{id} [get]
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	id, ok := parseWebhookID(c, "id")
+	if !ok {
+		return
+	}
+
+	subscription, err := h.service.GetSubscription(id)
+	if err != nil {
+		c.JSON(webhookErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, newWebhookResponse(subscription))
+}
+
+// UpdateWebhook godoc
+// @Summary Atualiza uma assinatura de webhook (URL, eventos, descrição ou ativa)
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param id path int true "ID da assinatura"
+// @Param webhook body service.UpdateWebhookRequest true "Campos a atualizar"
+// @Success 200 {object} WebhookResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /webhooks/{id} [put]
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	id, ok := parseWebhookID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req service.UpdateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	subscription, err := h.service.UpdateSubscription(id, req)
+	if err != nil {
+		c.JSON(webhookErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, newWebhookResponse(subscription))
+}
+
+// DeleteWebhook godoc
+// @Summary Remove uma assinatura de webhook e seu log de entregas
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "ID da assinatura"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	id, ok := parseWebhookID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteSubscription(id); err != nil {
+		c.JSON(webhookErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "assinatura removida"})
+}
+
+// ListDeliveries godoc
+// @Summary Log de entregas de uma assinatura de webhook
+// @Tags webhooks
+// @Produce json
+// @Param id path int true "ID da assinatura"
+// @Param status query string false "Status da entrega" Enums(pending, delivered, failed)
+// @Param page query int false "Número da página" default(1)
+// @Param limit query int false "Itens por página" default(20)
+// @Success 200 {object} ListWebhookDeliveriesResponse
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListDeliveries(c *gin.Context) {
+	id, ok := parseWebhookID(c, "id")
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	deliveries, total, err := h.service.ListDeliveries(id, c.Query("status"), page, limit)
+	if err != nil {
+		c.JSON(webhookErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, ListWebhookDeliveriesResponse{
+		Deliveries: deliveries,
+		Total:      total,
+		Page:       page,
+		Limit:      limit,
+	})
+}
+
+// GetDelivery godoc
+// @Summary Busca uma entrega de webhook
+// @Tags webhooks
+// @Produce json
+// @Param delivery_id path int true "ID da entrega"
+// @Success 200 {object} models.WebhookDelivery
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /webhooks/deliveries/{delivery_id} [get]
+func (h *WebhookHandler) GetDelivery(c *gin.Context) {
+	id, ok := parseWebhookID(c, "delivery_id")
+	if !ok {
+		return
+	}
+
+	delivery, err := h.service.GetDelivery(id)
+	if err != nil {
+		c.JSON(webhookErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, delivery)
+}
+
+// Redeliver godoc
+// @Summary Reenvia o evento de uma entrega de webhook
+// @Description Cria uma nova entrega do mesmo evento (mesmo id no corpo), mantendo a original no log.
+// @Tags webhooks
+// @Produce json
+// @Param delivery_id path int true "ID da entrega"
+// @Success 202 {object} models.WebhookDelivery
+// @Failure 400 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 409 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /webhooks/deliveries/{delivery_id}/redeliver [post]
+func (h *WebhookHandler) Redeliver(c *gin.Context) {
+	id, ok := parseWebhookID(c, "delivery_id")
+	if !ok {
+		return
+	}
+
+	delivery, err := h.service.Redeliver(id)
+	if err != nil {
+		c.JSON(webhookErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, delivery)
+}
+
+// parseWebhookID lê um ID da rota
+func parseWebhookID(c *gin.Context, param string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(param), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// webhookErrorStatus traduz erros do serviço de webhooks em status HTTP
+func webhookErrorStatus(err error) int {
+	msg := err.Error()
+	switch {
+	case strings.HasSuffix(msg, "não encontrada"):
+		return http.StatusNotFound
+	case msg == "entrega ainda está pendente":
+		return http.StatusConflict
+	case strings.HasPrefix(msg, "url"),
+		strings.HasPrefix(msg, "secret"),
+		strings.HasPrefix(msg, "descrição"),
+		strings.HasPrefix(msg, "status inválido"),
+		strings.HasPrefix(msg, "tipo de evento inválido"),
+		strings.HasPrefix(msg, "informe"):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
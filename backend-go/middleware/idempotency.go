@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+
+	"backend-go/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IdempotencyHeader é o header enviado pelo cliente para deduplicar criações
+const IdempotencyHeader = "Idempotency-Key"
+
+// bodyRecorder captura a resposta escrita pelo handler para poder gravá-la
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency deduplica requisições POST que enviam o header Idempotency-Key.
+// A primeira resposta é gravada e devolvida novamente em retentativas com o
+// mesmo payload; a mesma chave com outro payload recebe 422. As chaves valem
+// por método e rota, sem depender de quem chama: uma retentativa de outro IP
+// ainda é deduplicada e a mesma chave com outro user_id recebe 422.
+// Requisições sem o header seguem normalmente.
+func Idempotency(svc service.IdempotencyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyHeader)
+		if c.Request.Method != http.MethodPost || key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > 191 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key deve ter no máximo 191 caracteres"})
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "erro ao ler corpo da requisição"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		scope := c.Request.Method + " " + c.Request.URL.Path
+		if len(scope) > 191 {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "rota longa demais para Idempotency-Key"})
+			return
+		}
+		sum := sha256.Sum256(append([]byte(scope+"\n"), body...))
+		hash := hex.EncodeToString(sum[:])
+
+		record, err := svc.Begin(scope, key, hash)
+		switch {
+		case errors.Is(err, service.ErrIdempotencyKeyReused):
+			c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, service.ErrIdempotencyInProgress):
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		case err != nil:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		// Retentativa: devolve a resposta original sem executar o handler
+		if record != nil {
+			c.Header("Idempotent-Replayed", "true")
+			c.Data(record.StatusCode, "application/json; charset=utf-8", record.ResponseBody)
+			c.Abort()
+			return
+		}
+
+		recorder := &bodyRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		// Um panic no handler libera a chave antes de seguir para o Recovery;
+		// sem isso, as retentativas receberiam 409 até a chave expirar
+		completed := false
+		defer func() {
+			if completed {
+				return
+			}
+			if err := svc.Release(scope, key); err != nil {
+				log.Printf("[idempotency] erro ao liberar chave %s: %v", key, err)
+			}
+		}()
+
+		c.Next()
+
+		// Erros de servidor não são gravados para permitir nova tentativa
+		status := recorder.Status()
+		if status >= http.StatusInternalServerError {
+			return
+		}
+		completed = true
+		if err := svc.Complete(scope, key, status, recorder.body.Bytes()); err != nil {
+			log.Printf("[idempotency] erro ao gravar resposta da chave %s: %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"backend-go/models"
+	"backend-go/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// fakeIdempotencyService guarda as chaves em memória com as regras do serviço real
+type fakeIdempotencyService struct {
+	mu   sync.Mutex
+	keys map[string]*models.IdempotencyKey
+}
+
+func newFakeIdempotencyService() *fakeIdempotencyService {
+	return &fakeIdempotencyService{keys: make(map[string]*models.IdempotencyKey)}
+}
+
+func (s *fakeIdempotencyService) Begin(scope, key, requestHash string) (*models.IdempotencyKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	existing, ok := s.keys[scope+"|"+key]
+	if !ok {
+		s.keys[scope+"|"+key] = &models.IdempotencyKey{Scope: scope, Key: key, RequestHash: requestHash}
+		return nil, nil
+	}
+	if existing.RequestHash != requestHash {
+		return nil, service.ErrIdempotencyKeyReused
+	}
+	if existing.StatusCode == 0 {
+		return nil, service.ErrIdempotencyInProgress
+	}
+	return existing, nil
+}
+
+func (s *fakeIdempotencyService) Complete(scope, key string, statusCode int, body []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys[scope+"|"+key].StatusCode = statusCode
+	s.keys[scope+"|"+key].ResponseBody = body
+	return nil
+}
+
+func (s *fakeIdempotencyService) Release(scope, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.keys, scope+"|"+key)
+	return nil
+}
+
+func (s *fakeIdempotencyService) PurgeExpired() (int64, error) { return 0, nil }
+
+func TestIdempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	type request struct {
+		body string
+		// IP de origem; vazio usa o padrão do httptest
+		remoteAddr string
+		wantStatus int
+		wantReplay bool
+	}
+
+	tests := []struct {
+		name string
+		// Comportamento do handler por chamada: "ok", "fail" (500) ou "panic"
+		handler  []string
+		requests []request
+		// Número esperado de execuções do handler
+		wantCalls int
+	}{
+		{
+			name:    "retentativa devolve a resposta gravada",
+			handler: []string{"ok"},
+			requests: []request{
+				{body: `{"user_id":1,"x":1}`, wantStatus: http.StatusCreated},
+				{body: `{"user_id":1,"x":1}`, wantStatus: http.StatusCreated, wantReplay: true},
+			},
+			wantCalls: 1,
+		},
+		{
+			name:    "mesma chave com outro payload recebe 422",
+			handler: []string{"ok"},
+			requests: []request{
+				{body: `{"user_id":1,"x":1}`, wantStatus: http.StatusCreated},
+				{body: `{"user_id":1,"x":2}`, wantStatus: http.StatusUnprocessableEntity},
+			},
+			wantCalls: 1,
+		},
+		{
+			name:    "mesma chave com outro usuário recebe 422",
+			handler: []string{"ok", "ok"},
+			requests: []request{
+				{body: `{"user_id":1,"x":1}`, wantStatus: http.StatusCreated},
+				{body: `{"user_id":2,"x":1}`, wantStatus: http.StatusUnprocessableEntity},
+			},
+			wantCalls: 1,
+		},
+		{
+			name:    "retentativa de outro IP devolve a resposta gravada",
+			handler: []string{"ok"},
+			requests: []request{
+				{body: `{"title":"a"}`, remoteAddr: "198.51.100.1:1234", wantStatus: http.StatusCreated},
+				{body: `{"title":"a"}`, remoteAddr: "203.0.113.9:4321", wantStatus: http.StatusCreated, wantReplay: true},
+			},
+			wantCalls: 1,
+		},
+		{
+			name:    "panic no handler libera a chave",
+			handler: []string{"panic", "ok"},
+			requests: []request{
+				{body: `{"user_id":1}`, wantStatus: http.StatusInternalServerError},
+				{body: `{"user_id":1}`, wantStatus: http.StatusCreated},
+			},
+			wantCalls: 2,
+		},
+		{
+			name:    "erro de servidor libera a chave",
+			handler: []string{"fail", "ok"},
+			requests: []request{
+				{body: `{"user_id":1}`, wantStatus: http.StatusInternalServerError},
+				{body: `{"user_id":1}`, wantStatus: http.StatusCreated},
+			},
+			wantCalls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			engine := gin.New()
+			engine.Use(gin.CustomRecovery(func(c *gin.Context, _ interface{}) {
+				c.AbortWithStatus(http.StatusInternalServerError)
+			}))
+			engine.POST("/items", Idempotency(newFakeIdempotencyService()), func(c *gin.Context) {
+				behavior := tt.handler[calls]
+				calls++
+				switch behavior {
+				case "panic":
+					panic("falha no handler")
+				case "fail":
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "falha"})
+				default:
+					c.JSON(http.StatusCreated, gin.H{"call": calls})
+				}
+			})
+
+			for i, req := range tt.requests {
+				httpReq := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(req.body))
+				httpReq.Header.Set("Content-Type", "application/json")
+				httpReq.Header.Set(IdempotencyHeader, "chave-1")
+				if req.remoteAddr != "" {
+					httpReq.RemoteAddr = req.remoteAddr
+				}
+				w := httptest.NewRecorder()
+				engine.ServeHTTP(w, httpReq)
+
+				if w.Code != req.wantStatus {
+					t.Fatalf("requisição %d: status %d, esperado %d", i, w.Code, req.wantStatus)
+				}
+				if replayed := w.Header().Get("Idempotent-Replayed") == "true"; replayed != req.wantReplay {
+					t.Fatalf("requisição %d: replay = %v, esperado %v", i, replayed, req.wantReplay)
+				}
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("handler executado %d vezes, esperado %d", calls, tt.wantCalls)
+			}
+		})
+	}
+}
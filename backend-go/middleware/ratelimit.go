@@ -0,0 +1,238 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimiter implementa token buckets em memória, um por chave
+type RateLimiter struct {
+	mu        sync.Mutex
+	rate      float64 // tokens por segundo
+	burst     float64
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Intervalo entre as limpezas de buckets ociosos
+const rateLimiterSweepInterval = time.Minute
+
+// NewRateLimiter cria um limitador que permite perMinute requisições por
+// minuto por chave, com rajadas de até burst requisições
+func NewRateLimiter(perMinute, burst int) *RateLimiter {
+	if perMinute < 1 {
+		perMinute = 1
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{
+		rate:      float64(perMinute) / 60,
+		burst:     float64(burst),
+		buckets:   make(map[string]*tokenBucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow consome de cada chave o número de tokens informado. A requisição só
+// passa se todas as chaves tiverem ao menos um token; o custo é então
+// descontado de uma vez e pode deixar o bucket negativo, de modo que um lote
+// grande é aceito mas bloqueia a chave até os tokens se recomporem. Se
+// alguma chave estiver sem token, nada é consumido e é retornado o tempo até
+// o próximo token.
+func (l *RateLimiter) Allow(costs map[string]int) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	var wait time.Duration
+	for key := range costs {
+		bucket, ok := l.buckets[key]
+		if !ok {
+			bucket = &tokenBucket{tokens: l.burst, last: now}
+			l.buckets[key] = bucket
+		}
+
+		bucket.tokens = math.Min(l.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*l.rate)
+		bucket.last = now
+
+		if bucket.tokens < 1 {
+			if keyWait := time.Duration((1 - bucket.tokens) / l.rate * float64(time.Second)); keyWait > wait {
+				wait = keyWait
+			}
+		}
+	}
+	if wait > 0 {
+		return false, wait
+	}
+
+	for key, cost := range costs {
+		l.buckets[key].tokens -= float64(cost)
+	}
+	return true, 0
+}
+
+// sweep remove os buckets que já teriam se recarregado por completo,
+// equivalentes a um bucket novo
+func (l *RateLimiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < rateLimiterSweepInterval {
+		return
+	}
+	l.lastSweep = now
+
+	for key, bucket := range l.buckets {
+		refill := time.Duration((l.burst - bucket.tokens) / l.rate * float64(time.Second))
+		if now.Sub(bucket.last) >= refill {
+			delete(l.buckets, key)
+		}
+	}
+}
+
+// RateLimit aplica o limitador às requisições de escrita (POST, PUT, DELETE).
+// keys retorna quem está sendo limitado e quantos tokens a requisição custa
+// para cada um; sem chaves, a requisição não é limitada.
+// Requisições acima do limite recebem 429 com o header Retry-After.
+func RateLimit(limiter *RateLimiter, keys func(c *gin.Context) map[string]int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			c.Next()
+			return
+		}
+
+		costs := keys(c)
+		if len(costs) == 0 {
+			c.Next()
+			return
+		}
+
+		if ok, wait := limiter.Allow(costs); !ok {
+			seconds := int(math.Ceil(wait.Seconds()))
+			c.Header("Retry-After", strconv.Itoa(seconds))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error": fmt.Sprintf("limite de requisições excedido, tente novamente em %ds", seconds),
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// Tamanho máximo do corpo inspecionado em busca dos itens e seus user_id;
+// corpos maiores são rejeitados pelos handlers
+const maxRateLimitPeekBytes = 5 << 20
+
+// Chave do contexto onde os itens da requisição ficam em cache
+const rateLimitItemsKey = "rate_limit_items"
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// ClientIPKey identifica a requisição pelo IP do cliente
+func ClientIPKey(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// ClientIPKeys limita pelo IP do cliente, cobrando um token por item da
+// requisição (cada interação de um lote conta)
+func ClientIPKeys(c *gin.Context) map[string]int {
+	return map[string]int{ClientIPKey(c): len(requestItems(c))}
+}
+
+// UserIDKeys limita por usuário: o parâmetro de rota user_id ou o campo
+// user_id de cada item do corpo, cobrando um token por item. O limite vale
+// para o usuário em qualquer IP; o limite por IP fica com ClientIPKeys.
+func UserIDKeys(c *gin.Context) map[string]int {
+	costs := make(map[string]int)
+	for _, userID := range requestItems(c) {
+		if userID != "" {
+			costs["user:"+userID]++
+		}
+	}
+	return costs
+}
+
+// requestItems retorna o user_id de cada item da requisição (vazio quando o
+// item não tem um): um item para objetos JSON e demais corpos, um por
+// elemento em lotes (array JSON ou NDJSON). O resultado fica em cache no
+// contexto, e o corpo continua disponível para o handler.
+func requestItems(c *gin.Context) []string {
+	if cached, ok := c.Get(rateLimitItemsKey); ok {
+		return cached.([]string)
+	}
+	items := parseRequestItems(c)
+	c.Set(rateLimitItemsKey, items)
+	return items
+}
+
+func parseRequestItems(c *gin.Context) []string {
+	if userID := c.Param("user_id"); userID != "" {
+		return []string{userID}
+	}
+	if c.Request.Body == nil || !strings.Contains(c.ContentType(), "json") {
+		return []string{""}
+	}
+
+	original := c.Request.Body
+	body, err := io.ReadAll(io.LimitReader(original, maxRateLimitPeekBytes))
+	c.Request.Body = readCloser{io.MultiReader(bytes.NewReader(body), original), original}
+	if err != nil || len(body) == maxRateLimitPeekBytes {
+		return []string{""}
+	}
+
+	trimmed := bytes.TrimSpace(body)
+	var items []string
+	switch {
+	case strings.Contains(c.ContentType(), "ndjson"):
+		for _, line := range bytes.Split(trimmed, []byte("\n")) {
+			if line = bytes.TrimSpace(line); len(line) > 0 {
+				items = append(items, bodyUserID(line))
+			}
+		}
+	case len(trimmed) > 0 && trimmed[0] == '[':
+		var raw []json.RawMessage
+		if json.Unmarshal(trimmed, &raw) != nil {
+			return []string{""}
+		}
+		for _, item := range raw {
+			items = append(items, bodyUserID(item))
+		}
+	default:
+		items = append(items, bodyUserID(trimmed))
+	}
+	if len(items) == 0 {
+		return []string{""}
+	}
+	return items
+}
+
+// bodyUserID retorna o campo user_id de um objeto JSON; vazio se não houver
+func bodyUserID(body []byte) string {
+	var payload struct {
+		UserID uint `json:"user_id"`
+	}
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || trimmed[0] != '{' || json.Unmarshal(trimmed, &payload) != nil || payload.UserID == 0 {
+		return ""
+	}
+	return strconv.FormatUint(uint64(payload.UserID), 10)
+}
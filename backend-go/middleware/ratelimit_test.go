@@ -0,0 +1,132 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	type request struct {
+		body        string
+		contentType string
+		// IP da conexão; padrão 10.0.0.1
+		remoteIP   string
+		wantStatus int
+	}
+	batch := func(userIDs ...string) string {
+		items := make([]string, len(userIDs))
+		for i, id := range userIDs {
+			items[i] = `{"user_id":` + id + `}`
+		}
+		return "[" + strings.Join(items, ",") + "]"
+	}
+
+	tests := []struct {
+		name     string
+		keys     func(c *gin.Context) map[string]int
+		burst    int
+		requests []request
+	}{
+		{
+			name:  "lote consome um token por item do limite por IP",
+			keys:  ClientIPKeys,
+			burst: 5,
+			requests: []request{
+				{body: batch("1", "1", "1", "1", "1"), wantStatus: http.StatusCreated},
+				{body: `{"user_id":1}`, wantStatus: http.StatusTooManyRequests},
+			},
+		},
+		{
+			name:  "lote maior que o saldo passa e bloqueia as próximas requisições",
+			keys:  ClientIPKeys,
+			burst: 3,
+			requests: []request{
+				{body: batch("1", "2", "3", "4", "5", "6"), wantStatus: http.StatusCreated},
+				{body: `{"user_id":1}`, wantStatus: http.StatusTooManyRequests},
+			},
+		},
+		{
+			name:  "NDJSON cobra cada linha",
+			keys:  ClientIPKeys,
+			burst: 3,
+			requests: []request{
+				{body: "{\"user_id\":1}\n{\"user_id\":1}\n{\"user_id\":1}\n", contentType: "application/x-ndjson", wantStatus: http.StatusCreated},
+				{body: `{"user_id":1}`, wantStatus: http.StatusTooManyRequests},
+			},
+		},
+		{
+			name:  "lote de um usuário esgota o limite do usuário",
+			keys:  UserIDKeys,
+			burst: 2,
+			requests: []request{
+				{body: batch("7", "7", "7"), wantStatus: http.StatusCreated},
+				{body: `{"user_id":7}`, wantStatus: http.StatusTooManyRequests},
+				{body: `{"user_id":8}`, wantStatus: http.StatusCreated},
+			},
+		},
+		{
+			name:  "trocar de IP não renova o limite do usuário",
+			keys:  UserIDKeys,
+			burst: 2,
+			requests: []request{
+				{body: `{"user_id":7}`, remoteIP: "10.0.0.9", wantStatus: http.StatusCreated},
+				{body: `{"user_id":7}`, remoteIP: "10.0.0.9", wantStatus: http.StatusCreated},
+				{body: `{"user_id":7}`, wantStatus: http.StatusTooManyRequests},
+			},
+		},
+		{
+			name:  "corpo sem user_id não é limitado por usuário",
+			keys:  UserIDKeys,
+			burst: 1,
+			requests: []request{
+				{body: `{"content_id":1}`, wantStatus: http.StatusCreated},
+				{body: `{"content_id":1}`, wantStatus: http.StatusCreated},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Taxa de 1 token por minuto: nada se recompõe durante o teste
+			limiter := NewRateLimiter(1, tt.burst)
+			engine := gin.New()
+			engine.POST("/interactions", RateLimit(limiter, tt.keys), func(c *gin.Context) {
+				body, _ := io.ReadAll(c.Request.Body)
+				c.String(http.StatusCreated, string(body))
+			})
+
+			for i, req := range tt.requests {
+				httpReq := httptest.NewRequest(http.MethodPost, "/interactions", strings.NewReader(req.body))
+				contentType := req.contentType
+				if contentType == "" {
+					contentType = "application/json"
+				}
+				httpReq.Header.Set("Content-Type", contentType)
+				remoteIP := req.remoteIP
+				if remoteIP == "" {
+					remoteIP = "10.0.0.1"
+				}
+				httpReq.RemoteAddr = remoteIP + ":1234"
+				w := httptest.NewRecorder()
+				engine.ServeHTTP(w, httpReq)
+
+				if w.Code != req.wantStatus {
+					t.Fatalf("requisição %d: status %d, esperado %d", i, w.Code, req.wantStatus)
+				}
+				if w.Code == http.StatusCreated && w.Body.String() != req.body {
+					t.Fatalf("requisição %d: handler recebeu %q, esperado %q", i, w.Body.String(), req.body)
+				}
+				if w.Code == http.StatusTooManyRequests && w.Header().Get("Retry-After") == "" {
+					t.Fatalf("requisição %d: 429 sem Retry-After", i)
+				}
+			}
+		})
+	}
+}
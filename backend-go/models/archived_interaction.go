@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// =========================
+// ARCHIVED_INTERACTIONS
+// =========================
+// Interações movidas de user_interactions pela política de retenção
+// (armazenamento "table"). Mantém o ID original para permitir a restauração.
+type ArchivedInteraction struct {
+	ID              uint      `gorm:"primaryKey;autoIncrement:false" json:"id"`
+	ArchiveID       uint      `gorm:"index;not null" json:"archive_id"`
+	UserID          uint      `gorm:"index:idx_archived_user_content" json:"user_id"`
+	ContentID       uint      `gorm:"index:idx_archived_user_content" json:"content_id"`
+	InteractionType string    `json:"interaction_type"`
+	Rating          *float64  `json:"rating,omitempty"`
+	ClientEventID   *string   `gorm:"size:64" json:"client_event_id,omitempty"`
+	SessionID       *string   `gorm:"size:64" json:"session_id,omitempty"`
+	Device          *string   `gorm:"size:32" json:"device,omitempty"`
+	AppVersion      *string   `gorm:"size:32" json:"app_version,omitempty"`
+	Surface         *string   `gorm:"size:16" json:"surface,omitempty"`
+	Position        *int      `gorm:"type:smallint" json:"position,omitempty"`
+	DwellMs         *int      `json:"dwell_ms,omitempty"`
+	Flagged         bool      `gorm:"not null;default:false" json:"flagged,omitempty"`
+	FlagReason      *string   `gorm:"size:32" json:"flag_reason,omitempty"`
+	CreatedAt       time.Time `gorm:"index" json:"created_at"`
+}
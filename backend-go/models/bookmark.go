@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// =========================
+// BOOKMARKS
+// =========================
+type Bookmark struct {
+	UserID    uint      `gorm:"primaryKey" json:"user_id"`
+	ContentID uint      `gorm:"primaryKey;index" json:"content_id"`
+	CreatedAt time.Time `json:"created_at"`
+
+	// Relationships
+	User    User    `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"user,omitempty" swaggerignore:"true"`
+	Content Content `gorm:"foreignKey:ContentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"content,omitempty"`
+}
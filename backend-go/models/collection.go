@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Visibilidade de uma coleção
+const (
+	CollectionPublic  = "public"
+	CollectionPrivate = "private"
+)
+
+// =========================
+// COLLECTIONS
+// =========================
+type Collection struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	OwnerID     uint      `gorm:"index;not null" json:"owner_id"`
+	Name        string    `gorm:"size:120;not null" json:"name"`
+	Slug        string    `gorm:"size:191;uniqueIndex" json:"slug"`
+	Description string    `gorm:"size:1000" json:"description"`
+	Visibility  string    `gorm:"size:20;not null;default:private" json:"visibility"`
+	Editorial   bool      `gorm:"not null;default:false;index" json:"editorial"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Relationships
+	Owner User             `gorm:"foreignKey:OwnerID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"owner,omitempty" swaggerignore:"true"`
+	Items []CollectionItem `gorm:"foreignKey:CollectionID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"items,omitempty"`
+}
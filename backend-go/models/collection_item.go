@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// =========================
+// COLLECTION_ITEMS
+// =========================
+type CollectionItem struct {
+	CollectionID uint      `gorm:"primaryKey" json:"collection_id"`
+	ContentID    uint      `gorm:"primaryKey" json:"content_id"`
+	Position     int       `gorm:"not null" json:"position"`
+	AddedAt      time.Time `gorm:"autoCreateTime" json:"added_at"`
+
+	// Relationships
+	Content Content `gorm:"foreignKey:ContentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"content,omitempty"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// =========================
+// COMMENTS
+// =========================
+type Comment struct {
+	ID        uint      `gorm:"primaryKey" json:"id"`
+	ContentID uint      `gorm:"index;not null" json:"content_id"`
+	UserID    uint      `gorm:"index;not null" json:"user_id"`
+	ParentID  *uint     `gorm:"index" json:"parent_id,omitempty"`
+	RootID    *uint     `gorm:"index" json:"root_id,omitempty"`
+	Body      string    `gorm:"size:2000;not null" json:"body"`
+	Deleted   bool      `gorm:"not null;default:false" json:"deleted"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Relationships
+	Content Content `gorm:"foreignKey:ContentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"content,omitempty" swaggerignore:"true"`
+	User    User    `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"user,omitempty" swaggerignore:"true"`
+}
@@ -2,6 +2,14 @@ package models
 
 import "time"
 
+// Motivos pelos quais um conteúdo foi ocultado
+const (
+	// Oculto automaticamente ao atingir o limite de denúncias
+	HiddenReasonReports = "reports"
+	// Oculto por decisão de um moderador
+	HiddenReasonModerator = "moderator"
+)
+
 // =========================
 // CONTENTS
 // =========================
@@ -11,9 +19,13 @@ type Content struct {
 	Description string    `json:"description"`
 	Type        string    `json:"type"`
 	ReleaseDate time.Time `json:"release_date"`
-	CreatedAt   time.Time `json:"created_at"`
+	Hidden      bool      `gorm:"not null;default:false" json:"hidden"`
+	// Motivo da ocultação; só ocultações automáticas são desfeitas ao
+	// descartar denúncias
+	HiddenReason *string   `gorm:"size:20" json:"hidden_reason,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
 
 	// Relationships
-	Interactions []UserInteraction `gorm:"foreignKey:ContentID" json:"user_interactions,omitempty"`
+	Interactions []UserInteraction `gorm:"foreignKey:ContentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"user_interactions,omitempty"`
 	Categories   []Category        `gorm:"many2many:content_categories" json:"categories,omitempty"`
 }
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// =========================
+// CONTENT_DAILY_STATS
+// =========================
+// Rollup diário de interações por conteúdo e tipo, mantido pelo job de
+// agregação a partir de user_interactions.
+type ContentDailyStat struct {
+	ContentID       uint      `gorm:"primaryKey" json:"content_id"`
+	Day             time.Time `gorm:"primaryKey;type:date;index" json:"day"`
+	InteractionType string    `gorm:"primaryKey;size:20" json:"interaction_type"`
+	Interactions    int64     `gorm:"not null;default:0" json:"interactions"`
+	RatingSum       float64   `gorm:"not null;default:0" json:"rating_sum"`
+	RatingCount     int64     `gorm:"not null;default:0" json:"rating_count"`
+	UpdatedAt       time.Time `json:"updated_at"`
+
+	// Relationships
+	Content Content `gorm:"foreignKey:ContentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"content,omitempty" swaggerignore:"true"`
+}
@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Status de uma denúncia
+const (
+	ReportStatusOpen      = "open"
+	ReportStatusDismissed = "dismissed"
+	ReportStatusActioned  = "actioned"
+)
+
+// =========================
+// CONTENT_REPORTS
+// =========================
+type ContentReport struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	ContentID  uint       `gorm:"uniqueIndex:idx_content_report_user;not null" json:"content_id"`
+	UserID     uint       `gorm:"uniqueIndex:idx_content_report_user;index;not null" json:"user_id"`
+	Reason     string     `gorm:"size:30;not null" json:"reason"`
+	Details    string     `gorm:"size:1000" json:"details"`
+	Status     string     `gorm:"size:20;not null;default:open;index" json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+
+	// Relationships
+	Content Content `gorm:"foreignKey:ContentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"content,omitempty" swaggerignore:"true"`
+	User    User    `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"user,omitempty" swaggerignore:"true"`
+}
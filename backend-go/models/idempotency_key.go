@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// =========================
+// IDEMPOTENCY_KEYS
+// =========================
+type IdempotencyKey struct {
+	Scope        string    `gorm:"primaryKey;size:191" json:"scope"`
+	Key          string    `gorm:"primaryKey;column:request_key;size:191" json:"key"`
+	RequestHash  string    `gorm:"size:64;not null" json:"request_hash"`
+	StatusCode   int       `gorm:"not null;default:0" json:"status_code"`
+	ResponseBody []byte    `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+	ExpiresAt    time.Time `gorm:"index;not null" json:"expires_at"`
+}
@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// Armazenamentos de arquivamento de interações
+const (
+	ArchiveStorageTable = "table"
+	ArchiveStorageFile  = "file"
+)
+
+// Status de um arquivamento. Em "archiving" parte das interações ainda não
+// saiu de user_interactions; a próxima execução retoma o mesmo registro.
+const (
+	ArchiveStatusArchiving = "archiving"
+	ArchiveStatusArchived  = "archived"
+	ArchiveStatusRestored  = "restored"
+)
+
+// =========================
+// INTERACTION_ARCHIVES
+// =========================
+// Registro de cada período de interações arquivado pela política de retenção
+type InteractionArchive struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	RangeStart time.Time  `gorm:"index;not null" json:"range_start"`
+	RangeEnd   time.Time  `gorm:"not null" json:"range_end"`
+	Storage    string     `gorm:"size:10;not null" json:"storage"`
+	Path       string     `gorm:"size:255" json:"path,omitempty"`
+	Rows       int64      `gorm:"not null;default:0" json:"rows"`
+	MaxID      uint       `gorm:"not null;default:0" json:"max_id"`
+	Status     string     `gorm:"size:20;not null;default:archived;index" json:"status"`
+	CreatedAt  time.Time  `json:"created_at"`
+	RestoredAt *time.Time `json:"restored_at,omitempty"`
+}
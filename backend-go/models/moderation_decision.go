@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// Ações registradas no histórico de moderação
+const (
+	ModerationActionAutoHide = "auto_hide"
+	ModerationActionDismiss  = "dismiss"
+	ModerationActionAction   = "action"
+)
+
+// =========================
+// MODERATION_DECISIONS
+// =========================
+type ModerationDecision struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	ContentID   uint      `gorm:"index;not null" json:"content_id"`
+	ReportID    *uint     `gorm:"index" json:"report_id,omitempty"`
+	ModeratorID *uint     `json:"moderator_id,omitempty"`
+	Action      string    `gorm:"size:30;not null" json:"action"`
+	Note        string    `gorm:"size:1000" json:"note"`
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// Tópicos do outbox
+const (
+	// Interação (ou feedback implícito, como salvar) a repassar ao motor Python
+	OutboxTopicRecommenderInteraction = "recommender.interaction"
+	// Evento de domínio a repassar às assinaturas de webhook
+	OutboxTopicWebhookEvent = "webhook.event"
+	// Evento de domínio a publicar no barramento de eventos
+	OutboxTopicBusEvent = "bus.event"
+)
+
+// Status de um evento do outbox
+const (
+	OutboxStatusPending   = "pending"
+	OutboxStatusDelivered = "delivered"
+	OutboxStatusDead      = "dead"
+)
+
+// =========================
+// OUTBOX_EVENTS
+// =========================
+// Evento gravado na mesma transação da escrita que o originou e entregue
+// depois por um worker, com novas tentativas até ser entregue ou esgotar as
+// tentativas (status dead). Eventos com a mesma AggregateKey são entregues
+// na ordem em que foram gravados.
+type OutboxEvent struct {
+	ID            uint       `gorm:"primaryKey" json:"id"`
+	Topic         string     `gorm:"size:64;not null;index" json:"topic"`
+	AggregateKey  string     `gorm:"size:64;not null;default:'';index" json:"aggregate_key,omitempty"`
+	Payload       string     `gorm:"type:text;not null" json:"payload"`
+	Status        string     `gorm:"size:16;not null;default:pending;index:idx_outbox_due,priority:1" json:"status"`
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt time.Time  `gorm:"index:idx_outbox_due,priority:2" json:"next_attempt_at"`
+	LastError     *string    `gorm:"type:text" json:"last_error,omitempty"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt     time.Time  `gorm:"index" json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
@@ -9,12 +9,23 @@ import (
 // =========================
 // RECOMMENDATIONS
 // =========================
+// Lista servida a um usuário, gravada a cada resposta de recomendações.
+// Scores guarda a pontuação de cada conteúdo ({"<content_id>": score});
+// conteúdos que completaram a lista pelas coleções editoriais não têm
+// pontuação. Os campos de contexto vêm da requisição e são opcionais.
 type Recommendation struct {
 	ID                    uint           `gorm:"primaryKey" json:"id"`
-	UserID                uint           `json:"user_id"`
+	UserID                uint           `gorm:"index:idx_recommendation_user_created,priority:1" json:"user_id"`
 	RecommendedContentIDs datatypes.JSON `json:"recommended_content_ids" swaggertype:"string"`
+	Scores                datatypes.JSON `json:"scores,omitempty" swaggertype:"string"`
+	Method                string         `gorm:"size:32;index" json:"method"`
+	RequestedMethod       string         `gorm:"size:32" json:"requested_method"`
 	ModelVersion          string         `json:"model_version"`
-	CreatedAt             time.Time      `json:"created_at"`
+	SessionID             *string        `gorm:"size:64" json:"session_id,omitempty"`
+	Device                *string        `gorm:"size:32" json:"device,omitempty"`
+	AppVersion            *string        `gorm:"size:32" json:"app_version,omitempty"`
+	Surface               *string        `gorm:"size:16" json:"surface,omitempty"`
+	CreatedAt             time.Time      `gorm:"index:idx_recommendation_user_created,priority:2;index" json:"created_at"`
 
 	// Relationships
 	User User `gorm:"foreignKey:UserID" json:"user,omitempty" swaggerignore:"true"`
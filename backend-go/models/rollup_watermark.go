@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// =========================
+// ROLLUP_WATERMARKS
+// =========================
+// Último ID de user_interactions já agregado por cada job de rollup
+type RollupWatermark struct {
+	Name              string    `gorm:"primaryKey;size:64" json:"name"`
+	LastInteractionID uint      `gorm:"not null;default:0" json:"last_interaction_id"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// =========================
+// ROLLUP_GAPS
+// =========================
+// IDs de user_interactions que ainda não existiam quando o watermark de um job
+// passou por eles, normalmente de transações ainda não confirmadas. São
+// relidos nas execuções seguintes até aparecerem ou expirarem.
+type RollupGap struct {
+	Name          string    `gorm:"primaryKey;size:64" json:"name"`
+	InteractionID uint      `gorm:"primaryKey;autoIncrement:false;index" json:"interaction_id"`
+	SeenAt        time.Time `gorm:"not null" json:"seen_at"`
+}
@@ -2,6 +2,13 @@ package models
 
 import "time"
 
+// Papéis de usuário
+const (
+	RoleUser   = "user"
+	RoleEditor = "editor"
+	RoleAdmin  = "admin"
+)
+
 // =========================
 // USERS
 // =========================
@@ -10,9 +17,10 @@ type User struct {
 	Name      string    `gorm:"size:120;not null" json:"name"`
 	Email     string    `gorm:"size:191;not null;uniqueIndex" json:"email"`
 	Password  string    `gorm:"size:255;not null" json:"password_hash"`
+	Role      string    `gorm:"size:20;not null;default:user" json:"role"`
 	CreatedAt time.Time `json:"created_at"`
 
 	// Relationships
-	Interactions    []UserInteraction `gorm:"foreignKey:UserID" json:"interactions,omitempty" swaggerignore:"true"`
-	Recommendations []Recommendation  `gorm:"foreignKey:UserID" json:"recommendations,omitempty" swaggerignore:"true"`
+	Interactions    []UserInteraction `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"interactions,omitempty" swaggerignore:"true"`
+	Recommendations []Recommendation  `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"recommendations,omitempty" swaggerignore:"true"`
 }
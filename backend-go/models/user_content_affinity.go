@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// =========================
+// USER_CONTENT_AFFINITY
+// =========================
+// Afinidade de um usuário com um conteúdo, calculada a partir dos pesos por
+// tipo de interação com decaimento exponencial. Score é o valor em ComputedAt;
+// o valor atual é Score * 0.5^((agora - ComputedAt) / HalfLifeHours).
+type UserContentAffinity struct {
+	UserID        uint      `gorm:"primaryKey" json:"user_id"`
+	ContentID     uint      `gorm:"primaryKey;index" json:"content_id"`
+	Score         float64   `gorm:"not null;default:0" json:"score"`
+	HalfLifeHours float64   `gorm:"not null;default:0" json:"half_life_hours"`
+	LastEventAt   time.Time `json:"last_event_at"`
+	ComputedAt    time.Time `gorm:"index" json:"computed_at"`
+
+	// Relationships
+	User    User    `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"user,omitempty" swaggerignore:"true"`
+	Content Content `gorm:"foreignKey:ContentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"content,omitempty" swaggerignore:"true"`
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// =========================
+// USER_DAILY_STATS
+// =========================
+// Rollup diário de interações por usuário e tipo
+type UserDailyStat struct {
+	UserID          uint      `gorm:"primaryKey" json:"user_id"`
+	Day             time.Time `gorm:"primaryKey;type:date;index" json:"day"`
+	InteractionType string    `gorm:"primaryKey;size:20" json:"interaction_type"`
+	Interactions    int64     `gorm:"not null;default:0" json:"interactions"`
+	UpdatedAt       time.Time `json:"updated_at"`
+
+	// Relationships
+	User User `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"user,omitempty" swaggerignore:"true"`
+}
@@ -2,16 +2,37 @@ package models
 
 import "time"
 
+// Motivos pelos quais uma interação é sinalizada como suspeita. Interações
+// sinalizadas ficam gravadas, mas não entram nas estatísticas nem são
+// repassadas ao motor de recomendação.
+const (
+	FlagReasonBurst      = "burst"
+	FlagReasonRatingBomb = "rating_bomb"
+	FlagReasonReRating   = "re_rating"
+)
+
 // =========================
 // USER_INTERACTIONS
 // =========================
 type UserInteraction struct {
 	ID              uint      `gorm:"primaryKey" json:"id"`
-	UserID          uint      `json:"user_id"`
+	UserID          uint      `gorm:"uniqueIndex:idx_user_client_event" json:"user_id"`
 	ContentID       uint      `json:"content_id"`
 	InteractionType string    `json:"interaction_type"`
 	Rating          *float64  `json:"rating,omitempty"`
+	ClientEventID   *string   `gorm:"size:64;uniqueIndex:idx_user_client_event" json:"client_event_id,omitempty"`
+	SessionID       *string   `gorm:"size:64" json:"session_id,omitempty"`
+	Device          *string   `gorm:"size:32" json:"device,omitempty"`
+	AppVersion      *string   `gorm:"size:32" json:"app_version,omitempty"`
+	Surface         *string   `gorm:"size:16;index" json:"surface,omitempty"`
+	Position        *int      `gorm:"type:smallint" json:"position,omitempty"`
+	DwellMs         *int      `json:"dwell_ms,omitempty"`
+	Flagged         bool      `gorm:"not null;default:false;index" json:"flagged"`
+	FlagReason      *string   `gorm:"size:32" json:"flag_reason,omitempty"`
 	CreatedAt       time.Time `json:"created_at"`
+	// Momento em que o servidor recebeu o evento; CreatedAt pode vir do
+	// relógio do cliente em sincronizações offline
+	ReceivedAt time.Time `gorm:"index" json:"received_at"`
 
 	// Relationships
 	User    User    `gorm:"foreignKey:UserID" json:"user,omitempty"`
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// =========================
+// USER_REACTIONS
+// =========================
+// Estado atual da reação de um usuário a um conteúdo, derivado do log de
+// user_interactions (like/dislike/rating e suas retratações).
+type UserReaction struct {
+	UserID    uint     `gorm:"primaryKey" json:"user_id"`
+	ContentID uint     `gorm:"primaryKey;index" json:"content_id"`
+	Reaction  *string  `gorm:"size:16" json:"reaction,omitempty"`
+	Rating    *float64 `json:"rating,omitempty"`
+	// Último evento aplicado a Reaction e a Rating; like/dislike e nota são
+	// independentes, então cada um tem o seu
+	ReactedAt time.Time `json:"reacted_at"`
+	RatedAt   time.Time `json:"rated_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Relationships
+	User    User    `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"user,omitempty" swaggerignore:"true"`
+	Content Content `gorm:"foreignKey:ContentID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"content,omitempty" swaggerignore:"true"`
+}
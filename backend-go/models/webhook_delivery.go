@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Status de uma entrega de webhook
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// =========================
+// WEBHOOK_DELIVERIES
+// =========================
+// Entrega de um evento a uma assinatura. Funciona também como log: guarda as
+// tentativas, o último status HTTP e o último erro. Uma reentrega manual cria
+// uma nova linha com o mesmo EventID.
+type WebhookDelivery struct {
+	ID             uint       `gorm:"primaryKey" json:"id"`
+	SubscriptionID uint       `gorm:"not null;index" json:"subscription_id"`
+	EventID        string     `gorm:"size:32;not null;index" json:"event_id"`
+	EventType      string     `gorm:"size:64;not null" json:"event_type"`
+	Payload        string     `gorm:"type:text;not null" json:"payload"`
+	Status         string     `gorm:"size:16;not null;default:pending;index:idx_webhook_due,priority:1" json:"status"`
+	Attempts       int        `gorm:"not null;default:0" json:"attempts"`
+	NextAttemptAt  time.Time  `gorm:"index:idx_webhook_due,priority:2" json:"next_attempt_at"`
+	ResponseStatus *int       `json:"response_status,omitempty"`
+	LastError      *string    `gorm:"type:text" json:"last_error,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time  `gorm:"index" json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
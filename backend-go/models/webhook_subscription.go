@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// =========================
+// WEBHOOK_SUBSCRIPTIONS
+// =========================
+// Assinatura de um sistema parceiro para receber eventos por webhook.
+// EventTypes guarda os tipos assinados separados por vírgula; "*" assina todos.
+type WebhookSubscription struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	URL         string    `gorm:"size:500;not null" json:"url"`
+	Secret      string    `gorm:"size:128;not null" json:"-"`
+	EventTypes  string    `gorm:"size:255;not null" json:"event_types"`
+	Description string    `gorm:"size:255" json:"description,omitempty"`
+	Active      bool      `gorm:"not null;default:true;index" json:"active"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	// Relationships
+	Deliveries []WebhookDelivery `gorm:"foreignKey:SubscriptionID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"deliveries,omitempty" swaggerignore:"true"`
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"backend-go/models"
+	"errors"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+// AffinityRepository define a interface para a tabela user_content_affinity
+type AffinityRepository interface {
+	Get(userID, contentID uint) (*models.UserContentAffinity, error)
+	ListByUserID(userID uint) ([]models.UserContentAffinity, error)
+	Apply(name string, fromID, toID uint, gaps RollupGapChange, affinities []models.UserContentAffinity, removed []UserContentPair) error
+	ReplaceAll(name string, watermark uint, affinities []models.UserContentAffinity) error
+	ListPairEvents(userID, contentID uint) ([]models.UserInteraction, error)
+	ListPairs(afterUserID, afterContentID uint, limit int) ([]UserContentPair, error)
+}
+
+// UserContentPair identifica um par usuário-conteúdo
+type UserContentPair struct {
+	UserID    uint
+	ContentID uint
+}
+
+type affinityRepository struct {
+	db *gorm.DB
+}
+
+// NewAffinityRepository cria uma nova instância do AffinityRepository
+func NewAffinityRepository(db *gorm.DB) AffinityRepository {
+	return &affinityRepository{db: db}
+}
+
+// Get busca a afinidade de um usuário com um conteúdo; nil se não houver
+func (r *affinityRepository) Get(userID, contentID uint) (*models.UserContentAffinity, error) {
+	var affinity models.UserContentAffinity
+	if err := r.db.Where("user_id = ? AND content_id = ?", userID, contentID).
+		First(&affinity).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &affinity, nil
+}
+
+// ListByUserID busca todas as afinidades de um usuário
+func (r *affinityRepository) ListByUserID(userID uint) ([]models.UserContentAffinity, error) {
+	var affinities []models.UserContentAffinity
+	if err := r.db.Where("user_id = ?", userID).Find(&affinities).Error; err != nil {
+		return nil, err
+	}
+	return affinities, nil
+}
+
+// Apply grava as afinidades recalculadas, remove as dos pares sem eventos
+// válidos e avança o watermark de fromID para toID numa única transação.
+// Retorna ErrWatermarkMoved se o watermark não estiver mais em fromID.
+func (r *affinityRepository) Apply(name string, fromID, toID uint, gaps RollupGapChange, affinities []models.UserContentAffinity, removed []UserContentPair) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockWatermark(tx, name, fromID); err != nil {
+			return err
+		}
+		if err := updateGaps(tx, name, gaps); err != nil {
+			return err
+		}
+
+		for i := range affinities {
+			if err := tx.Omit("User", "Content").Save(&affinities[i]).Error; err != nil {
+				return err
+			}
+		}
+		for _, pair := range removed {
+			if err := tx.Where("user_id = ? AND content_id = ?", pair.UserID, pair.ContentID).
+				Delete(&models.UserContentAffinity{}).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&models.RollupWatermark{}).
+			Where("name = ?", name).
+			Update("last_interaction_id", toID).Error
+	})
+}
+
+// ReplaceAll substitui todas as afinidades numa única transação, então as
+// leituras continuam vendo a tabela anterior até o recálculo terminar. O
+// watermark precisa continuar no valor lido antes do recálculo; caso
+// contrário, uma atualização incremental gravada no meio seria sobrescrita.
+func (r *affinityRepository) ReplaceAll(name string, watermark uint, affinities []models.UserContentAffinity) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockWatermark(tx, name, watermark); err != nil {
+			return err
+		}
+		if err := tx.Where("1 = 1").Delete(&models.UserContentAffinity{}).Error; err != nil {
+			return err
+		}
+		if len(affinities) == 0 {
+			return nil
+		}
+		return tx.Omit("User", "Content").CreateInBatches(affinities, 200).Error
+	})
+}
+
+// ListPairEvents busca os eventos não sinalizados de um par em ordem
+// cronológica, incluindo os arquivados em tabela pela política de retenção
+func (r *affinityRepository) ListPairEvents(userID, contentID uint) ([]models.UserInteraction, error) {
+	var interactions []models.UserInteraction
+	if err := r.db.Select("id", "user_id", "content_id", "interaction_type", "rating", "created_at").
+		Where("user_id = ? AND content_id = ? AND flagged = ?", userID, contentID, false).
+		Find(&interactions).Error; err != nil {
+		return nil, err
+	}
+
+	var archived []models.ArchivedInteraction
+	if err := r.db.Select("id", "user_id", "content_id", "interaction_type", "rating", "created_at").
+		Where("user_id = ? AND content_id = ? AND flagged = ?", userID, contentID, false).
+		Find(&archived).Error; err != nil {
+		return nil, err
+	}
+	for _, a := range archived {
+		interactions = append(interactions, models.UserInteraction{
+			ID:              a.ID,
+			UserID:          a.UserID,
+			ContentID:       a.ContentID,
+			InteractionType: a.InteractionType,
+			Rating:          a.Rating,
+			CreatedAt:       a.CreatedAt,
+		})
+	}
+
+	sort.Slice(interactions, func(i, j int) bool {
+		if !interactions[i].CreatedAt.Equal(interactions[j].CreatedAt) {
+			return interactions[i].CreatedAt.Before(interactions[j].CreatedAt)
+		}
+		return interactions[i].ID < interactions[j].ID
+	})
+	return interactions, nil
+}
+
+// ListPairs busca os pares distintos com interações, ativas ou arquivadas em
+// tabela, em ordem, a partir do par (afterUserID, afterContentID)
+func (r *affinityRepository) ListPairs(afterUserID, afterContentID uint, limit int) ([]UserContentPair, error) {
+	var pairs []UserContentPair
+	if err := r.db.Raw(
+		"SELECT user_id, content_id FROM ("+
+			"SELECT user_id, content_id FROM user_interactions "+
+			"UNION SELECT user_id, content_id FROM archived_interactions"+
+			") pairs WHERE user_id > ? OR (user_id = ? AND content_id > ?) "+
+			"ORDER BY user_id ASC, content_id ASC LIMIT ?",
+		afterUserID, afterUserID, afterContentID, limit,
+	).Scan(&pairs).Error; err != nil {
+		return nil, err
+	}
+	return pairs, nil
+}
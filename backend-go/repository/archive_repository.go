@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"backend-go/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ArchiveRepository define a interface para o arquivamento de interações antigas
+type ArchiveRepository interface {
+	Create(archive *models.InteractionArchive) error
+	Update(archive *models.InteractionArchive) error
+	GetByID(id uint) (*models.InteractionArchive, error)
+	List(limit, offset int) ([]models.InteractionArchive, int64, error)
+	ListRestoredSince(since time.Time) ([]models.InteractionArchive, error)
+	ListInProgress() ([]models.InteractionArchive, error)
+	ArchivedUntil() (*time.Time, error)
+	OldestInteraction(before time.Time, maxID uint) (*time.Time, error)
+	ListInteractions(from, to time.Time, afterID, maxID uint, limit int) ([]models.UserInteraction, error)
+	MoveToTable(archive *models.InteractionArchive, rows []models.ArchivedInteraction) error
+	DeleteInteractions(ids []uint) error
+	ListArchivedRows(archiveID, afterID uint, limit int) ([]models.ArchivedInteraction, error)
+	RestoreRows(interactions []models.UserInteraction, archivedIDs []uint) error
+}
+
+type archiveRepository struct {
+	db *gorm.DB
+}
+
+// NewArchiveRepository cria uma nova instância do ArchiveRepository
+func NewArchiveRepository(db *gorm.DB) ArchiveRepository {
+	return &archiveRepository{db: db}
+}
+
+// Create registra um novo arquivamento
+func (r *archiveRepository) Create(archive *models.InteractionArchive) error {
+	return r.db.Create(archive).Error
+}
+
+// Update atualiza um arquivamento existente
+func (r *archiveRepository) Update(archive *models.InteractionArchive) error {
+	return r.db.Save(archive).Error
+}
+
+// GetByID busca um arquivamento por ID
+func (r *archiveRepository) GetByID(id uint) (*models.InteractionArchive, error) {
+	var archive models.InteractionArchive
+	if err := r.db.First(&archive, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("arquivamento não encontrado")
+		}
+		return nil, err
+	}
+	return &archive, nil
+}
+
+// List busca os arquivamentos com paginação, do mais recente para o mais antigo
+func (r *archiveRepository) List(limit, offset int) ([]models.InteractionArchive, int64, error) {
+	var archives []models.InteractionArchive
+	var total int64
+
+	if err := r.db.Model(&models.InteractionArchive{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := r.db.Order("range_start DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&archives).Error; err != nil {
+		return nil, 0, err
+	}
+	return archives, total, nil
+}
+
+// ListRestoredSince busca os arquivamentos restaurados a partir do instante informado
+func (r *archiveRepository) ListRestoredSince(since time.Time) ([]models.InteractionArchive, error) {
+	var archives []models.InteractionArchive
+	if err := r.db.Where("status = ? AND restored_at >= ?", models.ArchiveStatusRestored, since).
+		Find(&archives).Error; err != nil {
+		return nil, err
+	}
+	return archives, nil
+}
+
+// ListInProgress busca os arquivamentos interrompidos (status archiving)
+func (r *archiveRepository) ListInProgress() ([]models.InteractionArchive, error) {
+	var archives []models.InteractionArchive
+	if err := r.db.Where("status = ?", models.ArchiveStatusArchiving).
+		Order("range_start ASC, id ASC").
+		Find(&archives).Error; err != nil {
+		return nil, err
+	}
+	return archives, nil
+}
+
+// ArchivedUntil retorna o fim do período arquivado mais recente que ainda
+// não foi restaurado, inclusive os interrompidos; nil se não houver nenhum
+func (r *archiveRepository) ArchivedUntil() (*time.Time, error) {
+	var archive models.InteractionArchive
+	if err := r.db.Where("status IN ?", []string{models.ArchiveStatusArchived, models.ArchiveStatusArchiving}).
+		Order("range_end DESC").
+		First(&archive).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &archive.RangeEnd, nil
+}
+
+// OldestInteraction retorna o created_at da interação mais antiga anterior a
+// before e com ID até maxID; nil se não houver nenhuma
+func (r *archiveRepository) OldestInteraction(before time.Time, maxID uint) (*time.Time, error) {
+	var interaction models.UserInteraction
+	if err := r.db.Select("id", "created_at").
+		Where("created_at < ? AND id <= ?", before, maxID).
+		Order("created_at ASC").
+		First(&interaction).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &interaction.CreatedAt, nil
+}
+
+// ListInteractions busca interações criadas em [from, to) com ID no
+// intervalo (afterID, maxID], em ordem de ID. Interações em lacunas ainda
+// abertas dos jobs de rollup não foram agregadas e ficam de fora.
+func (r *archiveRepository) ListInteractions(from, to time.Time, afterID, maxID uint, limit int) ([]models.UserInteraction, error) {
+	var interactions []models.UserInteraction
+	if err := r.db.Where("created_at >= ? AND created_at < ?", from, to).
+		Where("id > ? AND id <= ?", afterID, maxID).
+		Where("id NOT IN (SELECT interaction_id FROM rollup_gaps)").
+		Order("id ASC").
+		Limit(limit).
+		Find(&interactions).Error; err != nil {
+		return nil, err
+	}
+	return interactions, nil
+}
+
+// MoveToTable copia as interações para archived_interactions, as remove de
+// user_interactions e atualiza a contagem do arquivamento numa única
+// transação. O registro do arquivamento é criado junto com o primeiro lote,
+// então nunca existe sem as linhas que descreve.
+func (r *archiveRepository) MoveToTable(archive *models.InteractionArchive, rows []models.ArchivedInteraction) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	updated := *archive
+	ids := make([]uint, len(rows))
+	for i := range rows {
+		ids[i] = rows[i].ID
+		if rows[i].ID > updated.MaxID {
+			updated.MaxID = rows[i].ID
+		}
+	}
+	updated.Rows += int64(len(rows))
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(&updated).Error; err != nil {
+			return err
+		}
+		for i := range rows {
+			rows[i].ArchiveID = updated.ID
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			CreateInBatches(rows, 200).Error; err != nil {
+			return err
+		}
+		return tx.Where("id IN ?", ids).Delete(&models.UserInteraction{}).Error
+	})
+	if err != nil {
+		return err
+	}
+	*archive = updated
+	return nil
+}
+
+// DeleteInteractions remove interações de user_interactions
+func (r *archiveRepository) DeleteInteractions(ids []uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return r.db.Where("id IN ?", ids).Delete(&models.UserInteraction{}).Error
+}
+
+// ListArchivedRows busca as interações de um arquivamento em ordem de ID
+func (r *archiveRepository) ListArchivedRows(archiveID, afterID uint, limit int) ([]models.ArchivedInteraction, error) {
+	var rows []models.ArchivedInteraction
+	if err := r.db.Where("archive_id = ? AND id > ?", archiveID, afterID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// RestoreRows devolve interações a user_interactions com os IDs originais e
+// remove as linhas correspondentes de archived_interactions. Interações que já
+// existirem são ignoradas, então a restauração pode ser repetida com segurança.
+func (r *archiveRepository) RestoreRows(interactions []models.UserInteraction, archivedIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if len(interactions) > 0 {
+			if err := tx.Omit("User", "Content").
+				Clauses(clause.OnConflict{DoNothing: true}).
+				CreateInBatches(interactions, 200).Error; err != nil {
+				return err
+			}
+		}
+		if len(archivedIDs) > 0 {
+			if err := tx.Where("id IN ?", archivedIDs).Delete(&models.ArchivedInteraction{}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"backend-go/models"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// BookmarkRepository define a interface para operações de conteúdos salvos
+type BookmarkRepository interface {
+	Delete(userID, contentID uint) error
+	ListByUserID(userID uint, limit, offset int) ([]models.Bookmark, int64, error)
+	GetBookmarkedIDs(userID uint, contentIDs []uint) ([]uint, error)
+}
+
+type bookmarkRepository struct {
+	db *gorm.DB
+}
+
+// NewBookmarkRepository cria uma nova instância do BookmarkRepository
+func NewBookmarkRepository(db *gorm.DB) BookmarkRepository {
+	return &bookmarkRepository{db: db}
+}
+
+// Delete remove um conteúdo salvo
+func (r *bookmarkRepository) Delete(userID, contentID uint) error {
+	result := r.db.Where("user_id = ? AND content_id = ?", userID, contentID).
+		Delete(&models.Bookmark{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("conteúdo não está salvo")
+	}
+	return nil
+}
+
+// ListByUserID busca os conteúdos salvos de um usuário com paginação
+// Retorna a lista de bookmarks (com conteúdo e categorias) e o total de registros
+func (r *bookmarkRepository) ListByUserID(userID uint, limit, offset int) ([]models.Bookmark, int64, error) {
+	var bookmarks []models.Bookmark
+	var total int64
+
+	query := r.db.Model(&models.Bookmark{}).Where("user_id = ?", userID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.
+		Preload("Content.Categories").
+		Limit(limit).
+		Offset(offset).
+		Order("created_at DESC").
+		Find(&bookmarks).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return bookmarks, total, nil
+}
+
+// GetBookmarkedIDs retorna, dentre os IDs informados, os conteúdos salvos pelo usuário
+func (r *bookmarkRepository) GetBookmarkedIDs(userID uint, contentIDs []uint) ([]uint, error) {
+	ids := []uint{}
+	if len(contentIDs) == 0 {
+		return ids, nil
+	}
+	if err := r.db.Model(&models.Bookmark{}).
+		Where("user_id = ? AND content_id IN ?", userID, contentIDs).
+		Pluck("content_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
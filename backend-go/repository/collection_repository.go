@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"backend-go/models"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// CollectionRepository define a interface para operações de coleções
+type CollectionRepository interface {
+	Create(collection *models.Collection, slug func(id uint) string) error
+	GetByID(id uint) (*models.Collection, error)
+	GetBySlug(slug string) (*models.Collection, error)
+	List(ownerID *uint, viewerID uint, editorialOnly bool, limit, offset int) ([]models.Collection, int64, error)
+	Update(collection *models.Collection) error
+	Delete(id uint) error
+	AddItem(item *models.CollectionItem) error
+	RemoveItem(collectionID, contentID uint) error
+	MaxPosition(collectionID uint) (int, error)
+	ReorderItems(collectionID uint, contentIDs []uint) error
+	GetEditorialContentIDs(limit int) ([]uint, error)
+}
+
+type collectionRepository struct {
+	db *gorm.DB
+}
+
+// NewCollectionRepository cria uma nova instância do CollectionRepository
+func NewCollectionRepository(db *gorm.DB) CollectionRepository {
+	return &collectionRepository{db: db}
+}
+
+// Create cria uma nova coleção com o slug gerado a partir do ID atribuído.
+// A inserção usa um slug provisório aleatório, trocado pelo definitivo na
+// mesma transação, para que nenhuma linha fique com slug vazio ou repetido.
+func (r *collectionRepository) Create(collection *models.Collection, slug func(id uint) string) error {
+	placeholder := make([]byte, 16)
+	if _, err := rand.Read(placeholder); err != nil {
+		return err
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		collection.Slug = "pending-" + hex.EncodeToString(placeholder)
+		if err := tx.Create(collection).Error; err != nil {
+			return err
+		}
+		collection.Slug = slug(collection.ID)
+		return tx.Model(collection).Update("slug", collection.Slug).Error
+	})
+}
+
+// GetByID busca uma coleção pelo ID com seus itens ordenados
+func (r *collectionRepository) GetByID(id uint) (*models.Collection, error) {
+	var collection models.Collection
+	if err := r.withItems(r.db).First(&collection, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("coleção não encontrada")
+		}
+		return nil, err
+	}
+	return &collection, nil
+}
+
+// GetBySlug busca uma coleção pelo slug com seus itens ordenados
+func (r *collectionRepository) GetBySlug(slug string) (*models.Collection, error) {
+	var collection models.Collection
+	if err := r.withItems(r.db).Where("slug = ?", slug).First(&collection).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("coleção não encontrada")
+		}
+		return nil, err
+	}
+	return &collection, nil
+}
+
+// List busca coleções visíveis ao usuário com paginação.
+// Coleções privadas só são retornadas para o próprio dono.
+func (r *collectionRepository) List(ownerID *uint, viewerID uint, editorialOnly bool, limit, offset int) ([]models.Collection, int64, error) {
+	var collections []models.Collection
+	var total int64
+
+	query := r.db.Model(&models.Collection{}).
+		Where("visibility = ? OR owner_id = ?", models.CollectionPublic, viewerID)
+
+	if ownerID != nil {
+		query = query.Where("owner_id = ?", *ownerID)
+	}
+	if editorialOnly {
+		query = query.Where("editorial = ?", true)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.
+		Limit(limit).
+		Offset(offset).
+		Order("updated_at DESC").
+		Find(&collections).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return collections, total, nil
+}
+
+// Update atualiza uma coleção existente
+func (r *collectionRepository) Update(collection *models.Collection) error {
+	return r.db.Omit("Items").Save(collection).Error
+}
+
+// Delete remove uma coleção e seus itens
+func (r *collectionRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("collection_id = ?", id).Delete(&models.CollectionItem{}).Error; err != nil {
+			return err
+		}
+		result := tx.Delete(&models.Collection{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errors.New("coleção não encontrada")
+		}
+		return nil
+	})
+}
+
+// AddItem adiciona um conteúdo a uma coleção
+func (r *collectionRepository) AddItem(item *models.CollectionItem) error {
+	return r.db.Create(item).Error
+}
+
+// RemoveItem remove um conteúdo de uma coleção
+func (r *collectionRepository) RemoveItem(collectionID, contentID uint) error {
+	result := r.db.Where("collection_id = ? AND content_id = ?", collectionID, contentID).
+		Delete(&models.CollectionItem{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("conteúdo não está na coleção")
+	}
+	return nil
+}
+
+// MaxPosition retorna a maior posição ocupada numa coleção (0 se vazia)
+func (r *collectionRepository) MaxPosition(collectionID uint) (int, error) {
+	var max int
+	if err := r.db.Model(&models.CollectionItem{}).
+		Where("collection_id = ?", collectionID).
+		Select("COALESCE(MAX(position), 0)").
+		Scan(&max).Error; err != nil {
+		return 0, err
+	}
+	return max, nil
+}
+
+// ReorderItems regrava as posições dos itens na ordem informada
+func (r *collectionRepository) ReorderItems(collectionID uint, contentIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i, contentID := range contentIDs {
+			if err := tx.Model(&models.CollectionItem{}).
+				Where("collection_id = ? AND content_id = ?", collectionID, contentID).
+				Update("position", i+1).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetEditorialContentIDs retorna conteúdos de coleções editoriais públicas,
+// das coleções mais recentes para as mais antigas e respeitando a ordem de cada uma
+func (r *collectionRepository) GetEditorialContentIDs(limit int) ([]uint, error) {
+	var ids []uint
+	if err := r.db.Model(&models.CollectionItem{}).
+		Select("collection_items.content_id").
+		Joins("JOIN collections ON collections.id = collection_items.collection_id").
+		Joins("JOIN contents ON contents.id = collection_items.content_id").
+		Where("collections.editorial = ? AND collections.visibility = ? AND contents.hidden = ?", true, models.CollectionPublic, false).
+		Order("collections.updated_at DESC, collection_items.position ASC").
+		Limit(limit).
+		Pluck("collection_items.content_id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func (r *collectionRepository) withItems(db *gorm.DB) *gorm.DB {
+	return db.Preload("Items", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC")
+	}).Preload("Items.Content.Categories")
+}
@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"backend-go/models"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// CommentRepository define a interface para operações de comentários
+type CommentRepository interface {
+	Create(comment *models.Comment) error
+	GetByID(id uint) (*models.Comment, error)
+	ListRootsByContentID(contentID uint, limit, offset int) ([]models.Comment, int64, error)
+	ListByRootIDs(rootIDs []uint) ([]models.Comment, error)
+	CountByContentID(contentID uint) (int64, error)
+	Update(comment *models.Comment) error
+}
+
+type commentRepository struct {
+	db *gorm.DB
+}
+
+// NewCommentRepository cria uma nova instância do CommentRepository
+func NewCommentRepository(db *gorm.DB) CommentRepository {
+	return &commentRepository{db: db}
+}
+
+// Create cria um novo comentário no banco de dados
+func (r *commentRepository) Create(comment *models.Comment) error {
+	return r.db.Create(comment).Error
+}
+
+// GetByID busca um comentário pelo ID
+func (r *commentRepository) GetByID(id uint) (*models.Comment, error) {
+	var comment models.Comment
+	if err := r.db.First(&comment, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("comentário não encontrado")
+		}
+		return nil, err
+	}
+	return &comment, nil
+}
+
+// ListRootsByContentID busca os comentários de primeiro nível de um conteúdo com paginação
+// Retorna a lista de comentários e o total de threads
+func (r *commentRepository) ListRootsByContentID(contentID uint, limit, offset int) ([]models.Comment, int64, error) {
+	var comments []models.Comment
+	var total int64
+
+	query := r.db.Model(&models.Comment{}).
+		Where("content_id = ? AND parent_id IS NULL", contentID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.
+		Limit(limit).
+		Offset(offset).
+		Order("created_at DESC").
+		Find(&comments).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return comments, total, nil
+}
+
+// ListByRootIDs busca todas as respostas das threads informadas, em ordem cronológica
+func (r *commentRepository) ListByRootIDs(rootIDs []uint) ([]models.Comment, error) {
+	var comments []models.Comment
+	if len(rootIDs) == 0 {
+		return comments, nil
+	}
+	if err := r.db.Where("root_id IN ?", rootIDs).
+		Order("created_at ASC").
+		Find(&comments).Error; err != nil {
+		return nil, err
+	}
+	return comments, nil
+}
+
+// CountByContentID conta os comentários não removidos de um conteúdo
+func (r *commentRepository) CountByContentID(contentID uint) (int64, error) {
+	var count int64
+	if err := r.db.Model(&models.Comment{}).
+		Where("content_id = ? AND deleted = ?", contentID, false).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Update atualiza um comentário existente
+func (r *commentRepository) Update(comment *models.Comment) error {
+	return r.db.Save(comment).Error
+}
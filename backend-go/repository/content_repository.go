@@ -3,17 +3,39 @@ package repository
 import (
 	"backend-go/models"
 	"errors"
+	"time"
 
 	"gorm.io/gorm"
 )
 
 // ContentRepository define a interface para operações de conteúdo
 type ContentRepository interface {
-	Create(content *models.Content) error
+	Create(content *models.Content, outbox OutboxFunc) error
 	GetByID(id uint) (*models.Content, error)
 	GetAll(limit, offset int, contentType *string) ([]models.Content, int64, error)
-	Update(content *models.Content) error
-	Delete(id uint) error
+	Update(content *models.Content, outbox OutboxFunc) error
+	Delete(id uint, outbox OutboxFunc) error
+	SetHidden(id uint, hidden bool, reason string) error
+	HideIfVisible(id uint, reason string) (bool, error)
+	UnhideIfReason(id uint, reason string) (bool, error)
+	GetHiddenIDs(ids []uint) ([]uint, error)
+	GetExistingIDs(ids []uint) ([]uint, error)
+	GetByIDs(ids []uint, withCategories bool) ([]models.Content, error)
+	FilterIDs(ids []uint, filter ContentFilter) ([]uint, error)
+}
+
+// ContentFilter restringe uma lista de conteúdos candidatos
+type ContentFilter struct {
+	Types       []string
+	CategoryIDs []uint
+	// Só conteúdos lançados até o instante informado
+	ReleasedBy *time.Time
+	// Usuário cujas interações excluem conteúdos
+	UserID          uint
+	ExcludeViewed   bool
+	ExcludeDisliked bool
+	// Exclui os conteúdos com qualquer interação do usuário
+	ExcludeConsumed bool
 }
 
 type contentRepository struct {
@@ -25,9 +47,15 @@ func NewContentRepository(db *gorm.DB) ContentRepository {
 	return &contentRepository{db: db}
 }
 
-// Create cria um novo conteúdo no banco de dados
-func (r *contentRepository) Create(content *models.Content) error {
-	return r.db.Create(content).Error
+// Create cria um novo conteúdo no banco de dados e grava os eventos do
+// outbox na mesma transação
+func (r *contentRepository) Create(content *models.Content, outbox OutboxFunc) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(content).Error; err != nil {
+			return err
+		}
+		return insertOutbox(tx, outbox)
+	})
 }
 
 // GetByID busca um conteúdo pelo ID com seus relacionamentos
@@ -37,7 +65,7 @@ func (r *contentRepository) GetByID(id uint) (*models.Content, error) {
 		Preload("Interactions").
 		First(&content, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("conteúdo não encontrado")
+			return nil, ErrContentNotFound
 		}
 		return nil, err
 	}
@@ -75,19 +103,148 @@ func (r *contentRepository) GetAll(limit, offset int, contentType *string) ([]mo
 	return contents, total, nil
 }
 
-// Update atualiza um conteúdo existente
-func (r *contentRepository) Update(content *models.Content) error {
-	return r.db.Save(content).Error
+// Update atualiza um conteúdo existente e grava os eventos do outbox na
+// mesma transação
+func (r *contentRepository) Update(content *models.Content, outbox OutboxFunc) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(content).Error; err != nil {
+			return err
+		}
+		return insertOutbox(tx, outbox)
+	})
 }
 
-// Delete remove um conteúdo do banco de dados
-func (r *contentRepository) Delete(id uint) error {
-	result := r.db.Delete(&models.Content{}, id)
+// Delete remove um conteúdo do banco de dados e grava os eventos do outbox
+// na mesma transação
+func (r *contentRepository) Delete(id uint, outbox OutboxFunc) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Delete(&models.Content{}, id)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrContentNotFound
+		}
+		return insertOutbox(tx, outbox)
+	})
+}
+
+// SetHidden marca (com o motivo) ou desmarca um conteúdo como oculto das recomendações
+func (r *contentRepository) SetHidden(id uint, hidden bool, reason string) error {
+	var hiddenReason *string
+	if hidden {
+		hiddenReason = &reason
+	}
+	result := r.db.Model(&models.Content{}).Where("id = ?", id).
+		Updates(map[string]interface{}{"hidden": hidden, "hidden_reason": hiddenReason})
 	if result.Error != nil {
 		return result.Error
 	}
 	if result.RowsAffected == 0 {
-		return errors.New("conteúdo não encontrado")
+		var count int64
+		if err := r.db.Model(&models.Content{}).Where("id = ?", id).Count(&count).Error; err != nil {
+			return err
+		}
+		if count == 0 {
+			return ErrContentNotFound
+		}
 	}
 	return nil
 }
+
+// HideIfVisible oculta o conteúdo com o motivo informado se ele ainda estiver
+// visível. Retorna false se ele já estava oculto.
+func (r *contentRepository) HideIfVisible(id uint, reason string) (bool, error) {
+	result := r.db.Model(&models.Content{}).
+		Where("id = ? AND hidden = ?", id, false).
+		Updates(map[string]interface{}{"hidden": true, "hidden_reason": reason})
+	return result.RowsAffected > 0, result.Error
+}
+
+// UnhideIfReason volta a exibir o conteúdo só se ele foi ocultado pelo motivo
+// informado. Retorna false se ele está visível ou foi ocultado por outro motivo.
+func (r *contentRepository) UnhideIfReason(id uint, reason string) (bool, error) {
+	result := r.db.Model(&models.Content{}).
+		Where("id = ? AND hidden = ? AND hidden_reason = ?", id, true, reason).
+		Updates(map[string]interface{}{"hidden": false, "hidden_reason": nil})
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetHiddenIDs retorna, dentre os IDs informados, os conteúdos que estão ocultos
+func (r *contentRepository) GetHiddenIDs(ids []uint) ([]uint, error) {
+	hidden := []uint{}
+	if len(ids) == 0 {
+		return hidden, nil
+	}
+	if err := r.db.Model(&models.Content{}).
+		Where("id IN ? AND hidden = ?", ids, true).
+		Pluck("id", &hidden).Error; err != nil {
+		return nil, err
+	}
+	return hidden, nil
+}
+
+// GetExistingIDs retorna, dentre os IDs informados, os conteúdos que existem
+func (r *contentRepository) GetExistingIDs(ids []uint) ([]uint, error) {
+	existing := []uint{}
+	if len(ids) == 0 {
+		return existing, nil
+	}
+	if err := r.db.Model(&models.Content{}).
+		Where("id IN ?", ids).
+		Pluck("id", &existing).Error; err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+// GetByIDs busca os conteúdos informados em uma única consulta, com as
+// categorias quando pedidas. A ordem do resultado não segue a de ids.
+func (r *contentRepository) GetByIDs(ids []uint, withCategories bool) ([]models.Content, error) {
+	contents := []models.Content{}
+	if len(ids) == 0 {
+		return contents, nil
+	}
+	query := r.db.Where("id IN ?", ids)
+	if withCategories {
+		query = query.Preload("Categories")
+	}
+	if err := query.Find(&contents).Error; err != nil {
+		return nil, err
+	}
+	return contents, nil
+}
+
+// FilterIDs retorna, dentre os IDs informados, os conteúdos visíveis que
+// atendem ao filtro. A ordem do resultado não é a dos IDs.
+func (r *contentRepository) FilterIDs(ids []uint, filter ContentFilter) ([]uint, error) {
+	allowed := []uint{}
+	if len(ids) == 0 {
+		return allowed, nil
+	}
+
+	query := r.db.Model(&models.Content{}).Where("id IN ? AND hidden = ?", ids, false)
+	if len(filter.Types) > 0 {
+		query = query.Where("type IN ?", filter.Types)
+	}
+	if len(filter.CategoryIDs) > 0 {
+		query = query.Where("id IN (SELECT content_id FROM content_categories WHERE category_id IN ?)", filter.CategoryIDs)
+	}
+	if filter.ReleasedBy != nil {
+		query = query.Where("release_date <= ?", *filter.ReleasedBy)
+	}
+	if filter.ExcludeViewed {
+		query = query.Where("id NOT IN (SELECT content_id FROM user_interactions WHERE user_id = ? AND interaction_type = ?)", filter.UserID, "view")
+	}
+	if filter.ExcludeDisliked {
+		query = query.Where("id NOT IN (SELECT content_id FROM user_reactions WHERE user_id = ? AND reaction = ?)", filter.UserID, "dislike")
+	}
+	if filter.ExcludeConsumed {
+		query = query.Where("id NOT IN (SELECT content_id FROM user_interactions WHERE user_id = ?)", filter.UserID)
+	}
+
+	if err := query.Pluck("id", &allowed).Error; err != nil {
+		return nil, err
+	}
+	return allowed, nil
+}
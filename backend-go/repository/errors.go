@@ -0,0 +1,9 @@
+package repository
+
+import "errors"
+
+// Erros de registro inexistente, comparáveis com errors.Is
+var (
+	ErrUserNotFound    = errors.New("usuário não encontrado")
+	ErrContentNotFound = errors.New("conteúdo não encontrado")
+)
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"backend-go/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IdempotencyRepository define a interface para as chaves de idempotência
+type IdempotencyRepository interface {
+	Get(scope, key string) (*models.IdempotencyKey, error)
+	Create(record *models.IdempotencyKey) (bool, error)
+	Complete(scope, key string, statusCode int, body []byte) error
+	Delete(scope, key string) error
+	DeleteExpired(now time.Time) (int64, error)
+}
+
+type idempotencyRepository struct {
+	db *gorm.DB
+}
+
+// NewIdempotencyRepository cria uma nova instância do IdempotencyRepository
+func NewIdempotencyRepository(db *gorm.DB) IdempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
+
+// Get busca uma chave de idempotência; retorna nil se ela não existir
+func (r *idempotencyRepository) Get(scope, key string) (*models.IdempotencyKey, error) {
+	var record models.IdempotencyKey
+	if err := r.db.Where("scope = ? AND request_key = ?", scope, key).First(&record).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &record, nil
+}
+
+// Create reserva uma chave. Retorna false se outra requisição já a reservou.
+func (r *idempotencyRepository) Create(record *models.IdempotencyKey) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(record)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Complete grava a resposta original associada à chave
+func (r *idempotencyRepository) Complete(scope, key string, statusCode int, body []byte) error {
+	return r.db.Model(&models.IdempotencyKey{}).
+		Where("scope = ? AND request_key = ?", scope, key).
+		Updates(map[string]interface{}{
+			"status_code":   statusCode,
+			"response_body": body,
+		}).Error
+}
+
+// Delete libera uma chave de idempotência
+func (r *idempotencyRepository) Delete(scope, key string) error {
+	return r.db.Where("scope = ? AND request_key = ?", scope, key).
+		Delete(&models.IdempotencyKey{}).Error
+}
+
+// DeleteExpired remove as chaves cuja janela de retenção já passou
+func (r *idempotencyRepository) DeleteExpired(now time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ?", now).Delete(&models.IdempotencyKey{})
+	return result.RowsAffected, result.Error
+}
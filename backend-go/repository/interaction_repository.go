@@ -2,17 +2,55 @@ package repository
 
 import (
 	"backend-go/models"
+	"errors"
+	"sort"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // InteractionRepository define a interface para operações de interações
 type InteractionRepository interface {
 	Create(interaction *models.UserInteraction) error
-	GetByUserID(userID uint) ([]models.UserInteraction, error)
-	GetByContentID(contentID uint) ([]models.UserInteraction, error)
+	CreateWithReactions(interactions []*models.UserInteraction, reactions []ReactionUpdate, outbox OutboxFunc, related ...interface{}) error
+	GetReaction(userID, contentID uint) (*models.UserReaction, error)
+	GetExistingClientEventIDs(userID uint, clientEventIDs []string) ([]string, error)
+	ListByUserID(userID uint, filter InteractionFilter) ([]models.UserInteraction, int64, error)
+	ListByContentID(contentID uint, filter InteractionFilter) ([]models.UserInteraction, int64, error)
+	ListFlagged(reason string, filter InteractionFilter) ([]models.UserInteraction, int64, error)
 	GetByUserAndContent(userID, contentID uint) (*models.UserInteraction, error)
 	CountByUserID(userID uint) (int64, error)
+	CountByUserSince(userID uint, since time.Time) (int64, error)
+	CountRatingsSince(userID, contentID uint, since time.Time) (int64, error)
+	GetRatingCountsSince(contentID uint, since time.Time, low, high float64) (RatingCounts, error)
+}
+
+// RatingCounts resume as notas recebidas por um conteúdo em uma janela de tempo
+type RatingCounts struct {
+	Total int64
+	Low   int64
+	High  int64
+}
+
+// ReactionUpdate recalcula o estado da reação de um usuário a um conteúdo.
+// Apply recebe o estado atual, lido com bloqueio dentro da transação (ou
+// vazio, se o usuário nunca reagiu), e indica se ele foi alterado.
+type ReactionUpdate struct {
+	UserID    uint
+	ContentID uint
+	Apply     func(state *models.UserReaction) bool
+}
+
+// InteractionFilter restringe e pagina consultas ao histórico de interações
+type InteractionFilter struct {
+	From      *time.Time
+	To        *time.Time
+	Types     []string
+	MinRating *float64
+	Limit     int
+	Offset    int
+	Preload   bool
 }
 
 type interactionRepository struct {
@@ -29,28 +67,163 @@ func (r *interactionRepository) Create(interaction *models.UserInteraction) erro
 	return r.db.Create(interaction).Error
 }
 
-// GetByUserID busca todas as interações de um usuário
-func (r *interactionRepository) GetByUserID(userID uint) ([]models.UserInteraction, error) {
-	var interactions []models.UserInteraction
-	if err := r.db.Where("user_id = ?", userID).
-		Preload("Content").
-		Order("created_at DESC").
-		Find(&interactions).Error; err != nil {
+// CreateWithReactions insere as interações, atualiza o estado das reações e
+// enfileira os eventos do outbox numa única transação, mantendo log, estado e
+// notificações consistentes. Os registros em related (ex.: o comentário que
+// gerou a interação) são inseridos antes, na mesma transação.
+// Cada reação é lida com SELECT ... FOR UPDATE e atualizada na mesma
+// transação, então requisições concorrentes para o mesmo par não sobrescrevem
+// umas às outras. Os pares são bloqueados em ordem para evitar deadlock.
+func (r *interactionRepository) CreateWithReactions(interactions []*models.UserInteraction, reactions []ReactionUpdate, outbox OutboxFunc, related ...interface{}) error {
+	if len(interactions) == 0 {
+		return nil
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, record := range related {
+			if err := tx.Omit(clause.Associations).Create(record).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.CreateInBatches(interactions, 100).Error; err != nil {
+			return err
+		}
+		sorted := append([]ReactionUpdate(nil), reactions...)
+		sort.Slice(sorted, func(i, j int) bool {
+			if sorted[i].UserID != sorted[j].UserID {
+				return sorted[i].UserID < sorted[j].UserID
+			}
+			return sorted[i].ContentID < sorted[j].ContentID
+		})
+		for _, update := range sorted {
+			if err := applyReactionUpdate(tx, update); err != nil {
+				return err
+			}
+		}
+		return insertOutbox(tx, outbox)
+	})
+}
+
+// applyReactionUpdate bloqueia a reação, aplica a atualização e grava o
+// resultado. Se outra transação criar a reação entre a leitura e a inserção,
+// a atualização é reaplicada sobre o estado gravado por ela.
+func applyReactionUpdate(tx *gorm.DB, update ReactionUpdate) error {
+	for attempt := 0; attempt < 2; attempt++ {
+		var state models.UserReaction
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND content_id = ?", update.UserID, update.ContentID).
+			First(&state).Error
+		if err == nil {
+			if !update.Apply(&state) {
+				return nil
+			}
+			return tx.Omit(clause.Associations).Save(&state).Error
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		state = models.UserReaction{UserID: update.UserID, ContentID: update.ContentID}
+		if !update.Apply(&state) {
+			return nil
+		}
+		result := tx.Omit(clause.Associations).Clauses(clause.OnConflict{DoNothing: true}).Create(&state)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected > 0 {
+			return nil
+		}
+	}
+	return errors.New("conflito ao atualizar a reação")
+}
+
+// GetReaction busca o estado atual da reação de um usuário a um conteúdo.
+// Retorna nil se o usuário nunca reagiu ao conteúdo.
+func (r *interactionRepository) GetReaction(userID, contentID uint) (*models.UserReaction, error) {
+	var reaction models.UserReaction
+	if err := r.db.Where("user_id = ? AND content_id = ?", userID, contentID).
+		First(&reaction).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &reaction, nil
+}
+
+// GetExistingClientEventIDs retorna, dentre os IDs de evento do cliente informados,
+// os que já foram registrados para o usuário
+func (r *interactionRepository) GetExistingClientEventIDs(userID uint, clientEventIDs []string) ([]string, error) {
+	existing := []string{}
+	if len(clientEventIDs) == 0 {
+		return existing, nil
+	}
+	if err := r.db.Model(&models.UserInteraction{}).
+		Where("user_id = ? AND client_event_id IN ?", userID, clientEventIDs).
+		Pluck("client_event_id", &existing).Error; err != nil {
 		return nil, err
 	}
-	return interactions, nil
+	return existing, nil
+}
+
+// ListByUserID busca as interações de um usuário com filtros e paginação
+// Retorna a lista de interações e o total de registros (com filtros aplicados)
+func (r *interactionRepository) ListByUserID(userID uint, filter InteractionFilter) ([]models.UserInteraction, int64, error) {
+	return r.list(r.db.Where("user_id = ?", userID), "Content", filter)
+}
+
+// ListByContentID busca as interações de um conteúdo com filtros e paginação
+// Retorna a lista de interações e o total de registros (com filtros aplicados)
+func (r *interactionRepository) ListByContentID(contentID uint, filter InteractionFilter) ([]models.UserInteraction, int64, error) {
+	return r.list(r.db.Where("content_id = ?", contentID), "User", filter)
+}
+
+// ListFlagged busca as interações sinalizadas como suspeitas, opcionalmente
+// filtradas pelo motivo
+func (r *interactionRepository) ListFlagged(reason string, filter InteractionFilter) ([]models.UserInteraction, int64, error) {
+	query := r.db.Where("flagged = ?", true)
+	if reason != "" {
+		query = query.Where("flag_reason = ?", reason)
+	}
+	return r.list(query, "Content", filter)
 }
 
-// GetByContentID busca todas as interações de um conteúdo
-func (r *interactionRepository) GetByContentID(contentID uint) ([]models.UserInteraction, error) {
+func (r *interactionRepository) list(query *gorm.DB, preload string, filter InteractionFilter) ([]models.UserInteraction, int64, error) {
 	var interactions []models.UserInteraction
-	if err := r.db.Where("content_id = ?", contentID).
-		Preload("User").
+	var total int64
+
+	query = query.Model(&models.UserInteraction{})
+
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	if len(filter.Types) > 0 {
+		query = query.Where("interaction_type IN ?", filter.Types)
+	}
+	if filter.MinRating != nil {
+		query = query.Where("rating >= ?", *filter.MinRating)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if filter.Preload {
+		query = query.Preload(preload)
+	}
+
+	if err := query.
+		Limit(filter.Limit).
+		Offset(filter.Offset).
 		Order("created_at DESC").
 		Find(&interactions).Error; err != nil {
-		return nil, err
+		return nil, 0, err
 	}
-	return interactions, nil
+
+	return interactions, total, nil
 }
 
 // GetByUserAndContent busca uma interação específica de um usuário com um conteúdo
@@ -75,3 +248,41 @@ func (r *interactionRepository) CountByUserID(userID uint) (int64, error) {
 	return count, nil
 }
 
+// CountByUserSince conta as interações de um usuário recebidas a partir de since
+func (r *interactionRepository) CountByUserSince(userID uint, since time.Time) (int64, error) {
+	var count int64
+	if err := r.db.Model(&models.UserInteraction{}).
+		Where("user_id = ? AND received_at >= ?", userID, since).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// CountRatingsSince conta quantas notas do usuário para o conteúdo foram
+// recebidas a partir de since
+func (r *interactionRepository) CountRatingsSince(userID, contentID uint, since time.Time) (int64, error) {
+	var count int64
+	if err := r.db.Model(&models.UserInteraction{}).
+		Where("user_id = ? AND content_id = ? AND interaction_type = ? AND received_at >= ?", userID, contentID, "rating", since).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetRatingCountsSince conta as notas do conteúdo recebidas a partir de since, separando
+// as notas extremas (até low e a partir de high)
+func (r *interactionRepository) GetRatingCountsSince(contentID uint, since time.Time, low, high float64) (RatingCounts, error) {
+	var counts RatingCounts
+	err := r.db.Model(&models.UserInteraction{}).
+		Select(
+			"COUNT(*) AS total, "+
+				"COALESCE(SUM(CASE WHEN rating <= ? THEN 1 ELSE 0 END), 0) AS low, "+
+				"COALESCE(SUM(CASE WHEN rating >= ? THEN 1 ELSE 0 END), 0) AS high",
+			low, high,
+		).
+		Where("content_id = ? AND interaction_type = ? AND received_at >= ?", contentID, "rating", since).
+		Scan(&counts).Error
+	return counts, err
+}
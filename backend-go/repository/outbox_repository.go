@@ -0,0 +1,196 @@
+package repository
+
+import (
+	"backend-go/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// OutboxRepository define a interface para os eventos do outbox
+type OutboxRepository interface {
+	ListDue(topic string, now time.Time, limit int) ([]models.OutboxEvent, error)
+	MarkDelivered(ids []uint, at time.Time) error
+	Update(event *models.OutboxEvent) error
+	GetByID(id uint) (*models.OutboxEvent, error)
+	ListByStatus(status string, limit, offset int) ([]models.OutboxEvent, int64, error)
+	Stats() (*OutboxStats, error)
+	PurgeDelivered(before time.Time) (int64, error)
+}
+
+// OutboxStats resume o estado do outbox
+type OutboxStats struct {
+	Pending         int64
+	Dead            int64
+	Delivered       int64
+	OldestPendingAt *time.Time
+}
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+// NewOutboxRepository cria uma nova instância do OutboxRepository
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &outboxRepository{db: db}
+}
+
+// OutboxFunc monta os eventos do outbox de uma escrita. É chamada dentro da
+// transação, depois dos registros inseridos, quando os IDs gerados já estão
+// disponíveis para o payload.
+type OutboxFunc func() ([]*models.OutboxEvent, error)
+
+// insertOutbox monta os eventos com outbox, se informada, e os grava na transação
+func insertOutbox(tx *gorm.DB, outbox OutboxFunc) error {
+	if outbox == nil {
+		return nil
+	}
+	events, err := outbox()
+	if err != nil {
+		return err
+	}
+	return insertOutboxEvents(tx, events)
+}
+
+// insertOutboxEvents grava eventos do outbox na transação da escrita que os
+// originou, para que sejam confirmados ou desfeitos junto com ela
+func insertOutboxEvents(tx *gorm.DB, events []*models.OutboxEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	now := time.Now()
+	for _, event := range events {
+		if event.Status == "" {
+			event.Status = models.OutboxStatusPending
+		}
+		if event.NextAttemptAt.IsZero() {
+			event.NextAttemptAt = now
+		}
+	}
+	return tx.CreateInBatches(events, 100).Error
+}
+
+// ListDue busca os eventos pendentes do tópico cuja próxima tentativa já venceu,
+// em ordem de criação. Um evento fica retido enquanto um evento anterior do
+// mesmo agregado ainda estiver pendente aguardando o backoff, para que, por
+// exemplo, um unlike não seja entregue antes do like que ele desfaz.
+func (r *outboxRepository) ListDue(topic string, now time.Time, limit int) ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	if err := r.db.Where("status = ? AND next_attempt_at <= ? AND topic = ?", models.OutboxStatusPending, now, topic).
+		Where("aggregate_key = '' OR NOT EXISTS (?)",
+			r.db.Table("outbox_events AS earlier").
+				Select("1").
+				Where("earlier.topic = outbox_events.topic AND earlier.aggregate_key = outbox_events.aggregate_key").
+				Where("earlier.id < outbox_events.id AND earlier.status = ? AND earlier.next_attempt_at > ?", models.OutboxStatusPending, now),
+		).
+		Order("id ASC").
+		Limit(limit).
+		Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// MarkDelivered marca os eventos como entregues
+func (r *outboxRepository) MarkDelivered(ids []uint, at time.Time) error {
+	return markOutboxDelivered(r.db, ids, at)
+}
+
+func markOutboxDelivered(tx *gorm.DB, ids []uint, at time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return tx.Model(&models.OutboxEvent{}).
+		Where("id IN ?", ids).
+		Updates(map[string]interface{}{
+			"status":       models.OutboxStatusDelivered,
+			"delivered_at": at,
+			"last_error":   nil,
+		}).Error
+}
+
+// Update grava o estado de um evento (tentativas, próxima tentativa, erro)
+func (r *outboxRepository) Update(event *models.OutboxEvent) error {
+	return r.db.Save(event).Error
+}
+
+// GetByID busca um evento do outbox por ID
+func (r *outboxRepository) GetByID(id uint) (*models.OutboxEvent, error) {
+	var event models.OutboxEvent
+	if err := r.db.First(&event, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("evento do outbox não encontrado")
+		}
+		return nil, err
+	}
+	return &event, nil
+}
+
+// ListByStatus busca os eventos com o status informado, dos mais recentes para
+// os mais antigos; status vazio lista todos
+func (r *outboxRepository) ListByStatus(status string, limit, offset int) ([]models.OutboxEvent, int64, error) {
+	var events []models.OutboxEvent
+	var total int64
+
+	query := r.db.Model(&models.OutboxEvent{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Order("id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&events).Error; err != nil {
+		return nil, 0, err
+	}
+	return events, total, nil
+}
+
+// Stats conta os eventos por status e busca o pendente mais antigo
+func (r *outboxRepository) Stats() (*OutboxStats, error) {
+	var counts []struct {
+		Status string
+		Total  int64
+	}
+	if err := r.db.Model(&models.OutboxEvent{}).
+		Select("status, COUNT(*) AS total").
+		Group("status").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+
+	stats := &OutboxStats{}
+	for _, count := range counts {
+		switch count.Status {
+		case models.OutboxStatusPending:
+			stats.Pending = count.Total
+		case models.OutboxStatusDead:
+			stats.Dead = count.Total
+		case models.OutboxStatusDelivered:
+			stats.Delivered = count.Total
+		}
+	}
+
+	var oldest models.OutboxEvent
+	if err := r.db.Select("id", "created_at").
+		Where("status = ?", models.OutboxStatusPending).
+		Order("id ASC").
+		First(&oldest).Error; err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+	} else {
+		stats.OldestPendingAt = &oldest.CreatedAt
+	}
+	return stats, nil
+}
+
+// PurgeDelivered remove os eventos entregues antes do instante informado
+func (r *outboxRepository) PurgeDelivered(before time.Time) (int64, error) {
+	result := r.db.Where("status = ? AND delivered_at < ?", models.OutboxStatusDelivered, before).
+		Delete(&models.OutboxEvent{})
+	return result.RowsAffected, result.Error
+}
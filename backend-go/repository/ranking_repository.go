@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"backend-go/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RankingRepository define a interface para os rankings não personalizados
+// usados quando o motor de recomendação está indisponível
+type RankingRepository interface {
+	PopularForUser(userID uint, from, to time.Time, weights map[string]float64, limit int) ([]RankedContent, error)
+	RecentForUser(userID uint, limit int) ([]RankedContent, error)
+}
+
+// RankedContent é um conteúdo candidato com sua pontuação e data de lançamento
+type RankedContent struct {
+	ContentID   uint
+	Score       float64
+	ReleaseDate time.Time
+}
+
+type rankingRepository struct {
+	db *gorm.DB
+}
+
+// NewRankingRepository cria uma nova instância do RankingRepository
+func NewRankingRepository(db *gorm.DB) RankingRepository {
+	return &rankingRepository{db: db}
+}
+
+// PopularForUser retorna os conteúdos mais populares no período pelos
+// rollups diários, ignorando os ocultos e os que o usuário já consumiu
+func (r *rankingRepository) PopularForUser(userID uint, from, to time.Time, weights map[string]float64, limit int) ([]RankedContent, error) {
+	var ranked []RankedContent
+
+	scoreExpr, scoreArgs := weightedScoreExpr(weights)
+	if err := r.db.Model(&models.ContentDailyStat{}).
+		Select("content_daily_stats.content_id, MAX(contents.release_date) AS release_date, "+scoreExpr+" AS score", scoreArgs...).
+		Joins("JOIN contents ON contents.id = content_daily_stats.content_id").
+		Where("day >= ? AND day < ?", from, to).
+		Where("contents.hidden = ?", false).
+		Where("content_daily_stats.content_id NOT IN (SELECT content_id FROM user_interactions WHERE user_id = ?)", userID).
+		Group("content_daily_stats.content_id").
+		Order("score DESC, content_daily_stats.content_id ASC").
+		Limit(limit).
+		Scan(&ranked).Error; err != nil {
+		return nil, err
+	}
+	return ranked, nil
+}
+
+// RecentForUser retorna os lançamentos mais recentes já disponíveis,
+// ignorando os ocultos e os que o usuário já consumiu
+func (r *rankingRepository) RecentForUser(userID uint, limit int) ([]RankedContent, error) {
+	var ranked []RankedContent
+
+	if err := r.db.Model(&models.Content{}).
+		Select("id AS content_id, release_date").
+		Where("hidden = ? AND release_date <= ?", false, time.Now()).
+		Where("id NOT IN (SELECT content_id FROM user_interactions WHERE user_id = ?)", userID).
+		Order("release_date DESC, id ASC").
+		Limit(limit).
+		Scan(&ranked).Error; err != nil {
+		return nil, err
+	}
+	return ranked, nil
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"backend-go/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RecommendationRepository define a interface para o histórico de recomendações servidas
+type RecommendationRepository interface {
+	Create(recommendation *models.Recommendation, outbox OutboxFunc) error
+	GetByID(id uint) (*models.Recommendation, error)
+	ListByUserID(userID uint, from, to *time.Time, limit, offset int) ([]models.Recommendation, int64, error)
+	PurgeBefore(before time.Time) (int64, error)
+}
+
+type recommendationRepository struct {
+	db *gorm.DB
+}
+
+// NewRecommendationRepository cria uma nova instância do RecommendationRepository
+func NewRecommendationRepository(db *gorm.DB) RecommendationRepository {
+	return &recommendationRepository{db: db}
+}
+
+// Create grava uma lista servida e, na mesma transação, os eventos do outbox
+func (r *recommendationRepository) Create(recommendation *models.Recommendation, outbox OutboxFunc) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(recommendation).Error; err != nil {
+			return err
+		}
+		return insertOutbox(tx, outbox)
+	})
+}
+
+// GetByID busca uma recomendação pelo ID
+func (r *recommendationRepository) GetByID(id uint) (*models.Recommendation, error) {
+	var recommendation models.Recommendation
+	if err := r.db.First(&recommendation, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("recomendação não encontrada")
+		}
+		return nil, err
+	}
+	return &recommendation, nil
+}
+
+// ListByUserID busca as recomendações servidas a um usuário, das mais
+// recentes para as mais antigas, com paginação
+func (r *recommendationRepository) ListByUserID(userID uint, from, to *time.Time, limit, offset int) ([]models.Recommendation, int64, error) {
+	var recommendations []models.Recommendation
+	var total int64
+
+	query := r.db.Model(&models.Recommendation{}).Where("user_id = ?", userID)
+	if from != nil {
+		query = query.Where("created_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("created_at < ?", *to)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.
+		Limit(limit).
+		Offset(offset).
+		Order("created_at DESC, id DESC").
+		Find(&recommendations).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return recommendations, total, nil
+}
+
+// PurgeBefore remove as recomendações servidas antes do instante informado
+func (r *recommendationRepository) PurgeBefore(before time.Time) (int64, error) {
+	result := r.db.Where("created_at < ?", before).Delete(&models.Recommendation{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"backend-go/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ReportRepository define a interface para operações de denúncias e moderação
+type ReportRepository interface {
+	Create(report *models.ContentReport) error
+	GetByID(id uint) (*models.ContentReport, error)
+	List(status *string, limit, offset int) ([]models.ContentReport, int64, error)
+	CountOpenReportersByContentID(contentID uint) (int64, error)
+	Resolve(reportID uint, resolution ReportResolution) (*models.ContentReport, error)
+	CreateDecision(decision *models.ModerationDecision) error
+	ListDecisionsByContentID(contentID uint) ([]models.ModerationDecision, error)
+}
+
+var (
+	// ErrDuplicateReport indica que o usuário já denunciou o conteúdo
+	ErrDuplicateReport = errors.New("usuário já denunciou este conteúdo")
+	// ErrReportNotFound indica que a denúncia não existe
+	ErrReportNotFound = errors.New("denúncia não encontrada")
+	// ErrReportResolved indica que a denúncia já foi fechada por outro moderador
+	ErrReportResolved = errors.New("denúncia já foi revisada")
+)
+
+// ReportResolution descreve o fechamento de uma denúncia pelo moderador
+type ReportResolution struct {
+	Status string
+	// Decisão registrada; ContentID e ReportID são preenchidos por Resolve
+	Decision *models.ModerationDecision
+	// Oculta o conteúdo por decisão do moderador
+	Hide bool
+	// Volta a exibir o conteúdo ocultado pelas denúncias se restarem menos
+	// denunciantes com denúncia em aberto que isso; 0 não altera o conteúdo
+	UnhideBelow int
+}
+
+type reportRepository struct {
+	db *gorm.DB
+}
+
+// NewReportRepository cria uma nova instância do ReportRepository
+func NewReportRepository(db *gorm.DB) ReportRepository {
+	return &reportRepository{db: db}
+}
+
+// Create registra uma nova denúncia. Uma segunda denúncia do mesmo usuário
+// para o mesmo conteúdo (idx_content_report_user) retorna ErrDuplicateReport.
+func (r *reportRepository) Create(report *models.ContentReport) error {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(report)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrDuplicateReport
+	}
+	return nil
+}
+
+// GetByID busca uma denúncia pelo ID
+func (r *reportRepository) GetByID(id uint) (*models.ContentReport, error) {
+	var report models.ContentReport
+	if err := r.db.First(&report, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrReportNotFound
+		}
+		return nil, err
+	}
+	return &report, nil
+}
+
+// List busca denúncias com paginação e filtro opcional por status
+// Retorna a lista de denúncias e o total de registros
+func (r *reportRepository) List(status *string, limit, offset int) ([]models.ContentReport, int64, error) {
+	var reports []models.ContentReport
+	var total int64
+
+	query := r.db.Model(&models.ContentReport{})
+
+	if status != nil && *status != "" {
+		query = query.Where("status = ?", *status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.
+		Limit(limit).
+		Offset(offset).
+		Order("created_at ASC").
+		Find(&reports).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return reports, total, nil
+}
+
+// CountOpenReportersByContentID conta os usuários distintos com denúncia em
+// aberto para um conteúdo
+func (r *reportRepository) CountOpenReportersByContentID(contentID uint) (int64, error) {
+	var count int64
+	if err := r.db.Model(&models.ContentReport{}).
+		Where("content_id = ? AND status = ?", contentID, models.ReportStatusOpen).
+		Distinct("user_id").
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Resolve fecha a denúncia, registra a decisão e aplica a mudança de
+// ocultação numa única transação. A denúncia só é fechada se ainda estiver
+// em aberto (UPDATE condicional), então dois moderadores não resolvem a
+// mesma denúncia: o segundo recebe ErrReportResolved e nada é gravado.
+func (r *reportRepository) Resolve(reportID uint, resolution ReportResolution) (*models.ContentReport, error) {
+	var report models.ContentReport
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&models.ContentReport{}).
+			Where("id = ? AND status = ?", reportID, models.ReportStatusOpen).
+			Updates(map[string]interface{}{"status": resolution.Status, "resolved_at": now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if err := tx.First(&report, reportID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrReportNotFound
+			}
+			return err
+		}
+		if result.RowsAffected == 0 {
+			return ErrReportResolved
+		}
+
+		decision := resolution.Decision
+		decision.ContentID = report.ContentID
+		decision.ReportID = &report.ID
+		if err := tx.Create(decision).Error; err != nil {
+			return err
+		}
+
+		if resolution.Hide {
+			return tx.Model(&models.Content{}).Where("id = ?", report.ContentID).
+				Updates(map[string]interface{}{"hidden": true, "hidden_reason": models.HiddenReasonModerator}).Error
+		}
+		if resolution.UnhideBelow > 0 {
+			var reporters int64
+			if err := tx.Model(&models.ContentReport{}).
+				Where("content_id = ? AND status = ?", report.ContentID, models.ReportStatusOpen).
+				Distinct("user_id").
+				Count(&reporters).Error; err != nil {
+				return err
+			}
+			if reporters < int64(resolution.UnhideBelow) {
+				return tx.Model(&models.Content{}).
+					Where("id = ? AND hidden = ? AND hidden_reason = ?", report.ContentID, true, models.HiddenReasonReports).
+					Updates(map[string]interface{}{"hidden": false, "hidden_reason": nil}).Error
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// CreateDecision registra uma decisão de moderação para auditoria
+func (r *reportRepository) CreateDecision(decision *models.ModerationDecision) error {
+	return r.db.Create(decision).Error
+}
+
+// ListDecisionsByContentID retorna o histórico de decisões de um conteúdo
+func (r *reportRepository) ListDecisionsByContentID(contentID uint) ([]models.ModerationDecision, error) {
+	var decisions []models.ModerationDecision
+	if err := r.db.Where("content_id = ?", contentID).
+		Order("created_at DESC").
+		Find(&decisions).Error; err != nil {
+		return nil, err
+	}
+	return decisions, nil
+}
@@ -0,0 +1,385 @@
+package repository
+
+import (
+	"backend-go/models"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrWatermarkMoved indica que outro processo agregou interações enquanto
+// esta execução estava em andamento
+var ErrWatermarkMoved = errors.New("watermark do rollup foi alterado por outra execução")
+
+// RollupRepository define a interface para os rollups diários de interações
+type RollupRepository interface {
+	GetWatermark(name string) (*models.RollupWatermark, error)
+	MaxInteractionID(createdBefore time.Time) (uint, error)
+	ListInteractions(afterID, upToID uint, from, to *time.Time, limit int) ([]models.UserInteraction, error)
+	ListInteractionsByID(ids []uint) ([]models.UserInteraction, error)
+	ListGaps(name string) ([]models.RollupGap, error)
+	Apply(name string, fromID, toID uint, gaps RollupGapChange, contentStats []models.ContentDailyStat, userStats []models.UserDailyStat) error
+	AdvanceWatermark(name string, fromID, toID uint, gaps RollupGapChange) error
+	ReplaceRange(name string, snapshot RollupSnapshot, resolved []uint, from, to *time.Time, contentStats []models.ContentDailyStat, userStats []models.UserDailyStat) error
+	ListContentDaily(contentID uint, from, to time.Time) ([]models.ContentDailyStat, error)
+	ListUserDaily(userID uint, from, to time.Time) ([]models.UserDailyStat, error)
+	TopContents(from, to time.Time, types []string, excludeIDs []uint, limit int) ([]ContentTotals, error)
+}
+
+// RollupGapChange lista as lacunas de ID abertas e resolvidas por um lote,
+// gravadas na mesma transação que o lote
+type RollupGapChange struct {
+	Open     []uint
+	Resolved []uint
+}
+
+// RollupSnapshot identifica o estado do job (watermark e lacunas abertas)
+// usado num recálculo
+type RollupSnapshot struct {
+	Watermark uint
+	Gaps      []uint
+}
+
+// ContentTotals soma os rollups de um conteúdo em um período
+type ContentTotals struct {
+	ContentID    uint
+	Interactions int64
+	RatingSum    float64
+	RatingCount  int64
+}
+
+type rollupRepository struct {
+	db *gorm.DB
+}
+
+// NewRollupRepository cria uma nova instância do RollupRepository
+func NewRollupRepository(db *gorm.DB) RollupRepository {
+	return &rollupRepository{db: db}
+}
+
+// GetWatermark retorna o watermark do job; zero se ele ainda não rodou
+func (r *rollupRepository) GetWatermark(name string) (*models.RollupWatermark, error) {
+	var watermark models.RollupWatermark
+	if err := r.db.Where("name = ?", name).First(&watermark).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &models.RollupWatermark{Name: name}, nil
+		}
+		return nil, err
+	}
+	return &watermark, nil
+}
+
+// MaxInteractionID retorna o maior ID de interação criada antes do instante informado
+func (r *rollupRepository) MaxInteractionID(createdBefore time.Time) (uint, error) {
+	var maxID *uint
+	if err := r.db.Model(&models.UserInteraction{}).
+		Where("created_at < ?", createdBefore).
+		Select("MAX(id)").
+		Scan(&maxID).Error; err != nil {
+		return 0, err
+	}
+	if maxID == nil {
+		return 0, nil
+	}
+	return *maxID, nil
+}
+
+// ListInteractions busca interações no intervalo de IDs (afterID, upToID],
+// opcionalmente restritas a um período de created_at, em ordem de ID
+func (r *rollupRepository) ListInteractions(afterID, upToID uint, from, to *time.Time, limit int) ([]models.UserInteraction, error) {
+	var interactions []models.UserInteraction
+
+	query := r.db.Select("id", "user_id", "content_id", "interaction_type", "rating", "flagged", "created_at").
+		Where("id > ? AND id <= ?", afterID, upToID)
+	if from != nil {
+		query = query.Where("created_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("created_at < ?", *to)
+	}
+
+	if err := query.Order("id ASC").Limit(limit).Find(&interactions).Error; err != nil {
+		return nil, err
+	}
+	return interactions, nil
+}
+
+// ListInteractionsByID busca as interações com os IDs informados, em ordem de ID
+func (r *rollupRepository) ListInteractionsByID(ids []uint) ([]models.UserInteraction, error) {
+	var interactions []models.UserInteraction
+	if len(ids) == 0 {
+		return interactions, nil
+	}
+	if err := r.db.Select("id", "user_id", "content_id", "interaction_type", "rating", "flagged", "created_at").
+		Where("id IN ?", ids).
+		Order("id ASC").
+		Find(&interactions).Error; err != nil {
+		return nil, err
+	}
+	return interactions, nil
+}
+
+// ListGaps retorna as lacunas de ID ainda abertas do job, em ordem de ID
+func (r *rollupRepository) ListGaps(name string) ([]models.RollupGap, error) {
+	var gaps []models.RollupGap
+	if err := r.db.Where("name = ?", name).
+		Order("interaction_id ASC").
+		Find(&gaps).Error; err != nil {
+		return nil, err
+	}
+	return gaps, nil
+}
+
+// Apply soma os deltas aos rollups, avança o watermark de fromID para toID e
+// atualiza as lacunas numa única transação. Retorna ErrWatermarkMoved se o
+// watermark não estiver mais em fromID ou se outra execução já tiver
+// resolvido alguma das lacunas.
+func (r *rollupRepository) Apply(name string, fromID, toID uint, gaps RollupGapChange, contentStats []models.ContentDailyStat, userStats []models.UserDailyStat) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockWatermark(tx, name, fromID); err != nil {
+			return err
+		}
+		if err := updateGaps(tx, name, gaps); err != nil {
+			return err
+		}
+
+		for i := range contentStats {
+			stat := contentStats[i]
+			if err := tx.Omit("Content").Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "content_id"}, {Name: "day"}, {Name: "interaction_type"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{
+					"interactions": gorm.Expr("content_daily_stats.interactions + ?", stat.Interactions),
+					"rating_sum":   gorm.Expr("content_daily_stats.rating_sum + ?", stat.RatingSum),
+					"rating_count": gorm.Expr("content_daily_stats.rating_count + ?", stat.RatingCount),
+					"updated_at":   stat.UpdatedAt,
+				}),
+			}).Create(&stat).Error; err != nil {
+				return err
+			}
+		}
+
+		for i := range userStats {
+			stat := userStats[i]
+			if err := tx.Omit("User").Clauses(clause.OnConflict{
+				Columns: []clause.Column{{Name: "user_id"}, {Name: "day"}, {Name: "interaction_type"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{
+					"interactions": gorm.Expr("user_daily_stats.interactions + ?", stat.Interactions),
+					"updated_at":   stat.UpdatedAt,
+				}),
+			}).Create(&stat).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&models.RollupWatermark{}).
+			Where("name = ?", name).
+			Update("last_interaction_id", toID).Error
+	})
+}
+
+// AdvanceWatermark move o watermark de fromID para toID e atualiza as
+// lacunas. Retorna ErrWatermarkMoved se outra execução já o tiver alterado.
+func (r *rollupRepository) AdvanceWatermark(name string, fromID, toID uint, gaps RollupGapChange) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockWatermark(tx, name, fromID); err != nil {
+			return err
+		}
+		if err := updateGaps(tx, name, gaps); err != nil {
+			return err
+		}
+		return tx.Model(&models.RollupWatermark{}).
+			Where("name = ?", name).
+			Update("last_interaction_id", toID).Error
+	})
+}
+
+// ReplaceRange apaga os rollups dos dias no período [from, to), grava os
+// valores recalculados e fecha as lacunas resolvidas pelo recálculo. Todos os
+// dias são apagados quando o período é nulo. O watermark e as lacunas abertas
+// precisam continuar iguais aos do snapshot usado no recálculo.
+func (r *rollupRepository) ReplaceRange(name string, snapshot RollupSnapshot, resolved []uint, from, to *time.Time, contentStats []models.ContentDailyStat, userStats []models.UserDailyStat) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockWatermark(tx, name, snapshot.Watermark); err != nil {
+			return err
+		}
+		var gaps []uint
+		if err := tx.Model(&models.RollupGap{}).
+			Where("name = ?", name).
+			Order("interaction_id ASC").
+			Pluck("interaction_id", &gaps).Error; err != nil {
+			return err
+		}
+		if !equalIDs(gaps, snapshot.Gaps) {
+			return ErrWatermarkMoved
+		}
+		if err := updateGaps(tx, name, RollupGapChange{Resolved: resolved}); err != nil {
+			return err
+		}
+
+		for _, model := range []interface{}{&models.ContentDailyStat{}, &models.UserDailyStat{}} {
+			query := tx.Where("1 = 1")
+			if from != nil {
+				query = query.Where("day >= ?", *from)
+			}
+			if to != nil {
+				query = query.Where("day < ?", *to)
+			}
+			if err := query.Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		if len(contentStats) > 0 {
+			if err := tx.Omit("Content").CreateInBatches(contentStats, 200).Error; err != nil {
+				return err
+			}
+		}
+		if len(userStats) > 0 {
+			if err := tx.Omit("User").CreateInBatches(userStats, 200).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// lockWatermark garante que o registro do watermark existe, bloqueia-o até o
+// fim da transação e confere se ele ainda está no valor esperado
+func lockWatermark(tx *gorm.DB, name string, expected uint) error {
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.RollupWatermark{Name: name}).Error; err != nil {
+		return err
+	}
+
+	var watermark models.RollupWatermark
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("name = ?", name).
+		First(&watermark).Error; err != nil {
+		return err
+	}
+	if watermark.LastInteractionID != expected {
+		return ErrWatermarkMoved
+	}
+	return nil
+}
+
+// updateGaps grava as lacunas abertas e apaga as resolvidas. Se alguma lacuna
+// resolvida não existir mais, outra execução já a agregou.
+func updateGaps(tx *gorm.DB, name string, gaps RollupGapChange) error {
+	if len(gaps.Open) > 0 {
+		now := time.Now()
+		rows := make([]models.RollupGap, len(gaps.Open))
+		for i, id := range gaps.Open {
+			rows[i] = models.RollupGap{Name: name, InteractionID: id, SeenAt: now}
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).
+			CreateInBatches(rows, 200).Error; err != nil {
+			return err
+		}
+	}
+	if len(gaps.Resolved) > 0 {
+		result := tx.Where("name = ? AND interaction_id IN ?", name, gaps.Resolved).
+			Delete(&models.RollupGap{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != int64(len(gaps.Resolved)) {
+			return ErrWatermarkMoved
+		}
+	}
+	return nil
+}
+
+// equalIDs compara duas listas de IDs ordenadas
+func equalIDs(a, b []uint) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ListContentDaily retorna os rollups de um conteúdo nos dias [from, to)
+func (r *rollupRepository) ListContentDaily(contentID uint, from, to time.Time) ([]models.ContentDailyStat, error) {
+	var stats []models.ContentDailyStat
+	if err := r.db.Where("content_id = ? AND day >= ? AND day < ?", contentID, from, to).
+		Order("day ASC, interaction_type ASC").
+		Find(&stats).Error; err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// ListUserDaily retorna os rollups de um usuário nos dias [from, to)
+func (r *rollupRepository) ListUserDaily(userID uint, from, to time.Time) ([]models.UserDailyStat, error) {
+	var stats []models.UserDailyStat
+	if err := r.db.Where("user_id = ? AND day >= ? AND day < ?", userID, from, to).
+		Order("day ASC, interaction_type ASC").
+		Find(&stats).Error; err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// TopContents soma os rollups por conteúdo nos dias [from, to) e retorna os
+// conteúdos com mais interações. Conteúdos ocultos pela moderação são ignorados.
+func (r *rollupRepository) TopContents(from, to time.Time, types []string, excludeIDs []uint, limit int) ([]ContentTotals, error) {
+	var totals []ContentTotals
+
+	query := r.db.Model(&models.ContentDailyStat{}).
+		Select("content_id, SUM(interactions) AS interactions, SUM(rating_sum) AS rating_sum, SUM(rating_count) AS rating_count").
+		Where("day >= ? AND day < ?", from, to).
+		Where("content_id NOT IN (SELECT id FROM contents WHERE hidden = ?)", true)
+	if len(types) > 0 {
+		query = query.Where("interaction_type IN ?", types)
+	}
+	if len(excludeIDs) > 0 {
+		query = query.Where("content_id NOT IN ?", excludeIDs)
+	}
+
+	if err := query.Group("content_id").
+		Order("interactions DESC, content_id ASC").
+		Limit(limit).
+		Scan(&totals).Error; err != nil {
+		return nil, err
+	}
+	return totals, nil
+}
+
+// weightedScoreExpr monta a soma ponderada dos rollups por tipo de interação.
+// Em rating, o peso multiplica a distância de cada nota até 3. Sem pesos, a
+// soma é o total de interações.
+func weightedScoreExpr(weights map[string]float64) (string, []interface{}) {
+	if len(weights) == 0 {
+		return "SUM(interactions)", nil
+	}
+
+	// Ordem fixa para a consulta gerada ser sempre a mesma
+	types := make([]string, 0, len(weights))
+	for interactionType := range weights {
+		types = append(types, interactionType)
+	}
+	sort.Strings(types)
+
+	var expr strings.Builder
+	var args []interface{}
+	expr.WriteString("SUM(CASE interaction_type")
+	for _, interactionType := range types {
+		if interactionType == "rating" {
+			expr.WriteString(" WHEN ? THEN (rating_sum - 3 * rating_count) * ?")
+		} else {
+			expr.WriteString(" WHEN ? THEN interactions * ?")
+		}
+		args = append(args, interactionType, weights[interactionType])
+	}
+	expr.WriteString(" ELSE 0 END)")
+	return expr.String(), args
+}
@@ -2,12 +2,15 @@ package repository
 
 import (
 	"backend-go/models"
+	"errors"
 
 	"gorm.io/gorm"
 )
 
 type UserRepository interface {
 	First() (*models.User, error)
+	GetByID(id uint) (*models.User, error)
+	GetExistingIDs(ids []uint) ([]uint, error)
 }
 
 type userRepository struct {
@@ -27,3 +30,28 @@ func (r *userRepository) First() (*models.User, error) {
 	}
 	return &user, nil
 }
+
+func (r *userRepository) GetByID(id uint) (*models.User, error) {
+	var user models.User
+	if err := r.db.First(&user, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetExistingIDs retorna, dentre os IDs informados, os usuários que existem
+func (r *userRepository) GetExistingIDs(ids []uint) ([]uint, error) {
+	existing := []uint{}
+	if len(ids) == 0 {
+		return existing, nil
+	}
+	if err := r.db.Model(&models.User{}).
+		Where("id IN ?", ids).
+		Pluck("id", &existing).Error; err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
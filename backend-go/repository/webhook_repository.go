@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"backend-go/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WebhookRepository define a interface para assinaturas e entregas de webhooks
+type WebhookRepository interface {
+	CreateSubscription(subscription *models.WebhookSubscription) error
+	UpdateSubscription(subscription *models.WebhookSubscription) error
+	DeleteSubscription(id uint) error
+	GetSubscription(id uint) (*models.WebhookSubscription, error)
+	ListSubscriptions(limit, offset int) ([]models.WebhookSubscription, int64, error)
+	ListActiveSubscriptions() ([]models.WebhookSubscription, error)
+	CreateDeliveries(deliveries []models.WebhookDelivery) error
+	EnqueueFromOutbox(deliveries []models.WebhookDelivery, outboxIDs []uint) error
+	GetDelivery(id uint) (*models.WebhookDelivery, error)
+	UpdateDelivery(delivery *models.WebhookDelivery) error
+	ListDeliveries(subscriptionID uint, status string, limit, offset int) ([]models.WebhookDelivery, int64, error)
+	ListDueDeliveries(now time.Time, limit int) ([]models.WebhookDelivery, error)
+}
+
+type webhookRepository struct {
+	db *gorm.DB
+}
+
+// NewWebhookRepository cria uma nova instância do WebhookRepository
+func NewWebhookRepository(db *gorm.DB) WebhookRepository {
+	return &webhookRepository{db: db}
+}
+
+// CreateSubscription cria uma nova assinatura
+func (r *webhookRepository) CreateSubscription(subscription *models.WebhookSubscription) error {
+	return r.db.Create(subscription).Error
+}
+
+// UpdateSubscription atualiza uma assinatura existente
+func (r *webhookRepository) UpdateSubscription(subscription *models.WebhookSubscription) error {
+	return r.db.Omit("Deliveries").Save(subscription).Error
+}
+
+// DeleteSubscription remove uma assinatura e, em cascata, suas entregas
+func (r *webhookRepository) DeleteSubscription(id uint) error {
+	result := r.db.Delete(&models.WebhookSubscription{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("assinatura não encontrada")
+	}
+	return nil
+}
+
+// GetSubscription busca uma assinatura por ID
+func (r *webhookRepository) GetSubscription(id uint) (*models.WebhookSubscription, error) {
+	var subscription models.WebhookSubscription
+	if err := r.db.First(&subscription, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("assinatura não encontrada")
+		}
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// ListSubscriptions busca as assinaturas com paginação
+func (r *webhookRepository) ListSubscriptions(limit, offset int) ([]models.WebhookSubscription, int64, error) {
+	var subscriptions []models.WebhookSubscription
+	var total int64
+
+	if err := r.db.Model(&models.WebhookSubscription{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := r.db.Order("id ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&subscriptions).Error; err != nil {
+		return nil, 0, err
+	}
+	return subscriptions, total, nil
+}
+
+// ListActiveSubscriptions busca todas as assinaturas ativas
+func (r *webhookRepository) ListActiveSubscriptions() ([]models.WebhookSubscription, error) {
+	var subscriptions []models.WebhookSubscription
+	if err := r.db.Where("active = ?", true).Find(&subscriptions).Error; err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// CreateDeliveries enfileira as entregas de um evento numa única transação
+func (r *webhookRepository) CreateDeliveries(deliveries []models.WebhookDelivery) error {
+	if len(deliveries) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(deliveries, 100).Error
+}
+
+// EnqueueFromOutbox grava as entregas dos eventos lidos do outbox e marca os
+// eventos como entregues numa única transação, então cada evento gera suas
+// entregas uma única vez
+func (r *webhookRepository) EnqueueFromOutbox(deliveries []models.WebhookDelivery, outboxIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if len(deliveries) > 0 {
+			if err := tx.CreateInBatches(deliveries, 100).Error; err != nil {
+				return err
+			}
+		}
+		return markOutboxDelivered(tx, outboxIDs, time.Now())
+	})
+}
+
+// GetDelivery busca uma entrega por ID
+func (r *webhookRepository) GetDelivery(id uint) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	if err := r.db.First(&delivery, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("entrega não encontrada")
+		}
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+// UpdateDelivery grava o resultado de uma tentativa de entrega
+func (r *webhookRepository) UpdateDelivery(delivery *models.WebhookDelivery) error {
+	return r.db.Save(delivery).Error
+}
+
+// ListDeliveries busca o log de entregas de uma assinatura, das mais recentes
+// para as mais antigas; status vazio lista todas
+func (r *webhookRepository) ListDeliveries(subscriptionID uint, status string, limit, offset int) ([]models.WebhookDelivery, int64, error) {
+	var deliveries []models.WebhookDelivery
+	var total int64
+
+	query := r.db.Model(&models.WebhookDelivery{}).Where("subscription_id = ?", subscriptionID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := query.Order("id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&deliveries).Error; err != nil {
+		return nil, 0, err
+	}
+	return deliveries, total, nil
+}
+
+// ListDueDeliveries busca as entregas pendentes cuja próxima tentativa já venceu
+func (r *webhookRepository) ListDueDeliveries(now time.Time, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	if err := r.db.Where("status = ? AND next_attempt_at <= ?", models.WebhookDeliveryPending, now).
+		Order("id ASC").
+		Limit(limit).
+		Find(&deliveries).Error; err != nil {
+		return nil, err
+	}
+	return deliveries, nil
+}
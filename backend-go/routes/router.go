@@ -2,6 +2,8 @@ package routes
 
 import (
 	"backend-go/handler"
+	"backend-go/middleware"
+	"backend-go/service"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -9,11 +11,27 @@ import (
 )
 
 type Router struct {
-	engine                *gin.Engine
-	userHandler           *handler.UserHandler
-	contentHandler        *handler.ContentHandler
-	interactionHandler    *handler.InteractionHandler
-	recommendationHandler *handler.RecommendationHandler
+	engine                     *gin.Engine
+	userHandler                *handler.UserHandler
+	contentHandler             *handler.ContentHandler
+	interactionHandler         *handler.InteractionHandler
+	recommendationHandler      *handler.RecommendationHandler
+	moderationHandler          *handler.ModerationHandler
+	commentHandler             *handler.CommentHandler
+	collectionHandler          *handler.CollectionHandler
+	bookmarkHandler            *handler.BookmarkHandler
+	analyticsHandler           *handler.AnalyticsHandler
+	affinityHandler            *handler.AffinityHandler
+	outboxHandler              *handler.OutboxHandler
+	webhookHandler             *handler.WebhookHandler
+	eventStreamHandler         *handler.EventStreamHandler
+	eventBusHandler            *handler.EventBusHandler
+	itemCFHandler              *handler.ItemCFHandler
+	recommenderHandler         *handler.RecommenderHandler
+	recommendationCacheHandler *handler.RecommendationCacheHandler
+	idempotencyService         service.IdempotencyService
+	ipRateLimiter              *middleware.RateLimiter
+	userRateLimiter            *middleware.RateLimiter
 }
 
 func NewRouter(
@@ -21,14 +39,46 @@ func NewRouter(
 	contentHandler *handler.ContentHandler,
 	interactionHandler *handler.InteractionHandler,
 	recommendationHandler *handler.RecommendationHandler,
+	moderationHandler *handler.ModerationHandler,
+	commentHandler *handler.CommentHandler,
+	collectionHandler *handler.CollectionHandler,
+	bookmarkHandler *handler.BookmarkHandler,
+	analyticsHandler *handler.AnalyticsHandler,
+	affinityHandler *handler.AffinityHandler,
+	outboxHandler *handler.OutboxHandler,
+	webhookHandler *handler.WebhookHandler,
+	eventStreamHandler *handler.EventStreamHandler,
+	eventBusHandler *handler.EventBusHandler,
+	itemCFHandler *handler.ItemCFHandler,
+	recommenderHandler *handler.RecommenderHandler,
+	recommendationCacheHandler *handler.RecommendationCacheHandler,
+	idempotencyService service.IdempotencyService,
+	ipRateLimiter *middleware.RateLimiter,
+	userRateLimiter *middleware.RateLimiter,
 ) *Router {
 	engine := gin.Default()
 	return &Router{
-		engine:                engine,
-		userHandler:           userHandler,
-		contentHandler:        contentHandler,
-		interactionHandler:    interactionHandler,
-		recommendationHandler: recommendationHandler,
+		engine:                     engine,
+		userHandler:                userHandler,
+		contentHandler:             contentHandler,
+		interactionHandler:         interactionHandler,
+		recommendationHandler:      recommendationHandler,
+		moderationHandler:          moderationHandler,
+		commentHandler:             commentHandler,
+		collectionHandler:          collectionHandler,
+		bookmarkHandler:            bookmarkHandler,
+		analyticsHandler:           analyticsHandler,
+		affinityHandler:            affinityHandler,
+		outboxHandler:              outboxHandler,
+		webhookHandler:             webhookHandler,
+		eventStreamHandler:         eventStreamHandler,
+		eventBusHandler:            eventBusHandler,
+		itemCFHandler:              itemCFHandler,
+		recommenderHandler:         recommenderHandler,
+		recommendationCacheHandler: recommendationCacheHandler,
+		idempotencyService:         idempotencyService,
+		ipRateLimiter:              ipRateLimiter,
+		userRateLimiter:            userRateLimiter,
 	}
 }
 
@@ -40,18 +90,66 @@ func (r *Router) SetupRoutes() *gin.Engine {
 	// Rotas de usuários
 	users := api.Group("/users")
 	r.userHandler.RegisterRoutes(users)
+	r.bookmarkHandler.RegisterUserRoutes(users)
+	r.affinityHandler.RegisterUserRoutes(users)
 
 	// Rotas de conteúdos
-	contents := api.Group("/contents")
+	contents := api.Group("/contents", middleware.Idempotency(r.idempotencyService))
 	r.contentHandler.RegisterRoutes(contents)
+	r.moderationHandler.RegisterContentRoutes(contents)
+	r.commentHandler.RegisterContentRoutes(contents)
 
 	// Rotas de interações
-	interactions := api.Group("/interactions")
+	interactions := api.Group("/interactions",
+		middleware.RateLimit(r.ipRateLimiter, middleware.ClientIPKeys),
+		middleware.RateLimit(r.userRateLimiter, middleware.UserIDKeys),
+		middleware.Idempotency(r.idempotencyService),
+	)
 	r.interactionHandler.RegisterRoutes(interactions)
 
 	// Rotas de recomendações
 	recommendations := api.Group("/recommendations")
 	r.recommendationHandler.RegisterRoutes(recommendations)
 
+	// Rotas de moderação
+	moderation := api.Group("/moderation")
+	r.moderationHandler.RegisterRoutes(moderation)
+
+	// Rotas de comentários
+	comments := api.Group("/comments")
+	r.commentHandler.RegisterRoutes(comments)
+
+	// Rotas de coleções
+	collections := api.Group("/collections")
+	r.collectionHandler.RegisterRoutes(collections)
+
+	// Rotas de analytics
+	analytics := api.Group("/analytics")
+	r.analyticsHandler.RegisterRoutes(analytics)
+
+	// Rotas de afinidade
+	affinity := api.Group("/affinity")
+	r.affinityHandler.RegisterRoutes(affinity)
+
+	// Rotas de webhooks
+	webhooks := api.Group("/webhooks")
+	r.webhookHandler.RegisterRoutes(webhooks)
+
+	// Stream de eventos em tempo real
+	events := api.Group("/events")
+	r.eventStreamHandler.RegisterRoutes(events)
+
+	// Schemas e consumo do barramento de eventos
+	bus := api.Group("/bus")
+	r.eventBusHandler.RegisterRoutes(bus)
+
+	// Rotas administrativas
+	admin := api.Group("/admin")
+	r.outboxHandler.RegisterRoutes(admin.Group("/outbox"))
+	r.itemCFHandler.RegisterRoutes(admin.Group("/item-cf"))
+	r.recommenderHandler.RegisterRoutes(admin.Group("/recommender"))
+	r.recommendationHandler.RegisterAdminRoutes(admin.Group("/recommendations"))
+	r.recommendationCacheHandler.RegisterRoutes(admin.Group("/recommendation-cache"))
+
 	return r.engine
 }
@@ -0,0 +1,211 @@
+package service
+
+import (
+	"backend-go/models"
+	"backend-go/repository"
+	"sort"
+	"sync"
+	"time"
+)
+
+// AbusePolicy define os limites usados para sinalizar interações suspeitas.
+// As janelas contam a partir do recebimento do evento pelo servidor, não do
+// horário informado pelo cliente.
+type AbusePolicy struct {
+	// Rajada: mais de BurstMax interações do mesmo usuário em BurstWindow
+	BurstWindow time.Duration
+	BurstMax    int64
+	// Re-avaliação: mais de ReRateMax notas do mesmo usuário para o mesmo
+	// conteúdo em ReRateWindow
+	ReRateWindow time.Duration
+	ReRateMax    int64
+	// Bombardeio de notas: a partir de RatingBombMin notas no conteúdo em
+	// RatingBombWindow, notas extremas são sinalizadas quando a fração de
+	// notas extremas no mesmo sentido atinge RatingBombShare
+	RatingBombWindow time.Duration
+	RatingBombMin    int64
+	RatingBombShare  float64
+}
+
+// Notas consideradas extremas pela heurística de bombardeio
+const (
+	extremeLowRating  = 1.5
+	extremeHighRating = 4.5
+)
+
+// DefaultAbusePolicy retorna os limites padrão de detecção de abuso
+func DefaultAbusePolicy() AbusePolicy {
+	return AbusePolicy{
+		BurstWindow:      time.Minute,
+		BurstMax:         60,
+		ReRateWindow:     24 * time.Hour,
+		ReRateMax:        3,
+		RatingBombWindow: time.Hour,
+		RatingBombMin:    20,
+		RatingBombShare:  0.8,
+	}
+}
+
+// abuseDetector aplica as heurísticas de abuso aos eventos antes de gravá-los
+type abuseDetector struct {
+	repo   repository.InteractionRepository
+	policy AbusePolicy
+	users  userLocks
+}
+
+func newAbuseDetector(repo repository.InteractionRepository, policy AbusePolicy) *abuseDetector {
+	return &abuseDetector{repo: repo, policy: policy}
+}
+
+// lock serializa a contagem e a gravação dos eventos dos usuários, para que
+// requisições concorrentes do mesmo usuário não leiam a mesma contagem.
+// O lock é por instância: com várias réplicas, rajadas divididas entre elas
+// ainda podem passar do limite até a próxima contagem.
+func (d *abuseDetector) lock(userIDs []uint) func() {
+	return d.users.lock(userIDs)
+}
+
+type userContentKey struct{ userID, contentID uint }
+
+// flag marca os eventos suspeitos. As contagens são carregadas do banco uma
+// vez por usuário/conteúdo e atualizadas em memória com os próprios eventos,
+// então um lote grande não gera uma consulta por evento.
+func (d *abuseDetector) flag(events []*models.UserInteraction) error {
+	now := time.Now()
+	burstSince := now.Add(-d.policy.BurstWindow)
+	reRateSince := now.Add(-d.policy.ReRateWindow)
+	bombSince := now.Add(-d.policy.RatingBombWindow)
+
+	userCounts := make(map[uint]int64)
+	ratingCounts := make(map[userContentKey]int64)
+	contentRatings := make(map[uint]*repository.RatingCounts)
+
+	for _, event := range events {
+		// Todo evento recebido conta para a rajada, inclusive os sincronizados
+		// offline com horário antigo
+		count, ok := userCounts[event.UserID]
+		if !ok {
+			var err error
+			if count, err = d.repo.CountByUserSince(event.UserID, burstSince); err != nil {
+				return err
+			}
+		}
+		count++
+		userCounts[event.UserID] = count
+		if count > d.policy.BurstMax {
+			flagInteraction(event, models.FlagReasonBurst)
+		}
+
+		if event.InteractionType != "rating" || event.Rating == nil {
+			continue
+		}
+
+		key := userContentKey{event.UserID, event.ContentID}
+		ratingCount, ok := ratingCounts[key]
+		if !ok {
+			var err error
+			if ratingCount, err = d.repo.CountRatingsSince(event.UserID, event.ContentID, reRateSince); err != nil {
+				return err
+			}
+		}
+		ratingCount++
+		ratingCounts[key] = ratingCount
+		if ratingCount > d.policy.ReRateMax {
+			flagInteraction(event, models.FlagReasonReRating)
+		}
+
+		ratings, ok := contentRatings[event.ContentID]
+		if !ok {
+			loaded, err := d.repo.GetRatingCountsSince(event.ContentID, bombSince, extremeLowRating, extremeHighRating)
+			if err != nil {
+				return err
+			}
+			ratings = &loaded
+			contentRatings[event.ContentID] = ratings
+		}
+		ratings.Total++
+		switch {
+		case *event.Rating <= extremeLowRating:
+			ratings.Low++
+			if d.isRatingBomb(ratings.Low, ratings.Total) {
+				flagInteraction(event, models.FlagReasonRatingBomb)
+			}
+		case *event.Rating >= extremeHighRating:
+			ratings.High++
+			if d.isRatingBomb(ratings.High, ratings.Total) {
+				flagInteraction(event, models.FlagReasonRatingBomb)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (d *abuseDetector) isRatingBomb(extreme, total int64) bool {
+	return total >= d.policy.RatingBombMin &&
+		float64(extreme)/float64(total) >= d.policy.RatingBombShare
+}
+
+// flagInteraction sinaliza o evento; o primeiro motivo encontrado prevalece
+func flagInteraction(event *models.UserInteraction, reason string) {
+	if event.Flagged {
+		return
+	}
+	event.Flagged = true
+	event.FlagReason = &reason
+}
+
+// userLocks mantém um mutex por usuário, criado sob demanda e descartado
+// quando ninguém mais o usa
+type userLocks struct {
+	mu    sync.Mutex
+	locks map[uint]*userLock
+}
+
+type userLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock adquire os mutexes dos usuários, em ordem crescente de ID para evitar
+// deadlock entre lotes, e retorna a função que os libera
+func (l *userLocks) lock(userIDs []uint) func() {
+	ids := make([]uint, 0, len(userIDs))
+	seen := make(map[uint]bool, len(userIDs))
+	for _, id := range userIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	held := make([]*userLock, len(ids))
+	for i, id := range ids {
+		l.mu.Lock()
+		if l.locks == nil {
+			l.locks = make(map[uint]*userLock)
+		}
+		lock, ok := l.locks[id]
+		if !ok {
+			lock = &userLock{}
+			l.locks[id] = lock
+		}
+		lock.refs++
+		l.mu.Unlock()
+
+		lock.Lock()
+		held[i] = lock
+	}
+
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		for i, lock := range held {
+			lock.Unlock()
+			if lock.refs--; lock.refs == 0 {
+				delete(l.locks, ids[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"backend-go/models"
+)
+
+func testAbusePolicy() AbusePolicy {
+	return AbusePolicy{
+		BurstWindow:      time.Minute,
+		BurstMax:         3,
+		ReRateWindow:     24 * time.Hour,
+		ReRateMax:        2,
+		RatingBombWindow: time.Hour,
+		RatingBombMin:    4,
+		RatingBombShare:  0.75,
+	}
+}
+
+func TestAbuseThresholds(t *testing.T) {
+	type event struct {
+		userID          uint
+		interactionType string
+		rating          float64
+		// Sincronizado offline com client_timestamp de uma hora atrás
+		backdated bool
+	}
+	view := event{userID: 1, interactionType: "view"}
+	rate := func(userID uint, rating float64) event {
+		return event{userID: userID, interactionType: "rating", rating: rating}
+	}
+	backdatedView := event{userID: 1, interactionType: "view", backdated: true}
+
+	tests := []struct {
+		name string
+		// Cada requisição é um lote enviado em sequência
+		requests [][]event
+		// Motivo esperado para cada interação gravada, na ordem; vazio se não sinalizada
+		wantFlags []string
+		// Nota do usuário 1 no conteúdo ao final, quando verificada
+		wantRating *float64
+	}{
+		{
+			name:      "abaixo dos limites",
+			requests:  [][]event{{view, view, view}},
+			wantFlags: []string{"", "", ""},
+		},
+		{
+			name:      "rajada sinaliza o excedente do lote",
+			requests:  [][]event{{view, view, view, view, view}},
+			wantFlags: []string{"", "", "", models.FlagReasonBurst, models.FlagReasonBurst},
+		},
+		{
+			name:      "rajada somada entre requisições",
+			requests:  [][]event{{view, view}, {view, view}},
+			wantFlags: []string{"", "", "", models.FlagReasonBurst},
+		},
+		{
+			name:      "eventos com horário antigo contam na rajada",
+			requests:  [][]event{{backdatedView, backdatedView, backdatedView, backdatedView}},
+			wantFlags: []string{"", "", "", models.FlagReasonBurst},
+		},
+		{
+			name:       "re-avaliação além do limite não altera a nota",
+			requests:   [][]event{{rate(1, 4)}, {rate(1, 5)}, {rate(1, 1)}},
+			wantFlags:  []string{"", "", models.FlagReasonReRating},
+			wantRating: func() *float64 { r := 5.0; return &r }(),
+		},
+		{
+			name:      "bombardeio de notas extremas",
+			requests:  [][]event{{rate(1, 1)}, {rate(2, 1)}, {rate(3, 1)}, {rate(4, 1)}},
+			wantFlags: []string{"", "", "", models.FlagReasonRatingBomb},
+		},
+		{
+			name:      "notas extremas divididas não são bombardeio",
+			requests:  [][]event{{rate(1, 1)}, {rate(2, 5)}, {rate(3, 1)}, {rate(4, 5)}},
+			wantFlags: []string{"", "", "", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeInteractionRepository()
+			svc := newTestInteractionServiceWithPolicy(repo, testAbusePolicy())
+
+			seq := 0
+			for i, request := range tt.requests {
+				raw := make([]json.RawMessage, len(request))
+				for j, ev := range request {
+					seq++
+					item := map[string]interface{}{
+						"client_event_id":  fmt.Sprintf("e%d", seq),
+						"user_id":          ev.userID,
+						"content_id":       2,
+						"interaction_type": ev.interactionType,
+					}
+					if ev.interactionType == "rating" {
+						item["rating"] = ev.rating
+					}
+					if ev.backdated {
+						item["client_timestamp"] = time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+					}
+					raw[j], _ = json.Marshal(item)
+				}
+				results, err := svc.CreateInteractionsBatch(raw)
+				if err != nil {
+					t.Fatalf("requisição %d: %v", i, err)
+				}
+				for _, result := range results {
+					if result.Status != BatchItemCreated {
+						t.Fatalf("requisição %d: item %d com status %s (%s)", i, result.Index, result.Status, result.Error)
+					}
+				}
+			}
+
+			if len(repo.interactions) != len(tt.wantFlags) {
+				t.Fatalf("interações gravadas = %d, esperado %d", len(repo.interactions), len(tt.wantFlags))
+			}
+			unflagged := 0
+			for i, interaction := range repo.interactions {
+				if got := derefFlagReason(interaction); got != tt.wantFlags[i] {
+					t.Errorf("interação %d: motivo = %q, esperado %q", i, got, tt.wantFlags[i])
+				}
+				if !interaction.Flagged {
+					unflagged++
+				}
+			}
+
+			// Só as interações não sinalizadas vão para o motor, os webhooks e o barramento
+			topics := make(map[string]int)
+			for _, event := range repo.outbox {
+				topics[event.Topic]++
+			}
+			for _, topic := range []string{models.OutboxTopicRecommenderInteraction, models.OutboxTopicWebhookEvent, models.OutboxTopicBusEvent} {
+				if topics[topic] != unflagged {
+					t.Errorf("eventos %s no outbox = %d, esperado %d", topic, topics[topic], unflagged)
+				}
+			}
+
+			if tt.wantRating != nil {
+				state, err := svc.GetReaction(1, 2)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !equalFloatPtr(state.Rating, tt.wantRating) {
+					t.Errorf("rating = %v, esperado %v", state.Rating, *tt.wantRating)
+				}
+			}
+		})
+	}
+}
+
+func TestAbuseDetectorConcurrentRequests(t *testing.T) {
+	const requests = 20
+	repo := newFakeInteractionRepository()
+	repo.countDelay = time.Millisecond
+	policy := testAbusePolicy()
+	svc := newTestInteractionServiceWithPolicy(repo, policy)
+
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := svc.CreateInteraction(1, 2, "view", nil, InteractionMetadata{}); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var flagged int64
+	for _, interaction := range repo.interactions {
+		if interaction.Flagged {
+			flagged++
+		}
+	}
+	if want := requests - policy.BurstMax; flagged != want {
+		t.Errorf("interações sinalizadas = %d, esperado %d", flagged, want)
+	}
+}
+
+func derefFlagReason(interaction *models.UserInteraction) string {
+	if interaction.FlagReason == nil {
+		return ""
+	}
+	return *interaction.FlagReason
+}
@@ -0,0 +1,405 @@
+package service
+
+import (
+	"backend-go/models"
+	"backend-go/repository"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Nome do watermark da atualização incremental das afinidades
+	affinityWatermarkName = "user_content_affinity"
+	// Pares recalculados por lote no recálculo completo
+	affinityRebuildChunkSize = 500
+)
+
+// NeutralRating é a nota neutra das afinidades: notas acima contam como
+// preferência positiva, abaixo como negativa
+const NeutralRating = 3.0
+
+// AffinityPolicy define os pesos por tipo de interação e o decaimento no tempo
+type AffinityPolicy struct {
+	Weights  map[string]float64
+	HalfLife time.Duration
+}
+
+// ParseAffinityWeights lê pesos no formato "view=1,like=3,dislike=-4".
+// O peso de rating é multiplicado pela distância da nota até a nota neutra (3).
+func ParseAffinityWeights(raw string) (map[string]float64, error) {
+	weights := make(map[string]float64)
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("peso inválido: %q, use tipo=peso", item)
+		}
+		interactionType := strings.TrimSpace(parts[0])
+		if !validInteractionTypes[interactionType] {
+			return nil, errors.New("tipo de interação inválido: " + interactionType)
+		}
+		weight, err := strconv.ParseFloat(strings.TrimSpace(parts[1]), 64)
+		if err != nil || math.IsNaN(weight) || math.IsInf(weight, 0) {
+			return nil, fmt.Errorf("peso inválido para %s: %q", interactionType, parts[1])
+		}
+		weights[interactionType] = weight
+	}
+	if len(weights) == 0 {
+		return nil, errors.New("nenhum peso de afinidade configurado")
+	}
+	return weights, nil
+}
+
+// PopularityWeights converte os pesos de afinidade em pesos de popularidade
+// por tipo de interação. As retratações descontam o peso do evento retratado.
+func (p AffinityPolicy) PopularityWeights() map[string]float64 {
+	weights := make(map[string]float64, len(p.Weights)+2)
+	for interactionType, weight := range p.Weights {
+		weights[interactionType] = weight
+	}
+	weights[InteractionUnlike] = -p.Weights["like"]
+	weights[InteractionUndislike] = -p.Weights["dislike"]
+	return weights
+}
+
+// UserAffinity é a afinidade de um usuário com um conteúdo no instante atual
+type UserAffinity struct {
+	ContentID   uint      `json:"content_id"`
+	Score       float64   `json:"score"`
+	LastEventAt time.Time `json:"last_event_at"`
+}
+
+// AffinityQuery define a paginação e o filtro das afinidades de um usuário
+type AffinityQuery struct {
+	MinScore *float64
+	Page     int
+	Limit    int
+}
+
+// Normalize aplica os valores padrão de paginação
+func (q *AffinityQuery) Normalize() {
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.Limit < 1 {
+		q.Limit = 50
+	}
+	if q.Limit > 200 {
+		q.Limit = 200 // Limite máximo
+	}
+}
+
+// AffinityService define a interface para as afinidades usuário-conteúdo
+type AffinityService interface {
+	Policy() AffinityPolicy
+	CatchUp() (int, error)
+	Rebuild() (int, error)
+	GetUserAffinities(userID uint, query AffinityQuery) ([]UserAffinity, int64, error)
+	GetAffinity(userID, contentID uint) (*UserAffinity, error)
+}
+
+type affinityService struct {
+	repo        repository.AffinityRepository
+	rollupRepo  repository.RollupRepository
+	userRepo    repository.UserRepository
+	contentRepo repository.ContentRepository
+	policy      AffinityPolicy
+	mu          sync.Mutex
+}
+
+// NewAffinityService cria uma nova instância do AffinityService
+func NewAffinityService(
+	repo repository.AffinityRepository,
+	rollupRepo repository.RollupRepository,
+	userRepo repository.UserRepository,
+	contentRepo repository.ContentRepository,
+	policy AffinityPolicy,
+) AffinityService {
+	return &affinityService{
+		repo:        repo,
+		rollupRepo:  rollupRepo,
+		userRepo:    userRepo,
+		contentRepo: contentRepo,
+		policy:      policy,
+	}
+}
+
+// Policy retorna os pesos e a meia-vida em uso
+func (s *affinityService) Policy() AffinityPolicy {
+	return s.policy
+}
+
+// CatchUp recalcula as afinidades dos pares com interações criadas depois do
+// watermark. Cada par é recalculado a partir de todo o seu histórico, então
+// eventos fora de ordem e retratações são tratados corretamente. IDs pelos
+// quais o watermark passou sem que a interação estivesse confirmada ficam
+// registrados como lacunas, e seus pares são recalculados quando elas
+// aparecem. Retorna o número de pares atualizados.
+func (s *affinityService) CatchUp() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	watermark, err := s.rollupRepo.GetWatermark(affinityWatermarkName)
+	if err != nil {
+		return 0, err
+	}
+	upTo, err := s.rollupRepo.MaxInteractionID(time.Now().Add(-rollupSafetyLag))
+	if err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	lastID := watermark.LastInteractionID
+
+	gapInteractions, resolved, err := loadGaps(s.rollupRepo, affinityWatermarkName)
+	if err != nil {
+		return 0, err
+	}
+	if len(resolved) > 0 {
+		pairs := interactionPairs(gapInteractions)
+		if err := s.apply(lastID, lastID, repository.RollupGapChange{Resolved: resolved}, pairs); err != nil {
+			return 0, err
+		}
+		updated += len(pairs)
+	}
+
+	for lastID < upTo {
+		interactions, err := s.rollupRepo.ListInteractions(lastID, upTo, nil, nil, rollupChunkSize)
+		if err != nil {
+			return updated, err
+		}
+		if len(interactions) == 0 {
+			break
+		}
+
+		pairs := interactionPairs(interactions)
+		nextID := interactions[len(interactions)-1].ID
+		gaps := repository.RollupGapChange{Open: idGaps(lastID, interactions)}
+		if err := s.apply(lastID, nextID, gaps, pairs); err != nil {
+			return updated, err
+		}
+		updated += len(pairs)
+		lastID = nextID
+	}
+
+	return updated, nil
+}
+
+// Rebuild recalcula todas as afinidades, por exemplo depois de uma mudança
+// nos pesos, a partir do histórico completo, incluindo as interações
+// arquivadas em tabela (arquivamentos em arquivo não são lidos). A tabela é
+// substituída de uma vez ao final; o watermark não é alterado, e as
+// interações posteriores a ele são recalculadas pelo próximo CatchUp.
+// Retorna o número de pares calculados.
+func (s *affinityService) Rebuild() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	watermark, err := s.rollupRepo.GetWatermark(affinityWatermarkName)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	var affinities []models.UserContentAffinity
+	var after repository.UserContentPair
+	for {
+		pairs, err := s.repo.ListPairs(after.UserID, after.ContentID, affinityRebuildChunkSize)
+		if err != nil {
+			return 0, err
+		}
+		if len(pairs) == 0 {
+			break
+		}
+		computed, _, err := s.recompute(pairs, now)
+		if err != nil {
+			return 0, err
+		}
+		affinities = append(affinities, computed...)
+		after = pairs[len(pairs)-1]
+	}
+
+	if err := s.repo.ReplaceAll(affinityWatermarkName, watermark.LastInteractionID, affinities); err != nil {
+		return 0, err
+	}
+	return len(affinities), nil
+}
+
+// apply recalcula os pares e grava o resultado junto com o avanço do watermark
+func (s *affinityService) apply(fromID, toID uint, gaps repository.RollupGapChange, pairs []repository.UserContentPair) error {
+	affinities, removed, err := s.recompute(pairs, time.Now())
+	if err != nil {
+		return err
+	}
+	return s.repo.Apply(affinityWatermarkName, fromID, toID, gaps, affinities, removed)
+}
+
+// interactionPairs retorna os pares distintos das interações, na ordem em
+// que aparecem
+func interactionPairs(interactions []models.UserInteraction) []repository.UserContentPair {
+	seen := make(map[userContentKey]bool)
+	var pairs []repository.UserContentPair
+	for i := range interactions {
+		key := userContentKey{interactions[i].UserID, interactions[i].ContentID}
+		if !seen[key] {
+			seen[key] = true
+			pairs = append(pairs, repository.UserContentPair{UserID: key.userID, ContentID: key.contentID})
+		}
+	}
+	return pairs
+}
+
+// recompute recalcula a afinidade de cada par. Pares sem eventos válidos
+// (todos sinalizados) são retornados em removed.
+func (s *affinityService) recompute(pairs []repository.UserContentPair, now time.Time) ([]models.UserContentAffinity, []repository.UserContentPair, error) {
+	var affinities []models.UserContentAffinity
+	var removed []repository.UserContentPair
+	for _, pair := range pairs {
+		events, err := s.repo.ListPairEvents(pair.UserID, pair.ContentID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(events) == 0 {
+			removed = append(removed, pair)
+			continue
+		}
+
+		affinities = append(affinities, models.UserContentAffinity{
+			UserID:        pair.UserID,
+			ContentID:     pair.ContentID,
+			Score:         computeAffinity(events, s.policy, now),
+			HalfLifeHours: s.policy.HalfLife.Hours(),
+			LastEventAt:   events[len(events)-1].CreatedAt,
+			ComputedAt:    now,
+		})
+	}
+	return affinities, removed, nil
+}
+
+// computeAffinity soma os pesos dos eventos com decaimento exponencial pela
+// idade de cada um. Like/dislike e rating representam um estado: só a reação
+// e a nota vigentes contam, decaindo a partir do momento em que foram dadas.
+// Os demais tipos (view, share, comment) se acumulam.
+func computeAffinity(events []models.UserInteraction, policy AffinityPolicy, now time.Time) float64 {
+	var score float64
+	var reaction *models.UserInteraction
+	var rating *models.UserInteraction
+
+	for i := range events {
+		event := &events[i]
+		switch event.InteractionType {
+		case "like", "dislike":
+			reaction = event
+		case InteractionUnlike, InteractionUndislike:
+			if reaction != nil && retractionTypes[reaction.InteractionType] == event.InteractionType {
+				reaction = nil
+			}
+		case "rating":
+			if event.Rating != nil {
+				rating = event
+			}
+		case InteractionUnrate:
+			rating = nil
+		default:
+			score += policy.Weights[event.InteractionType] * decayFactor(now.Sub(event.CreatedAt), policy.HalfLife)
+		}
+	}
+
+	if reaction != nil {
+		score += policy.Weights[reaction.InteractionType] * decayFactor(now.Sub(reaction.CreatedAt), policy.HalfLife)
+	}
+	if rating != nil {
+		score += (*rating.Rating - NeutralRating) * policy.Weights["rating"] * decayFactor(now.Sub(rating.CreatedAt), policy.HalfLife)
+	}
+	return score
+}
+
+// decayFactor retorna 0.5^(age/halfLife); sem meia-vida não há decaimento
+func decayFactor(age, halfLife time.Duration) float64 {
+	if halfLife <= 0 || age <= 0 {
+		return 1
+	}
+	return math.Pow(0.5, age.Hours()/halfLife.Hours())
+}
+
+// currentAffinity aplica à afinidade gravada o decaimento desde o cálculo
+func currentAffinity(affinity *models.UserContentAffinity, now time.Time) UserAffinity {
+	halfLife := time.Duration(affinity.HalfLifeHours * float64(time.Hour))
+	return UserAffinity{
+		ContentID:   affinity.ContentID,
+		Score:       affinity.Score * decayFactor(now.Sub(affinity.ComputedAt), halfLife),
+		LastEventAt: affinity.LastEventAt,
+	}
+}
+
+// GetUserAffinities retorna as afinidades atuais do usuário, da maior para a menor
+func (s *affinityService) GetUserAffinities(userID uint, query AffinityQuery) ([]UserAffinity, int64, error) {
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		return nil, 0, err
+	}
+	query.Normalize()
+
+	stored, err := s.repo.ListByUserID(userID)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// O decaimento depende de quando cada linha foi calculada, então a
+	// ordenação é feita em memória sobre os valores atuais
+	now := time.Now()
+	affinities := make([]UserAffinity, 0, len(stored))
+	for i := range stored {
+		affinity := currentAffinity(&stored[i], now)
+		if query.MinScore != nil && affinity.Score < *query.MinScore {
+			continue
+		}
+		affinities = append(affinities, affinity)
+	}
+	sort.Slice(affinities, func(i, j int) bool {
+		if affinities[i].Score != affinities[j].Score {
+			return affinities[i].Score > affinities[j].Score
+		}
+		return affinities[i].ContentID < affinities[j].ContentID
+	})
+
+	total := int64(len(affinities))
+	start := (query.Page - 1) * query.Limit
+	if start >= len(affinities) {
+		return []UserAffinity{}, total, nil
+	}
+	end := start + query.Limit
+	if end > len(affinities) {
+		end = len(affinities)
+	}
+	return affinities[start:end], total, nil
+}
+
+// GetAffinity retorna a afinidade atual do usuário com um conteúdo; zero se
+// não houver interações entre eles
+func (s *affinityService) GetAffinity(userID, contentID uint) (*UserAffinity, error) {
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		return nil, err
+	}
+	if _, err := s.contentRepo.GetByID(contentID); err != nil {
+		return nil, err
+	}
+
+	stored, err := s.repo.Get(userID, contentID)
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		return &UserAffinity{ContentID: contentID}, nil
+	}
+	affinity := currentAffinity(stored, time.Now())
+	return &affinity, nil
+}
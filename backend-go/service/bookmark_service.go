@@ -0,0 +1,116 @@
+package service
+
+import (
+	"backend-go/models"
+	"backend-go/repository"
+	"errors"
+)
+
+// BookmarkService define a interface para a lista "salvar para depois"
+type BookmarkService interface {
+	AddBookmark(userID, contentID uint) (*models.Bookmark, bool, error)
+	RemoveBookmark(userID, contentID uint) error
+	ListBookmarks(userID uint, page, limit int) ([]models.Bookmark, int64, error)
+	BookmarkedSet(userID uint, contentIDs []uint) (map[uint]bool, error)
+}
+
+type bookmarkService struct {
+	repo               repository.BookmarkRepository
+	interactionService InteractionService
+}
+
+// NewBookmarkService cria uma nova instância do BookmarkService
+func NewBookmarkService(
+	repo repository.BookmarkRepository,
+	interactionService InteractionService,
+) BookmarkService {
+	return &bookmarkService{
+		repo:               repo,
+		interactionService: interactionService,
+	}
+}
+
+// AddBookmark salva um conteúdo para o usuário.
+// O segundo retorno indica se o conteúdo foi salvo agora (false se já estava salvo).
+func (s *bookmarkService) AddBookmark(userID, contentID uint) (*models.Bookmark, bool, error) {
+	if userID == 0 || contentID == 0 {
+		return nil, false, errors.New("ID inválido")
+	}
+
+	bookmark := &models.Bookmark{
+		UserID:    userID,
+		ContentID: contentID,
+	}
+	saved, err := s.isBookmarked(userID, contentID)
+	if err != nil {
+		return nil, false, err
+	}
+	if saved {
+		return bookmark, false, nil
+	}
+
+	// Salvar é um feedback implícito positivo: a interação "bookmark" entra
+	// no log lido pelo motor Python, pela afinidade e pelo item-item, e
+	// invalida o cache de recomendações do usuário
+	if _, err := s.interactionService.CreateBookmark(bookmark); err != nil {
+		// Outra requisição pode ter salvo o conteúdo depois da verificação
+		if saved, checkErr := s.isBookmarked(userID, contentID); checkErr == nil && saved {
+			return bookmark, false, nil
+		}
+		return nil, false, err
+	}
+
+	return bookmark, true, nil
+}
+
+func (s *bookmarkService) isBookmarked(userID, contentID uint) (bool, error) {
+	ids, err := s.repo.GetBookmarkedIDs(userID, []uint{contentID})
+	if err != nil {
+		return false, err
+	}
+	return len(ids) > 0, nil
+}
+
+// RemoveBookmark remove um conteúdo salvo
+func (s *bookmarkService) RemoveBookmark(userID, contentID uint) error {
+	if userID == 0 || contentID == 0 {
+		return errors.New("ID inválido")
+	}
+	return s.repo.Delete(userID, contentID)
+}
+
+// ListBookmarks lista os conteúdos salvos com paginação
+func (s *bookmarkService) ListBookmarks(userID uint, page, limit int) ([]models.Bookmark, int64, error) {
+	if userID == 0 {
+		return nil, 0, errors.New("ID inválido")
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	return s.repo.ListByUserID(userID, limit, (page-1)*limit)
+}
+
+// BookmarkedSet indica quais dos conteúdos informados estão salvos pelo usuário
+func (s *bookmarkService) BookmarkedSet(userID uint, contentIDs []uint) (map[uint]bool, error) {
+	set := make(map[uint]bool)
+	if userID == 0 || len(contentIDs) == 0 {
+		return set, nil
+	}
+
+	ids, err := s.repo.GetBookmarkedIDs(userID, contentIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		set[id] = true
+	}
+
+	return set, nil
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"backend-go/models"
+	"backend-go/repository"
+)
+
+// fakeBookmarkRepository guarda os conteúdos salvos em memória. As primeiras
+// staleChecks consultas não enxergam os salvos, como uma requisição
+// concorrente que salvou o conteúdo logo depois da verificação.
+type fakeBookmarkRepository struct {
+	repository.BookmarkRepository
+	saved       map[userContentKey]bool
+	staleChecks int
+}
+
+func (r *fakeBookmarkRepository) GetBookmarkedIDs(userID uint, contentIDs []uint) ([]uint, error) {
+	if r.staleChecks > 0 {
+		r.staleChecks--
+		return nil, nil
+	}
+	var ids []uint
+	for _, id := range contentIDs {
+		if r.saved[userContentKey{userID, id}] {
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// bookmarkInteractionRepository grava o bookmark recebido em related como a
+// transação real, falhando se ele já existe (chave primária duplicada)
+type bookmarkInteractionRepository struct {
+	*fakeInteractionRepository
+	bookmarks *fakeBookmarkRepository
+}
+
+func (r *bookmarkInteractionRepository) CreateWithReactions(interactions []*models.UserInteraction, reactions []repository.ReactionUpdate, outbox repository.OutboxFunc, related ...interface{}) error {
+	for _, record := range related {
+		if bookmark, ok := record.(*models.Bookmark); ok {
+			key := userContentKey{bookmark.UserID, bookmark.ContentID}
+			if r.bookmarks.saved[key] {
+				return errors.New("chave duplicada em bookmarks")
+			}
+			r.bookmarks.saved[key] = true
+		}
+	}
+	return r.fakeInteractionRepository.CreateWithReactions(interactions, reactions, outbox, related...)
+}
+
+func TestAddBookmark(t *testing.T) {
+	tests := []struct {
+		name        string
+		alreadySet  bool
+		staleChecks int
+		wantCreated bool
+		// Interações "bookmark" gravadas e eventos enfileirados para o motor
+		wantInteractions int
+		wantRecommender  int
+	}{
+		{
+			name:             "salvar grava a interação bookmark e notifica o motor",
+			wantCreated:      true,
+			wantInteractions: 1,
+			wantRecommender:  1,
+		},
+		{
+			name:       "conteúdo já salvo não gera nova interação",
+			alreadySet: true,
+		},
+		{
+			name:        "salvo concorrente entre a verificação e a gravação",
+			alreadySet:  true,
+			staleChecks: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bookmarks := &fakeBookmarkRepository{saved: make(map[userContentKey]bool), staleChecks: tt.staleChecks}
+			if tt.alreadySet {
+				bookmarks.saved[userContentKey{1, 2}] = true
+			}
+			repo := &bookmarkInteractionRepository{fakeInteractionRepository: newFakeInteractionRepository(), bookmarks: bookmarks}
+			svc := NewBookmarkService(bookmarks, newTestInteractionService(repo))
+
+			bookmark, created, err := svc.AddBookmark(1, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if created != tt.wantCreated || bookmark == nil {
+				t.Errorf("created = %v, esperado %v", created, tt.wantCreated)
+			}
+			if !bookmarks.saved[userContentKey{1, 2}] {
+				t.Error("conteúdo não ficou salvo")
+			}
+
+			interactions := 0
+			for _, interaction := range repo.interactions {
+				if interaction.InteractionType == "bookmark" {
+					interactions++
+				}
+			}
+			if interactions != tt.wantInteractions {
+				t.Errorf("interações bookmark = %d, esperado %d", interactions, tt.wantInteractions)
+			}
+			recommender := 0
+			for _, event := range repo.outbox {
+				if event.Topic == models.OutboxTopicRecommenderInteraction {
+					recommender++
+				}
+			}
+			if recommender != tt.wantRecommender {
+				t.Errorf("eventos para o motor = %d, esperado %d", recommender, tt.wantRecommender)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// Estados do circuit breaker
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half_open"
+)
+
+// BreakerStats é o estado atual do circuit breaker
+type BreakerStats struct {
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+	Opens               int64      `json:"opens"`
+}
+
+// circuitBreaker abre após failureThreshold falhas consecutivas e recusa
+// chamadas por openTimeout. Depois disso passa a meio-aberto e deixa passar
+// até halfOpenProbes chamadas de teste: um sucesso fecha o circuito, uma
+// falha o abre de novo.
+type circuitBreaker struct {
+	mu               sync.Mutex
+	failureThreshold int
+	openTimeout      time.Duration
+	halfOpenProbes   int
+
+	state    string
+	failures int
+	openedAt time.Time
+	probes   int
+	opens    int64
+}
+
+func newCircuitBreaker(failureThreshold int, openTimeout time.Duration, halfOpenProbes int) *circuitBreaker {
+	if failureThreshold <= 0 {
+		failureThreshold = 5
+	}
+	if halfOpenProbes <= 0 {
+		halfOpenProbes = 1
+	}
+	return &circuitBreaker{
+		failureThreshold: failureThreshold,
+		openTimeout:      openTimeout,
+		halfOpenProbes:   halfOpenProbes,
+		state:            BreakerClosed,
+	}
+}
+
+// allow informa se a chamada pode seguir e se ela é uma chamada de teste do
+// estado meio-aberto. Toda chamada liberada deve terminar em record.
+func (b *circuitBreaker) allow() (probe, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if time.Since(b.openedAt) < b.openTimeout {
+			return false, false
+		}
+		b.state = BreakerHalfOpen
+		b.probes = 0
+		fallthrough
+	case BreakerHalfOpen:
+		if b.probes >= b.halfOpenProbes {
+			return false, false
+		}
+		b.probes++
+		return true, true
+	default:
+		return false, true
+	}
+}
+
+// record registra o resultado de uma chamada liberada por allow
+func (b *circuitBreaker) record(probe, success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if probe {
+		b.probes--
+	}
+	if success {
+		b.failures = 0
+		if probe && b.state == BreakerHalfOpen {
+			b.state = BreakerClosed
+		}
+		return
+	}
+
+	b.failures++
+	if (probe && b.state == BreakerHalfOpen) || (b.state == BreakerClosed && b.failures >= b.failureThreshold) {
+		b.state = BreakerOpen
+		b.openedAt = time.Now()
+		b.opens++
+	}
+}
+
+func (b *circuitBreaker) stats() BreakerStats {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	stats := BreakerStats{
+		State:               b.state,
+		ConsecutiveFailures: b.failures,
+		Opens:               b.opens,
+	}
+	// Um circuito aberto cujo prazo venceu só passa a meio-aberto na próxima chamada
+	if b.state == BreakerOpen && time.Since(b.openedAt) >= b.openTimeout {
+		stats.State = BreakerHalfOpen
+	}
+	if b.state != BreakerClosed {
+		openedAt := b.openedAt
+		stats.OpenedAt = &openedAt
+	}
+	return stats
+}
@@ -0,0 +1,328 @@
+package service
+
+import (
+	"backend-go/models"
+	"backend-go/repository"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// CollectionService define a interface para operações de coleções
+type CollectionService interface {
+	CreateCollection(req CreateCollectionRequest) (*models.Collection, error)
+	GetCollection(id, viewerID uint) (*models.Collection, error)
+	GetSharedCollection(slug string) (*models.Collection, error)
+	ListCollections(ownerID *uint, viewerID uint, editorialOnly bool, page, limit int) ([]models.Collection, int64, error)
+	UpdateCollection(id, userID uint, req UpdateCollectionRequest) (*models.Collection, error)
+	DeleteCollection(id, userID uint) error
+	AddItem(id, userID, contentID uint) (*models.Collection, error)
+	RemoveItem(id, userID, contentID uint) (*models.Collection, error)
+	ReorderItems(id, userID uint, contentIDs []uint) (*models.Collection, error)
+}
+
+type collectionService struct {
+	repo        repository.CollectionRepository
+	contentRepo repository.ContentRepository
+	userRepo    repository.UserRepository
+}
+
+// CreateCollectionRequest representa os dados necessários para criar uma coleção
+type CreateCollectionRequest struct {
+	OwnerID     uint   `json:"owner_id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Visibility  string `json:"visibility"`
+	Editorial   bool   `json:"editorial"`
+}
+
+// UpdateCollectionRequest representa os dados necessários para atualizar uma coleção
+type UpdateCollectionRequest struct {
+	Name        *string `json:"name,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Visibility  *string `json:"visibility,omitempty"`
+}
+
+// NewCollectionService cria uma nova instância do CollectionService
+func NewCollectionService(
+	repo repository.CollectionRepository,
+	contentRepo repository.ContentRepository,
+	userRepo repository.UserRepository,
+) CollectionService {
+	return &collectionService{
+		repo:        repo,
+		contentRepo: contentRepo,
+		userRepo:    userRepo,
+	}
+}
+
+// CreateCollection cria uma nova coleção após validar os dados
+func (s *collectionService) CreateCollection(req CreateCollectionRequest) (*models.Collection, error) {
+	if req.Visibility == "" {
+		req.Visibility = models.CollectionPrivate
+	}
+	if err := validateCollection(req.Name, req.Description, req.Visibility); err != nil {
+		return nil, err
+	}
+
+	owner, err := s.userRepo.GetByID(req.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Editorial && owner.Role != models.RoleEditor && owner.Role != models.RoleAdmin {
+		return nil, errors.New("apenas editores podem criar coleções editoriais")
+	}
+
+	collection := &models.Collection{
+		OwnerID:     owner.ID,
+		Name:        req.Name,
+		Description: req.Description,
+		Visibility:  req.Visibility,
+		Editorial:   req.Editorial,
+	}
+	// O slug usa o ID para ser único mesmo com nomes repetidos
+	if err := s.repo.Create(collection, func(id uint) string {
+		return collectionSlug(collection.Name, id)
+	}); err != nil {
+		return nil, err
+	}
+
+	return collection, nil
+}
+
+// GetCollection busca uma coleção visível ao usuário
+func (s *collectionService) GetCollection(id, viewerID uint) (*models.Collection, error) {
+	if id == 0 {
+		return nil, errors.New("ID inválido")
+	}
+
+	collection, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if collection.Visibility != models.CollectionPublic && collection.OwnerID != viewerID {
+		return nil, errors.New("coleção não encontrada")
+	}
+
+	return collection, nil
+}
+
+// GetSharedCollection busca uma coleção pública pelo slug compartilhável
+func (s *collectionService) GetSharedCollection(slug string) (*models.Collection, error) {
+	collection, err := s.repo.GetBySlug(slug)
+	if err != nil {
+		return nil, err
+	}
+	if collection.Visibility != models.CollectionPublic {
+		return nil, errors.New("coleção não encontrada")
+	}
+	return collection, nil
+}
+
+// ListCollections lista coleções visíveis com paginação e filtros
+func (s *collectionService) ListCollections(ownerID *uint, viewerID uint, editorialOnly bool, page, limit int) ([]models.Collection, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	return s.repo.List(ownerID, viewerID, editorialOnly, limit, (page-1)*limit)
+}
+
+// UpdateCollection atualiza os dados de uma coleção do próprio dono
+func (s *collectionService) UpdateCollection(id, userID uint, req UpdateCollectionRequest) (*models.Collection, error) {
+	collection, err := s.ownedCollection(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	name, description, visibility := collection.Name, collection.Description, collection.Visibility
+	if req.Name != nil {
+		name = *req.Name
+	}
+	if req.Description != nil {
+		description = *req.Description
+	}
+	if req.Visibility != nil {
+		visibility = *req.Visibility
+	}
+	if err := validateCollection(name, description, visibility); err != nil {
+		return nil, err
+	}
+
+	if name != collection.Name {
+		collection.Slug = collectionSlug(name, collection.ID)
+	}
+	collection.Name = name
+	collection.Description = description
+	collection.Visibility = visibility
+
+	if err := s.repo.Update(collection); err != nil {
+		return nil, err
+	}
+
+	return collection, nil
+}
+
+// DeleteCollection remove uma coleção do próprio dono
+func (s *collectionService) DeleteCollection(id, userID uint) error {
+	if _, err := s.ownedCollection(id, userID); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+// AddItem adiciona um conteúdo ao final da coleção
+func (s *collectionService) AddItem(id, userID, contentID uint) (*models.Collection, error) {
+	collection, err := s.ownedCollection(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range collection.Items {
+		if item.ContentID == contentID {
+			return nil, errors.New("conteúdo já está na coleção")
+		}
+	}
+
+	if _, err := s.contentRepo.GetByID(contentID); err != nil {
+		return nil, err
+	}
+
+	position, err := s.repo.MaxPosition(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.AddItem(&models.CollectionItem{
+		CollectionID: id,
+		ContentID:    contentID,
+		Position:     position + 1,
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.touch(collection)
+}
+
+// RemoveItem remove um conteúdo da coleção
+func (s *collectionService) RemoveItem(id, userID, contentID uint) (*models.Collection, error) {
+	collection, err := s.ownedCollection(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.RemoveItem(id, contentID); err != nil {
+		return nil, err
+	}
+
+	return s.touch(collection)
+}
+
+// ReorderItems define a nova ordem dos itens. A lista deve conter
+// exatamente os conteúdos que já estão na coleção.
+func (s *collectionService) ReorderItems(id, userID uint, contentIDs []uint) (*models.Collection, error) {
+	collection, err := s.ownedCollection(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(contentIDs) != len(collection.Items) {
+		return nil, errors.New("a nova ordem deve conter todos os itens da coleção")
+	}
+	current := make(map[uint]bool, len(collection.Items))
+	for _, item := range collection.Items {
+		current[item.ContentID] = true
+	}
+	for _, contentID := range contentIDs {
+		if !current[contentID] {
+			return nil, errors.New("a nova ordem deve conter todos os itens da coleção")
+		}
+		delete(current, contentID)
+	}
+
+	if err := s.repo.ReorderItems(id, contentIDs); err != nil {
+		return nil, err
+	}
+
+	return s.touch(collection)
+}
+
+// ownedCollection busca uma coleção e garante que pertence ao usuário
+func (s *collectionService) ownedCollection(id, userID uint) (*models.Collection, error) {
+	if id == 0 || userID == 0 {
+		return nil, errors.New("ID inválido")
+	}
+
+	collection, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if collection.OwnerID != userID {
+		return nil, errors.New("apenas o dono pode alterar a coleção")
+	}
+
+	return collection, nil
+}
+
+// touch atualiza a data de modificação e recarrega a coleção com os itens
+func (s *collectionService) touch(collection *models.Collection) (*models.Collection, error) {
+	if err := s.repo.Update(collection); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByID(collection.ID)
+}
+
+// validateCollection valida os dados de uma coleção
+func validateCollection(name, description, visibility string) error {
+	if strings.TrimSpace(name) == "" {
+		return errors.New("nome é obrigatório")
+	}
+	if len(name) > 120 {
+		return errors.New("nome deve ter no máximo 120 caracteres")
+	}
+	if len(description) > 1000 {
+		return errors.New("descrição deve ter no máximo 1000 caracteres")
+	}
+	if visibility != models.CollectionPublic && visibility != models.CollectionPrivate {
+		return errors.New("visibilidade inválida. Valores válidos: public, private")
+	}
+	return nil
+}
+
+// collectionSlug gera um slug legível, sem acentos, a partir do nome da coleção
+func collectionSlug(name string, id uint) string {
+	var b strings.Builder
+	lastDash := true
+	for _, r := range norm.NFD.String(strings.ToLower(name)) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if r < unicode.MaxASCII {
+				b.WriteRune(r)
+				lastDash = false
+			}
+		case !lastDash:
+			b.WriteByte('-')
+			lastDash = true
+		}
+	}
+
+	slug := strings.TrimSuffix(b.String(), "-")
+	if len(slug) > 160 {
+		slug = strings.TrimSuffix(slug[:160], "-")
+	}
+	if slug == "" {
+		return fmt.Sprintf("%d", id)
+	}
+	return fmt.Sprintf("%s-%d", slug, id)
+}
@@ -0,0 +1,206 @@
+package service
+
+import (
+	"backend-go/models"
+	"backend-go/repository"
+	"errors"
+	"strings"
+)
+
+// CommentThread agrupa um comentário de primeiro nível e suas respostas
+type CommentThread struct {
+	Comment models.Comment
+	Replies []models.Comment
+}
+
+// CommentService define a interface para operações de comentários
+type CommentService interface {
+	CreateComment(contentID, userID uint, parentID *uint, body string) (*models.Comment, error)
+	ListContentComments(contentID uint, page, limit int) ([]CommentThread, int64, error)
+	CountContentComments(contentID uint) (int64, error)
+	UpdateComment(id, userID uint, body string) (*models.Comment, error)
+	DeleteComment(id, userID uint) error
+}
+
+type commentService struct {
+	repo               repository.CommentRepository
+	contentRepo        repository.ContentRepository
+	interactionService InteractionService
+}
+
+// NewCommentService cria uma nova instância do CommentService
+func NewCommentService(
+	repo repository.CommentRepository,
+	contentRepo repository.ContentRepository,
+	interactionService InteractionService,
+) CommentService {
+	return &commentService{
+		repo:               repo,
+		contentRepo:        contentRepo,
+		interactionService: interactionService,
+	}
+}
+
+// CreateComment cria um comentário (ou resposta) e registra a interação
+// "comment" na mesma transação
+func (s *commentService) CreateComment(contentID, userID uint, parentID *uint, body string) (*models.Comment, error) {
+	if contentID == 0 || userID == 0 {
+		return nil, errors.New("ID inválido")
+	}
+	body, err := validateCommentBody(body)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.contentRepo.GetByID(contentID); err != nil {
+		return nil, err
+	}
+
+	comment := &models.Comment{
+		ContentID: contentID,
+		UserID:    userID,
+		Body:      body,
+	}
+
+	if parentID != nil {
+		parent, err := s.repo.GetByID(*parentID)
+		if err != nil {
+			return nil, err
+		}
+		if parent.ContentID != contentID {
+			return nil, errors.New("comentário pai pertence a outro conteúdo")
+		}
+		rootID := parent.ID
+		if parent.RootID != nil {
+			rootID = *parent.RootID
+		}
+		comment.ParentID = &parent.ID
+		comment.RootID = &rootID
+	}
+
+	// Mantém o sinal de comentário para o motor de recomendação
+	if _, err := s.interactionService.CreateComment(comment); err != nil {
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+// ListContentComments lista as threads de um conteúdo com paginação
+func (s *commentService) ListContentComments(contentID uint, page, limit int) ([]CommentThread, int64, error) {
+	if contentID == 0 {
+		return nil, 0, errors.New("ID inválido")
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	roots, total, err := s.repo.ListRootsByContentID(contentID, limit, (page-1)*limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rootIDs := make([]uint, len(roots))
+	for i, root := range roots {
+		rootIDs[i] = root.ID
+	}
+
+	replies, err := s.repo.ListByRootIDs(rootIDs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	repliesByRoot := make(map[uint][]models.Comment, len(roots))
+	for _, reply := range replies {
+		repliesByRoot[*reply.RootID] = append(repliesByRoot[*reply.RootID], reply)
+	}
+
+	threads := make([]CommentThread, len(roots))
+	for i, root := range roots {
+		threads[i] = CommentThread{
+			Comment: root,
+			Replies: repliesByRoot[root.ID],
+		}
+	}
+
+	return threads, total, nil
+}
+
+// CountContentComments retorna o número de comentários de um conteúdo
+func (s *commentService) CountContentComments(contentID uint) (int64, error) {
+	if contentID == 0 {
+		return 0, errors.New("ID inválido")
+	}
+	return s.repo.CountByContentID(contentID)
+}
+
+// UpdateComment edita o texto de um comentário do próprio autor
+func (s *commentService) UpdateComment(id, userID uint, body string) (*models.Comment, error) {
+	comment, err := s.authorComment(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err = validateCommentBody(body)
+	if err != nil {
+		return nil, err
+	}
+
+	comment.Body = body
+	if err := s.repo.Update(comment); err != nil {
+		return nil, err
+	}
+
+	return comment, nil
+}
+
+// DeleteComment remove um comentário do próprio autor.
+// O registro é mantido sem texto para não quebrar as respostas da thread.
+func (s *commentService) DeleteComment(id, userID uint) error {
+	comment, err := s.authorComment(id, userID)
+	if err != nil {
+		return err
+	}
+
+	comment.Body = ""
+	comment.Deleted = true
+	return s.repo.Update(comment)
+}
+
+// authorComment busca um comentário ativo e garante que pertence ao usuário
+func (s *commentService) authorComment(id, userID uint) (*models.Comment, error) {
+	if id == 0 || userID == 0 {
+		return nil, errors.New("ID inválido")
+	}
+
+	comment, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if comment.Deleted {
+		return nil, errors.New("comentário não encontrado")
+	}
+	if comment.UserID != userID {
+		return nil, errors.New("apenas o autor pode alterar o comentário")
+	}
+
+	return comment, nil
+}
+
+// validateCommentBody normaliza e valida o texto de um comentário
+func validateCommentBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", errors.New("texto do comentário é obrigatório")
+	}
+	if len(body) > 2000 {
+		return "", errors.New("comentário deve ter no máximo 2000 caracteres")
+	}
+	return body, nil
+}
@@ -14,10 +14,12 @@ type ContentService interface {
 	ListContents(page, limit int, contentType *string) ([]models.Content, int64, error)
 	UpdateContent(id uint, req UpdateContentRequest) (*models.Content, error)
 	DeleteContent(id uint) error
+	GetContentsByIDs(ids []uint, withCategories bool) ([]models.Content, error)
 }
 
 type contentService struct {
-	repo repository.ContentRepository
+	repo   repository.ContentRepository
+	events EventEmitter
 }
 
 // CreateContentRequest representa os dados necessários para criar um conteúdo
@@ -38,9 +40,10 @@ type UpdateContentRequest struct {
 	CategoryIDs []uint     `json:"category_ids,omitempty"`
 }
 
-// NewContentService cria uma nova instância do ContentService
-func NewContentService(repo repository.ContentRepository) ContentService {
-	return &contentService{repo: repo}
+// NewContentService cria uma nova instância do ContentService.
+// Criações, atualizações e remoções são publicadas em events.
+func NewContentService(repo repository.ContentRepository, events EventEmitter) ContentService {
+	return &contentService{repo: repo, events: events}
 }
 
 // Tipos de conteúdo válidos
@@ -68,10 +71,15 @@ func (s *contentService) CreateContent(req CreateContentRequest) (*models.Conten
 
 	// Se houver categorias, associa (será feito via relacionamento many-to-many)
 	// Por enquanto, criamos o conteúdo primeiro
-	if err := s.repo.Create(content); err != nil {
+	// Os eventos dos webhooks e do barramento são gravados no outbox na mesma transação
+	if err := s.repo.Create(content, domainEventOutbox(EventContentPublished, func() (interface{}, string) {
+		return newContentEventData(content), contentAggregateKey(content.ID)
+	})); err != nil {
 		return nil, err
 	}
 
+	s.events.Emit(EventContentPublished, newContentEventData(content))
+
 	return content, nil
 }
 
@@ -154,10 +162,14 @@ func (s *contentService) UpdateContent(id uint, req UpdateContentRequest) (*mode
 	}
 
 	// Atualiza no banco
-	if err := s.repo.Update(content); err != nil {
+	if err := s.repo.Update(content, domainEventOutbox(EventContentUpdated, func() (interface{}, string) {
+		return newContentEventData(content), contentAggregateKey(content.ID)
+	})); err != nil {
 		return nil, err
 	}
 
+	s.events.Emit(EventContentUpdated, newContentEventData(content))
+
 	return content, nil
 }
 
@@ -167,7 +179,14 @@ func (s *contentService) DeleteContent(id uint) error {
 		return errors.New("ID inválido")
 	}
 
-	return s.repo.Delete(id)
+	if err := s.repo.Delete(id, domainEventOutbox(EventContentDeleted, func() (interface{}, string) {
+		return ContentDeletedEventData{ID: id}, contentAggregateKey(id)
+	})); err != nil {
+		return err
+	}
+
+	s.events.Emit(EventContentDeleted, ContentDeletedEventData{ID: id})
+	return nil
 }
 
 // validateContentRequest valida os dados de criação de conteúdo
@@ -201,3 +220,24 @@ func (s *contentService) validateContentRequest(title, description, contentType
 	return nil
 }
 
+
+// GetContentsByIDs busca vários conteúdos de uma vez, na ordem dos IDs
+// informados. IDs de conteúdos inexistentes são ignorados.
+func (s *contentService) GetContentsByIDs(ids []uint, withCategories bool) ([]models.Content, error) {
+	contents, err := s.repo.GetByIDs(ids, withCategories)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]*models.Content, len(contents))
+	for i := range contents {
+		byID[contents[i].ID] = &contents[i]
+	}
+	ordered := make([]models.Content, 0, len(contents))
+	for _, id := range ids {
+		if content, ok := byID[id]; ok {
+			ordered = append(ordered, *content)
+		}
+	}
+	return ordered, nil
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"backend-go/eventbus"
+	"backend-go/models"
+	"backend-go/repository"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Tipos de evento publicados apenas no barramento
+const (
+	EventRecommendationServed   = "recommendation.served"
+	EventRecommenderInteraction = "recommender.interaction"
+)
+
+// Formas de entregar ao motor Python as interações do outbox
+const (
+	RecommenderDeliveryHTTP = "http"
+	RecommenderDeliveryBus  = "bus"
+)
+
+// RecommendationServedEventData descreve as recomendações servidas a um usuário
+type RecommendationServedEventData struct {
+	UserID     uint      `json:"user_id"`
+	Method     string    `json:"method"`
+	ContentIDs []uint    `json:"content_ids"`
+	ServedAt   time.Time `json:"served_at"`
+}
+
+// busOutboxPayload é o payload dos eventos do outbox destinados ao
+// barramento: o envelope já validado e o subject em que será publicado
+type busOutboxPayload struct {
+	Subject string          `json:"subject"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// newBusOutboxEvent monta o evento do outbox que publica um evento de domínio
+// no barramento. O envelope é conferido contra o schema aqui, na escrita, e
+// não no worker. Eventos com a mesma aggregateKey são publicados em ordem.
+func newBusOutboxEvent(eventType string, data interface{}, aggregateKey string) (*models.OutboxEvent, error) {
+	subject, envelope, err := eventbus.NewEnvelope(eventType, data)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(busOutboxPayload{Subject: subject, Data: envelope})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar evento do outbox: %w", err)
+	}
+	return &models.OutboxEvent{
+		Topic:        models.OutboxTopicBusEvent,
+		AggregateKey: aggregateKey,
+		Payload:      string(payload),
+	}, nil
+}
+
+// contentAggregateKey agrupa os eventos de um conteúdo
+func contentAggregateKey(contentID uint) string {
+	return fmt.Sprintf("content:%d", contentID)
+}
+
+// userAggregateKey agrupa os eventos de um usuário
+func userAggregateKey(userID uint) string {
+	return fmt.Sprintf("user:%d", userID)
+}
+
+// interactionAggregateKey agrupa os eventos de um par usuário-conteúdo
+func interactionAggregateKey(userID, contentID uint) string {
+	return fmt.Sprintf("user:%d|content:%d", userID, contentID)
+}
+
+// EventBusRelay publica no barramento os eventos de domínio gravados no outbox
+type EventBusRelay interface {
+	Dispatch() (int, error)
+}
+
+type eventBusRelay struct {
+	repo   repository.OutboxRepository
+	bus    eventbus.Bus
+	policy OutboxPolicy
+	mu     sync.Mutex
+}
+
+// NewEventBusRelay cria o worker que publica os eventos do outbox no
+// barramento, com as tentativas e o backoff do outbox
+func NewEventBusRelay(repo repository.OutboxRepository, bus eventbus.Bus, policy OutboxPolicy) EventBusRelay {
+	return &eventBusRelay{
+		repo:   repo,
+		bus:    bus,
+		policy: normalizeOutboxPolicy(policy),
+	}
+}
+
+// Dispatch publica, em ordem de criação, os eventos pendentes cuja próxima
+// tentativa já venceu. Na primeira falha de publicação o evento é reagendado
+// e o lote para, pois o barramento está indisponível. Retorna o número de
+// eventos publicados.
+func (r *eventBusRelay) Dispatch() (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	published := 0
+	for {
+		events, err := r.repo.ListDue(models.OutboxTopicBusEvent, time.Now(), outboxBatchSize)
+		if err != nil {
+			return published, err
+		}
+		if len(events) == 0 {
+			return published, nil
+		}
+
+		ids := make([]uint, 0, len(events))
+		var publishErr error
+		for i := range events {
+			var payload busOutboxPayload
+			if err := json.Unmarshal([]byte(events[i].Payload), &payload); err != nil {
+				// Payload inválido nunca será publicado: vai direto para dead
+				if err := failOutboxEvent(r.repo, r.policy, &events[i], fmt.Errorf("payload inválido: %w", err), true); err != nil {
+					return published, err
+				}
+				continue
+			}
+			if err := r.bus.Publish(payload.Subject, payload.Data); err != nil {
+				publishErr = fmt.Errorf("erro ao publicar %s no barramento: %w", payload.Subject, err)
+				if err := failOutboxEvent(r.repo, r.policy, &events[i], publishErr, false); err != nil {
+					return published, err
+				}
+				break
+			}
+			ids = append(ids, events[i].ID)
+		}
+
+		if err := r.repo.MarkDelivered(ids, time.Now()); err != nil {
+			return published, err
+		}
+		published += len(ids)
+
+		if publishErr != nil {
+			return published, publishErr
+		}
+		if len(events) < outboxBatchSize {
+			return published, nil
+		}
+	}
+}
+
+type busInteractionDelivery struct {
+	bus eventbus.Bus
+}
+
+// NewBusInteractionDelivery cria uma InteractionDelivery que publica as
+// interações do outbox em recommender.interaction, para o motor Python
+// consumir do broker em vez de ser chamado por HTTP
+func NewBusInteractionDelivery(bus eventbus.Bus) InteractionDelivery {
+	return &busInteractionDelivery{bus: bus}
+}
+
+// DeliverInteractions publica cada interação do lote. Se uma publicação
+// falhar, o outbox repete o lote inteiro; consumidores devem tolerar
+// mensagens repetidas.
+func (d *busInteractionDelivery) DeliverInteractions(interactions []InteractionRequest) error {
+	for _, interaction := range interactions {
+		subject, payload, err := eventbus.NewEnvelope(EventRecommenderInteraction, interaction)
+		if err != nil {
+			return err
+		}
+		if err := d.bus.Publish(subject, payload); err != nil {
+			return fmt.Errorf("erro ao publicar interação no barramento: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StreamEvent é um evento de atividade entregue pelo stream SSE
+type StreamEvent struct {
+	ID        uint64          `json:"id"`
+	Type      string          `json:"type"`
+	ContentID uint            `json:"content_id,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data" swaggertype:"object"`
+}
+
+// StreamFilter restringe os eventos entregues a uma conexão.
+// Campos vazios não filtram.
+type StreamFilter struct {
+	Types     map[string]bool
+	ContentID uint
+}
+
+// Matches indica se o evento passa pelo filtro
+func (f StreamFilter) Matches(event *StreamEvent) bool {
+	if len(f.Types) > 0 && !f.Types[event.Type] {
+		return false
+	}
+	if f.ContentID != 0 && event.ContentID != f.ContentID {
+		return false
+	}
+	return true
+}
+
+// ParseStreamFilter valida os tipos de evento (separados por vírgula) e o
+// conteúdo informados na conexão
+func ParseStreamFilter(types string, contentID uint) (StreamFilter, error) {
+	filter := StreamFilter{ContentID: contentID}
+	for _, eventType := range strings.Split(types, ",") {
+		eventType = strings.TrimSpace(eventType)
+		if eventType == "" {
+			continue
+		}
+		if !validWebhookEventTypes[eventType] {
+			return StreamFilter{}, errors.New("tipo de evento inválido: " + eventType)
+		}
+		if filter.Types == nil {
+			filter.Types = make(map[string]bool)
+		}
+		filter.Types[eventType] = true
+	}
+	return filter, nil
+}
+
+// StreamPolicy define o tamanho do histórico e da fila de cada conexão
+type StreamPolicy struct {
+	// Eventos mantidos em memória para retomada via Last-Event-ID
+	BufferSize int
+	// Eventos pendentes por conexão antes de ela ser desconectada
+	ClientBufferSize int
+}
+
+// StreamSubscription é uma conexão registrada no stream. Events é fechado
+// quando a conexão é removida ou quando o cliente não acompanha o ritmo dos
+// eventos; nesse caso Dropped retorna true e o cliente deve reconectar
+// informando o último ID recebido.
+type StreamSubscription struct {
+	Events  <-chan *StreamEvent
+	events  chan *StreamEvent
+	filter  StreamFilter
+	dropped bool
+}
+
+// Dropped indica se a conexão foi encerrada por estar lenta demais
+func (s *StreamSubscription) Dropped() bool {
+	return s.dropped
+}
+
+// EventStreamService define a interface do stream de atividade em tempo real
+type EventStreamService interface {
+	EventEmitter
+	// Subscribe registra uma conexão. Com lastEventID > 0, retorna os eventos
+	// do histórico posteriores a ele; missed indica que parte deles já saiu
+	// do histórico e não pode ser reenviada.
+	Subscribe(filter StreamFilter, lastEventID uint64) (sub *StreamSubscription, replay []*StreamEvent, missed bool)
+	Unsubscribe(sub *StreamSubscription)
+}
+
+type eventStreamService struct {
+	policy StreamPolicy
+	mu     sync.Mutex
+	// Histórico circular: ring[next] é o evento mais antigo quando cheio
+	ring        []*StreamEvent
+	next        int
+	full        bool
+	lastID      uint64
+	subscribers map[*StreamSubscription]struct{}
+}
+
+// NewEventStreamService cria uma nova instância do EventStreamService
+func NewEventStreamService(policy StreamPolicy) EventStreamService {
+	if policy.BufferSize < 1 {
+		policy.BufferSize = 1000
+	}
+	if policy.ClientBufferSize < 1 {
+		policy.ClientBufferSize = 64
+	}
+	return &eventStreamService{
+		policy: policy,
+		ring:   make([]*StreamEvent, policy.BufferSize),
+		// IDs partem do relógio para continuarem crescendo após um restart,
+		// assim um Last-Event-ID antigo não coincide com eventos novos
+		lastID:      uint64(time.Now().UnixMicro()),
+		subscribers: make(map[*StreamSubscription]struct{}),
+	}
+}
+
+// Emit registra o evento no histórico e o entrega às conexões cujo filtro
+// ele satisfaz. Nunca bloqueia: uma conexão com a fila cheia é desconectada.
+func (s *eventStreamService) Emit(eventType string, data interface{}) {
+	rawData, err := json.Marshal(data)
+	if err != nil {
+		log.Printf("erro ao serializar evento %s para o stream: %v", eventType, err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lastID++
+	event := &StreamEvent{
+		ID:        s.lastID,
+		Type:      eventType,
+		ContentID: eventContentID(data),
+		CreatedAt: time.Now(),
+		Data:      rawData,
+	}
+	s.ring[s.next] = event
+	s.next = (s.next + 1) % len(s.ring)
+	if s.next == 0 {
+		s.full = true
+	}
+
+	for sub := range s.subscribers {
+		if !sub.filter.Matches(event) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			sub.dropped = true
+			s.remove(sub)
+		}
+	}
+}
+
+// eventContentID extrai o conteúdo a que o evento se refere
+func eventContentID(data interface{}) uint {
+	switch d := data.(type) {
+	case ContentEventData:
+		return d.ID
+	case ContentDeletedEventData:
+		return d.ID
+	case InteractionEventData:
+		return d.ContentID
+	default:
+		return 0
+	}
+}
+
+// Subscribe registra a conexão e monta a retomada sob o mesmo lock da
+// publicação, então nenhum evento é perdido nem repetido entre as duas
+func (s *eventStreamService) Subscribe(filter StreamFilter, lastEventID uint64) (*StreamSubscription, []*StreamEvent, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	events := make(chan *StreamEvent, s.policy.ClientBufferSize)
+	sub := &StreamSubscription{Events: events, events: events, filter: filter}
+	s.subscribers[sub] = struct{}{}
+
+	if lastEventID == 0 || lastEventID >= s.lastID {
+		return sub, nil, false
+	}
+
+	// Os IDs são sequenciais, então houve perda se o evento seguinte ao
+	// último recebido já saiu do histórico
+	history := s.history()
+	missed := len(history) == 0 || history[0].ID > lastEventID+1
+
+	var replay []*StreamEvent
+	for _, event := range history {
+		if event.ID > lastEventID && filter.Matches(event) {
+			replay = append(replay, event)
+		}
+	}
+	return sub, replay, missed
+}
+
+// history retorna o histórico do mais antigo ao mais recente
+func (s *eventStreamService) history() []*StreamEvent {
+	if !s.full {
+		return s.ring[:s.next]
+	}
+	history := make([]*StreamEvent, 0, len(s.ring))
+	history = append(history, s.ring[s.next:]...)
+	return append(history, s.ring[:s.next]...)
+}
+
+// Unsubscribe remove a conexão e fecha seu canal
+func (s *eventStreamService) Unsubscribe(sub *StreamSubscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.remove(sub)
+}
+
+func (s *eventStreamService) remove(sub *StreamSubscription) {
+	if _, ok := s.subscribers[sub]; !ok {
+		return
+	}
+	delete(s.subscribers, sub)
+	close(sub.events)
+}
+
+// multiEmitter repassa cada evento a vários consumidores
+type multiEmitter []EventEmitter
+
+// NewMultiEmitter cria um EventEmitter que publica em todos os emitters informados
+func NewMultiEmitter(emitters ...EventEmitter) EventEmitter {
+	return multiEmitter(emitters)
+}
+
+func (m multiEmitter) Emit(eventType string, data interface{}) {
+	for _, emitter := range m {
+		emitter.Emit(eventType, data)
+	}
+}
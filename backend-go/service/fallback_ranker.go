@@ -0,0 +1,106 @@
+package service
+
+import (
+	"backend-go/repository"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// MethodPopularityFallback identifica as respostas servidas pelo ranking de
+// popularidade e recência quando o motor Python falha
+const MethodPopularityFallback = "popularity_fallback"
+
+// FallbackPolicy define o ranking usado quando o motor está indisponível
+type FallbackPolicy struct {
+	// Janela de interações considerada na popularidade
+	PopularityWindow time.Duration
+	// Pesos por tipo de interação na popularidade
+	Weights map[string]float64
+	// Meia-vida do bônus de recência contado a partir do lançamento
+	RecencyHalfLife time.Duration
+	// Peso do bônus de recência; a popularidade normalizada vale no máximo 1
+	RecencyWeight float64
+}
+
+// FallbackRanker ordena conteúdos sem depender do motor de recomendação
+type FallbackRanker interface {
+	Rank(userID uint, topN int) (*RecommendationResult, error)
+}
+
+type fallbackRanker struct {
+	repo   repository.RankingRepository
+	policy FallbackPolicy
+}
+
+// NewFallbackRanker cria o ranking de popularidade e recência
+func NewFallbackRanker(repo repository.RankingRepository, policy FallbackPolicy) FallbackRanker {
+	return &fallbackRanker{repo: repo, policy: policy}
+}
+
+// Rank combina os conteúdos mais populares da janela com os lançamentos
+// mais recentes que o usuário ainda não consumiu. A pontuação é a
+// popularidade normalizada pela do primeiro colocado somada ao bônus de
+// recência, que cai pela metade a cada meia-vida.
+func (r *fallbackRanker) Rank(userID uint, topN int) (*RecommendationResult, error) {
+	now := time.Now()
+	// Busca candidatos a mais de cada lista para a combinação ter margem
+	limit := topN * 3
+
+	popular, err := r.repo.PopularForUser(userID, now.Add(-r.policy.PopularityWindow), now, r.policy.Weights, limit)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar conteúdos populares: %w", err)
+	}
+	recent, err := r.repo.RecentForUser(userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar conteúdos recentes: %w", err)
+	}
+
+	maxPopularity := 0.0
+	for _, content := range popular {
+		if content.Score > maxPopularity {
+			maxPopularity = content.Score
+		}
+	}
+
+	scores := make(map[uint]float64, len(popular)+len(recent))
+	for _, content := range append(popular, recent...) {
+		if _, ok := scores[content.ContentID]; ok {
+			continue
+		}
+		score := r.policy.RecencyWeight * decayFactor(now.Sub(content.ReleaseDate), r.policy.RecencyHalfLife)
+		scores[content.ContentID] = score
+	}
+	if maxPopularity > 0 {
+		for _, content := range popular {
+			if content.Score > 0 {
+				scores[content.ContentID] += content.Score / maxPopularity
+			}
+		}
+	}
+
+	contentIDs := make([]uint, 0, len(scores))
+	for contentID := range scores {
+		contentIDs = append(contentIDs, contentID)
+	}
+	sort.Slice(contentIDs, func(i, j int) bool {
+		if scores[contentIDs[i]] != scores[contentIDs[j]] {
+			return scores[contentIDs[i]] > scores[contentIDs[j]]
+		}
+		return contentIDs[i] < contentIDs[j]
+	})
+	if len(contentIDs) > topN {
+		contentIDs = contentIDs[:topN]
+	}
+
+	selected := make(map[uint]float64, len(contentIDs))
+	for _, contentID := range contentIDs {
+		selected[contentID] = scores[contentID]
+	}
+	return &RecommendationResult{
+		ContentIDs:   contentIDs,
+		Scores:       selected,
+		Method:       MethodPopularityFallback,
+		ModelVersion: MethodPopularityFallback,
+	}, nil
+}
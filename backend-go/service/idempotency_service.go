@@ -0,0 +1,99 @@
+package service
+
+import (
+	"backend-go/models"
+	"backend-go/repository"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrIdempotencyKeyReused indica que a chave já foi usada com outro payload
+	ErrIdempotencyKeyReused = errors.New("Idempotency-Key já utilizada com um payload diferente")
+	// ErrIdempotencyInProgress indica que a requisição original ainda está em processamento
+	ErrIdempotencyInProgress = errors.New("requisição com esta Idempotency-Key ainda está em processamento")
+)
+
+// IdempotencyService define a interface para deduplicação de requisições de criação
+type IdempotencyService interface {
+	Begin(scope, key, requestHash string) (*models.IdempotencyKey, error)
+	Complete(scope, key string, statusCode int, body []byte) error
+	Release(scope, key string) error
+	PurgeExpired() (int64, error)
+}
+
+type idempotencyService struct {
+	repo repository.IdempotencyRepository
+	ttl  time.Duration
+}
+
+// NewIdempotencyService cria uma nova instância do IdempotencyService.
+// As respostas ficam disponíveis para replay durante ttl.
+func NewIdempotencyService(repo repository.IdempotencyRepository, ttl time.Duration) IdempotencyService {
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	return &idempotencyService{repo: repo, ttl: ttl}
+}
+
+// Begin reserva a chave para uma nova requisição. Se a chave já tiver uma
+// resposta gravada para o mesmo payload, retorna esse registro para replay;
+// caso contrário retorna nil e a requisição deve ser processada normalmente.
+func (s *idempotencyService) Begin(scope, key, requestHash string) (*models.IdempotencyKey, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		existing, err := s.repo.Get(scope, key)
+		if err != nil {
+			return nil, err
+		}
+
+		if existing != nil && existing.ExpiresAt.Before(time.Now()) {
+			if err := s.repo.Delete(scope, key); err != nil {
+				return nil, err
+			}
+			existing = nil
+		}
+
+		if existing != nil {
+			if existing.RequestHash != requestHash {
+				return nil, ErrIdempotencyKeyReused
+			}
+			if existing.StatusCode == 0 {
+				return nil, ErrIdempotencyInProgress
+			}
+			return existing, nil
+		}
+
+		now := time.Now()
+		created, err := s.repo.Create(&models.IdempotencyKey{
+			Scope:       scope,
+			Key:         key,
+			RequestHash: requestHash,
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(s.ttl),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if created {
+			return nil, nil
+		}
+		// Outra requisição reservou a chave entre a leitura e a escrita: relê
+	}
+
+	return nil, ErrIdempotencyInProgress
+}
+
+// Complete grava a resposta original para replays futuros
+func (s *idempotencyService) Complete(scope, key string, statusCode int, body []byte) error {
+	return s.repo.Complete(scope, key, statusCode, body)
+}
+
+// Release libera a chave para que o cliente possa tentar novamente
+func (s *idempotencyService) Release(scope, key string) error {
+	return s.repo.Delete(scope, key)
+}
+
+// PurgeExpired remove as chaves fora da janela de retenção
+func (s *idempotencyService) PurgeExpired() (int64, error) {
+	return s.repo.DeleteExpired(time.Now())
+}
@@ -3,43 +3,801 @@ package service
 import (
 	"backend-go/models"
 	"backend-go/repository"
+	"encoding/json"
 	"errors"
+	"sort"
 	"time"
 )
 
 // InteractionService define a interface para operações de interações
 type InteractionService interface {
-	CreateInteraction(userID, contentID uint, interactionType string, rating *float64) (*models.UserInteraction, error)
-	GetUserInteractions(userID uint) ([]models.UserInteraction, error)
-	GetContentInteractions(contentID uint) ([]models.UserInteraction, error)
+	CreateInteraction(userID, contentID uint, interactionType string, rating *float64, meta InteractionMetadata) (*models.UserInteraction, error)
+	CreateComment(comment *models.Comment) (*models.UserInteraction, error)
+	CreateBookmark(bookmark *models.Bookmark) (*models.UserInteraction, error)
+	CreateInteractionsBatch(items []json.RawMessage) ([]BatchInteractionResult, error)
+	GetUserInteractions(userID uint, query InteractionQuery) ([]models.UserInteraction, int64, error)
+	GetContentInteractions(contentID uint, query InteractionQuery) ([]models.UserInteraction, int64, error)
+	GetFlaggedInteractions(reason string, query InteractionQuery) ([]models.UserInteraction, int64, error)
+	GetReaction(userID, contentID uint) (*models.UserReaction, error)
+	UpdateReaction(userID, contentID uint, reaction *string, rating *float64) (*models.UserReaction, []models.UserInteraction, error)
+	RetractReaction(userID, contentID uint, kind string) (*models.UserReaction, []models.UserInteraction, error)
+}
+
+// Erros retornados quando a interação referencia um usuário ou conteúdo inexistente
+var (
+	ErrUserNotFound    = repository.ErrUserNotFound
+	ErrContentNotFound = repository.ErrContentNotFound
+	// ErrNoReaction indica que não há reação ativa para retratar
+	ErrNoReaction = errors.New("nenhuma reação ativa para remover")
+)
+
+// Eventos de retratação registrados no log quando o usuário desfaz uma reação
+const (
+	InteractionUnlike    = "unlike"
+	InteractionUndislike = "undislike"
+	InteractionUnrate    = "unrate"
+)
+
+// retractionTypes mapeia cada reação para o evento que a desfaz
+var retractionTypes = map[string]string{
+	"like":    InteractionUnlike,
+	"dislike": InteractionUndislike,
+	"rating":  InteractionUnrate,
 }
 
 type interactionService struct {
-	repo repository.InteractionRepository
+	repo        repository.InteractionRepository
+	userRepo    repository.UserRepository
+	contentRepo repository.ContentRepository
+	detector    *abuseDetector
+	events      EventEmitter
+}
+
+// InteractionMetadata descreve o contexto em que a interação aconteceu.
+// Todos os campos são opcionais.
+type InteractionMetadata struct {
+	SessionID  *string `json:"session_id,omitempty"`
+	Device     *string `json:"device,omitempty"`
+	AppVersion *string `json:"app_version,omitempty"`
+	Surface    *string `json:"surface,omitempty"`
+	Position   *int    `json:"position,omitempty"`
+	DwellMs    *int    `json:"dwell_ms,omitempty"`
 }
 
-// NewInteractionService cria uma nova instância do InteractionService
-func NewInteractionService(repo repository.InteractionRepository) InteractionService {
-	return &interactionService{repo: repo}
+// InteractionQuery define filtros e paginação para o histórico de interações
+type InteractionQuery struct {
+	From        *time.Time
+	To          *time.Time
+	Types       []string
+	MinRating   *float64
+	Page        int
+	Limit       int
+	SkipPreload bool
+}
+
+// Tipos de interação válidos
+var validInteractionTypes = map[string]bool{
+	"view":     true,
+	"like":     true,
+	"dislike":  true,
+	"rating":   true,
+	"share":    true,
+	"comment":  true,
+	"bookmark": true,
+}
+
+// Superfícies do aplicativo onde uma interação pode acontecer
+var validSurfaces = map[string]bool{
+	"home":       true,
+	"search":     true,
+	"recs":       true,
+	"collection": true,
+}
+
+// Limites dos campos de contexto
+const (
+	maxInteractionPosition = 1000
+	maxInteractionDwellMs  = 24 * 60 * 60 * 1000
+)
+
+// BatchInteractionItem representa uma interação enfileirada offline pelo aplicativo
+type BatchInteractionItem struct {
+	ClientEventID   string     `json:"client_event_id"`
+	UserID          uint       `json:"user_id"`
+	ContentID       uint       `json:"content_id"`
+	InteractionType string     `json:"interaction_type"`
+	Rating          *float64   `json:"rating,omitempty"`
+	ClientTimestamp *time.Time `json:"client_timestamp,omitempty"`
+	InteractionMetadata
+}
+
+// Status possíveis de um item do lote
+const (
+	BatchItemCreated   = "created"
+	BatchItemDuplicate = "duplicate"
+	BatchItemInvalid   = "invalid"
+	BatchItemFailed    = "failed"
+)
+
+// BatchInteractionResult é o resultado do processamento de um item do lote.
+// Itens "invalid" não devem ser reenviados; itens "failed" podem ser reenviados.
+type BatchInteractionResult struct {
+	Index         int                     `json:"index"`
+	ClientEventID string                  `json:"client_event_id"`
+	Status        string                  `json:"status"`
+	Error         string                  `json:"error,omitempty"`
+	Interaction   *models.UserInteraction `json:"-"`
+}
+
+// Tamanho máximo de um lote de sincronização
+const MaxInteractionBatchSize = 500
+
+// Erros que rejeitam o lote inteiro; os demais problemas são reportados por item
+var (
+	ErrEmptyBatch    = errors.New("lote vazio")
+	ErrBatchTooLarge = errors.New("lote deve ter no máximo 500 interações")
+)
+
+// Janela aceita para o horário informado pelo cliente
+const (
+	maxClientTimestampAge  = 90 * 24 * time.Hour
+	maxClientTimestampSkew = 5 * time.Minute
+)
+
+// NewInteractionService cria uma nova instância do InteractionService.
+// Eventos que violam a abusePolicy são gravados como sinalizados. As
+// interações gravadas são publicadas em events como interaction.created.
+func NewInteractionService(
+	repo repository.InteractionRepository,
+	userRepo repository.UserRepository,
+	contentRepo repository.ContentRepository,
+	abusePolicy AbusePolicy,
+	events EventEmitter,
+) InteractionService {
+	return &interactionService{
+		repo:        repo,
+		userRepo:    userRepo,
+		contentRepo: contentRepo,
+		detector:    newAbuseDetector(repo, abusePolicy),
+		events:      events,
+	}
 }
 
 // CreateInteraction cria uma nova interação
-func (s *interactionService) CreateInteraction(userID, contentID uint, interactionType string, rating *float64) (*models.UserInteraction, error) {
-	// Validar tipo de interação
-	validTypes := map[string]bool{
-		"view":     true,
-		"like":     true,
-		"dislike":  true,
-		"rating":   true,
-		"share":    true,
-		"comment":  true,
-	}
-	if !validTypes[interactionType] {
+func (s *interactionService) CreateInteraction(userID, contentID uint, interactionType string, rating *float64, meta InteractionMetadata) (*models.UserInteraction, error) {
+	interaction, err := newInteraction(userID, contentID, interactionType, rating, meta)
+	if err != nil {
+		return nil, err
+	}
+
+	missingUsers, missingContents, err := s.missingReferences([]uint{userID}, []uint{contentID})
+	if err != nil {
+		return nil, err
+	}
+	if missingUsers[userID] {
+		return nil, ErrUserNotFound
+	}
+	if missingContents[contentID] {
+		return nil, ErrContentNotFound
+	}
+
+	if err := s.record([]*models.UserInteraction{interaction}); err != nil {
+		return nil, err
+	}
+
+	return interaction, nil
+}
+
+// CreateComment grava o comentário e a interação "comment" na mesma
+// transação: se a interação falhar, o comentário também não é gravado
+func (s *interactionService) CreateComment(comment *models.Comment) (*models.UserInteraction, error) {
+	interaction, err := newInteraction(comment.UserID, comment.ContentID, "comment", nil, InteractionMetadata{})
+	if err != nil {
+		return nil, err
+	}
+
+	missingUsers, missingContents, err := s.missingReferences([]uint{comment.UserID}, []uint{comment.ContentID})
+	if err != nil {
+		return nil, err
+	}
+	if missingUsers[comment.UserID] {
+		return nil, ErrUserNotFound
+	}
+	if missingContents[comment.ContentID] {
+		return nil, ErrContentNotFound
+	}
+
+	if err := s.record([]*models.UserInteraction{interaction}, comment); err != nil {
+		return nil, err
+	}
+
+	return interaction, nil
+}
+
+// CreateBookmark grava o conteúdo salvo e a interação "bookmark" na mesma
+// transação, para o motor Python e os modelos locais lerem o sinal do log
+func (s *interactionService) CreateBookmark(bookmark *models.Bookmark) (*models.UserInteraction, error) {
+	interaction, err := newInteraction(bookmark.UserID, bookmark.ContentID, "bookmark", nil, InteractionMetadata{})
+	if err != nil {
+		return nil, err
+	}
+
+	missingUsers, missingContents, err := s.missingReferences([]uint{bookmark.UserID}, []uint{bookmark.ContentID})
+	if err != nil {
+		return nil, err
+	}
+	if missingUsers[bookmark.UserID] {
+		return nil, ErrUserNotFound
+	}
+	if missingContents[bookmark.ContentID] {
+		return nil, ErrContentNotFound
+	}
+
+	if err := s.record([]*models.UserInteraction{interaction}, bookmark); err != nil {
+		return nil, err
+	}
+
+	return interaction, nil
+}
+
+// CreateInteractionsBatch decodifica e valida cada item do lote com as mesmas
+// regras de CreateInteraction e insere os válidos numa única transação. Itens
+// malformados ou inválidos não impedem os demais e são marcados como
+// "invalid"; eventos já recebidos (mesmo client_event_id) são marcados como
+// duplicados.
+func (s *interactionService) CreateInteractionsBatch(rawItems []json.RawMessage) ([]BatchInteractionResult, error) {
+	if len(rawItems) == 0 {
+		return nil, ErrEmptyBatch
+	}
+	if len(rawItems) > MaxInteractionBatchSize {
+		return nil, ErrBatchTooLarge
+	}
+
+	results := make([]BatchInteractionResult, len(rawItems))
+	eventsByUser := make(map[uint][]string)
+	seen := make(map[uint]map[string]bool)
+
+	for i, raw := range rawItems {
+		var item BatchInteractionItem
+		if err := json.Unmarshal(raw, &item); err != nil {
+			results[i] = BatchInteractionResult{Index: i, ClientEventID: batchClientEventID(raw)}
+			results[i].Status = BatchItemInvalid
+			results[i].Error = "item malformado: " + err.Error()
+			continue
+		}
+		results[i] = BatchInteractionResult{Index: i, ClientEventID: item.ClientEventID}
+
+		if item.ClientEventID == "" || len(item.ClientEventID) > 64 {
+			results[i].Status = BatchItemInvalid
+			results[i].Error = "client_event_id é obrigatório e deve ter no máximo 64 caracteres"
+			continue
+		}
+
+		interaction, err := newInteraction(item.UserID, item.ContentID, item.InteractionType, item.Rating, item.InteractionMetadata)
+		if err != nil {
+			results[i].Status = BatchItemInvalid
+			results[i].Error = err.Error()
+			continue
+		}
+
+		if item.ClientTimestamp != nil {
+			now := time.Now()
+			if item.ClientTimestamp.After(now.Add(maxClientTimestampSkew)) || item.ClientTimestamp.Before(now.Add(-maxClientTimestampAge)) {
+				results[i].Status = BatchItemInvalid
+				results[i].Error = "client_timestamp fora da janela aceita"
+				continue
+			}
+			interaction.CreatedAt = *item.ClientTimestamp
+		}
+
+		if seen[item.UserID] == nil {
+			seen[item.UserID] = make(map[string]bool)
+		}
+		if seen[item.UserID][item.ClientEventID] {
+			results[i].Status = BatchItemDuplicate
+			continue
+		}
+		seen[item.UserID][item.ClientEventID] = true
+
+		clientEventID := item.ClientEventID
+		interaction.ClientEventID = &clientEventID
+		results[i].Interaction = interaction
+		eventsByUser[item.UserID] = append(eventsByUser[item.UserID], clientEventID)
+	}
+
+	// Verifica se usuários e conteúdos referenciados existem
+	var userIDs, contentIDs []uint
+	for _, result := range results {
+		if result.Interaction != nil {
+			userIDs = append(userIDs, result.Interaction.UserID)
+			contentIDs = append(contentIDs, result.Interaction.ContentID)
+		}
+	}
+	missingUsers, missingContents, err := s.missingReferences(userIDs, contentIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		interaction := results[i].Interaction
+		if interaction == nil {
+			continue
+		}
+		switch {
+		case missingUsers[interaction.UserID]:
+			results[i].Error = ErrUserNotFound.Error()
+		case missingContents[interaction.ContentID]:
+			results[i].Error = ErrContentNotFound.Error()
+		default:
+			continue
+		}
+		results[i].Status = BatchItemInvalid
+		results[i].Interaction = nil
+	}
+
+	// Descarta eventos já sincronizados em envios anteriores
+	existing := make(map[uint]map[string]bool, len(eventsByUser))
+	for userID, eventIDs := range eventsByUser {
+		ids, err := s.repo.GetExistingClientEventIDs(userID, eventIDs)
+		if err != nil {
+			return nil, err
+		}
+		existing[userID] = make(map[string]bool, len(ids))
+		for _, id := range ids {
+			existing[userID][id] = true
+		}
+	}
+
+	var toInsert []*models.UserInteraction
+	for i := range results {
+		interaction := results[i].Interaction
+		if interaction == nil {
+			continue
+		}
+		if existing[interaction.UserID][*interaction.ClientEventID] {
+			results[i].Status = BatchItemDuplicate
+			results[i].Interaction = nil
+			continue
+		}
+		toInsert = append(toInsert, interaction)
+	}
+
+	if err := s.record(toInsert); err != nil {
+		for i := range results {
+			if results[i].Interaction != nil {
+				results[i].Status = BatchItemFailed
+				results[i].Error = err.Error()
+				results[i].Interaction = nil
+			}
+		}
+		return results, nil
+	}
+
+	for i := range results {
+		if results[i].Interaction != nil {
+			results[i].Status = BatchItemCreated
+		}
+	}
+
+	return results, nil
+}
+
+// batchClientEventID extrai o client_event_id de um item malformado, se houver,
+// para o cliente saber qual evento descartar
+func batchClientEventID(raw json.RawMessage) string {
+	var item struct {
+		ClientEventID string `json:"client_event_id"`
+	}
+	_ = json.Unmarshal(raw, &item)
+	return item.ClientEventID
+}
+
+// GetUserInteractions retorna o histórico de interações de um usuário
+func (s *interactionService) GetUserInteractions(userID uint, query InteractionQuery) ([]models.UserInteraction, int64, error) {
+	filter, err := newInteractionFilter(query)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.repo.ListByUserID(userID, filter)
+}
+
+// GetContentInteractions retorna o histórico de interações de um conteúdo
+func (s *interactionService) GetContentInteractions(contentID uint, query InteractionQuery) ([]models.UserInteraction, int64, error) {
+	filter, err := newInteractionFilter(query)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.repo.ListByContentID(contentID, filter)
+}
+
+// GetFlaggedInteractions retorna as interações sinalizadas como suspeitas
+func (s *interactionService) GetFlaggedInteractions(reason string, query InteractionQuery) ([]models.UserInteraction, int64, error) {
+	if reason != "" && !validFlagReasons[reason] {
+		return nil, 0, errors.New("motivo inválido. Valores válidos: burst, rating_bomb, re_rating")
+	}
+	filter, err := newInteractionFilter(query)
+	if err != nil {
+		return nil, 0, err
+	}
+	return s.repo.ListFlagged(reason, filter)
+}
+
+// Motivos de sinalização válidos
+var validFlagReasons = map[string]bool{
+	models.FlagReasonBurst:      true,
+	models.FlagReasonRatingBomb: true,
+	models.FlagReasonReRating:   true,
+}
+
+// Normalize aplica os valores padrão de paginação
+func (q *InteractionQuery) Normalize() {
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.Limit < 1 {
+		q.Limit = 50
+	}
+	if q.Limit > 200 {
+		q.Limit = 200 // Limite máximo
+	}
+}
+
+// newInteractionFilter valida a consulta e converte para o filtro do repository
+func newInteractionFilter(query InteractionQuery) (repository.InteractionFilter, error) {
+	query.Normalize()
+
+	if query.From != nil && query.To != nil && !query.From.Before(*query.To) {
+		return repository.InteractionFilter{}, errors.New("from deve ser anterior a to")
+	}
+	for _, interactionType := range query.Types {
+		if !validInteractionTypes[interactionType] && !affectsReaction(interactionType) {
+			return repository.InteractionFilter{}, errors.New("tipo de interação inválido: " + interactionType)
+		}
+	}
+	if query.MinRating != nil && (*query.MinRating < 1.0 || *query.MinRating > 5.0) {
+		return repository.InteractionFilter{}, errors.New("min_rating deve estar entre 1.0 e 5.0")
+	}
+
+	return repository.InteractionFilter{
+		From:      query.From,
+		To:        query.To,
+		Types:     query.Types,
+		MinRating: query.MinRating,
+		Limit:     query.Limit,
+		Offset:    (query.Page - 1) * query.Limit,
+		Preload:   !query.SkipPreload,
+	}, nil
+}
+
+// GetReaction retorna o estado atual da reação de um usuário a um conteúdo
+func (s *interactionService) GetReaction(userID, contentID uint) (*models.UserReaction, error) {
+	if userID == 0 || contentID == 0 {
+		return nil, errors.New("ID inválido")
+	}
+
+	reaction, err := s.repo.GetReaction(userID, contentID)
+	if err != nil {
+		return nil, err
+	}
+	if reaction == nil {
+		return &models.UserReaction{UserID: userID, ContentID: contentID}, nil
+	}
+	return reaction, nil
+}
+
+// UpdateReaction troca a reação (like/dislike) e/ou a nota de um usuário.
+// Cada alteração é registrada como um evento no log de interações.
+func (s *interactionService) UpdateReaction(userID, contentID uint, reaction *string, rating *float64) (*models.UserReaction, []models.UserInteraction, error) {
+	if reaction == nil && rating == nil {
+		return nil, nil, errors.New("informe reaction e/ou rating")
+	}
+	if reaction != nil && *reaction != "like" && *reaction != "dislike" {
+		return nil, nil, errors.New("reaction inválida. Valores válidos: like, dislike")
+	}
+
+	var events []*models.UserInteraction
+	if reaction != nil {
+		event, err := newInteraction(userID, contentID, *reaction, nil, InteractionMetadata{})
+		if err != nil {
+			return nil, nil, err
+		}
+		events = append(events, event)
+	}
+	if rating != nil {
+		event, err := newInteraction(userID, contentID, "rating", rating, InteractionMetadata{})
+		if err != nil {
+			return nil, nil, err
+		}
+		events = append(events, event)
+	}
+
+	missingUsers, missingContents, err := s.missingReferences([]uint{userID}, []uint{contentID})
+	if err != nil {
+		return nil, nil, err
+	}
+	if missingUsers[userID] {
+		return nil, nil, ErrUserNotFound
+	}
+	if missingContents[contentID] {
+		return nil, nil, ErrContentNotFound
+	}
+
+	return s.recordReactionEvents(userID, contentID, events)
+}
+
+// RetractReaction desfaz uma reação ativa (like, dislike ou rating).
+// Com kind vazio, desfaz todas as reações ativas do usuário no conteúdo.
+func (s *interactionService) RetractReaction(userID, contentID uint, kind string) (*models.UserReaction, []models.UserInteraction, error) {
+	if userID == 0 || contentID == 0 {
+		return nil, nil, errors.New("ID inválido")
+	}
+	if kind != "" && retractionTypes[kind] == "" {
+		return nil, nil, errors.New("tipo de reação inválido. Valores válidos: like, dislike, rating")
+	}
+
+	current, err := s.repo.GetReaction(userID, contentID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if current == nil {
+		return nil, nil, ErrNoReaction
+	}
+
+	var active []string
+	if current.Reaction != nil && (kind == "" || kind == *current.Reaction) {
+		active = append(active, *current.Reaction)
+	}
+	if current.Rating != nil && (kind == "" || kind == "rating") {
+		active = append(active, "rating")
+	}
+	if len(active) == 0 {
+		return nil, nil, ErrNoReaction
+	}
+
+	now := time.Now()
+	events := make([]*models.UserInteraction, len(active))
+	for i, reaction := range active {
+		events[i] = &models.UserInteraction{
+			UserID:          userID,
+			ContentID:       contentID,
+			InteractionType: retractionTypes[reaction],
+			CreatedAt:       now,
+		}
+	}
+
+	return s.recordReactionEvents(userID, contentID, events)
+}
+
+// recordReactionEvents grava os eventos e retorna o novo estado da reação
+func (s *interactionService) recordReactionEvents(userID, contentID uint, events []*models.UserInteraction) (*models.UserReaction, []models.UserInteraction, error) {
+	if err := s.record(events); err != nil {
+		return nil, nil, err
+	}
+
+	state, err := s.GetReaction(userID, contentID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	recorded := make([]models.UserInteraction, len(events))
+	for i, event := range events {
+		recorded[i] = *event
+	}
+	return state, recorded, nil
+}
+
+// record grava os eventos no log e atualiza, na mesma transação, o estado
+// das reações afetadas por like/dislike/rating e suas retratações e o outbox
+// de notificações ao motor Python, aos webhooks e ao barramento. Os registros em related são gravados na
+// mesma transação.
+// Eventos suspeitos são sinalizados antes de gravados; eles ficam no log,
+// mas não alteram a reação do usuário nem são notificados.
+func (s *interactionService) record(interactions []*models.UserInteraction, related ...interface{}) error {
+	type pair struct{ userID, contentID uint }
+
+	userIDs := make([]uint, len(interactions))
+	now := time.Now()
+	for i, interaction := range interactions {
+		interaction.ReceivedAt = now
+		userIDs[i] = interaction.UserID
+	}
+
+	// As contagens do detector só valem até os eventos serem gravados
+	unlock := s.detector.lock(userIDs)
+	defer unlock()
+
+	if err := s.detector.flag(interactions); err != nil {
+		return err
+	}
+
+	eventsByPair := make(map[pair][]*models.UserInteraction)
+	for _, interaction := range interactions {
+		if !interaction.Flagged && affectsReaction(interaction.InteractionType) {
+			key := pair{interaction.UserID, interaction.ContentID}
+			eventsByPair[key] = append(eventsByPair[key], interaction)
+		}
+	}
+
+	reactions := make([]repository.ReactionUpdate, 0, len(eventsByPair))
+	for key, events := range eventsByPair {
+		// Eventos sincronizados offline podem chegar fora de ordem
+		sort.SliceStable(events, func(i, j int) bool {
+			return events[i].CreatedAt.Before(events[j].CreatedAt)
+		})
+		reactions = append(reactions, repository.ReactionUpdate{
+			UserID:    key.userID,
+			ContentID: key.contentID,
+			Apply:     reactionReducer(events),
+		})
+	}
+
+	// Montado depois da inserção, para os webhooks e o barramento receberem os IDs gerados
+	outbox := func() ([]*models.OutboxEvent, error) {
+		events, err := newRecommenderOutboxEvents(interactions)
+		if err != nil {
+			return nil, err
+		}
+		for _, interaction := range interactions {
+			if interaction.Flagged {
+				continue
+			}
+			data := newInteractionEventData(interaction)
+			webhookEvent, err := newWebhookOutboxEvent(EventInteractionCreated, data)
+			if err != nil {
+				return nil, err
+			}
+			busEvent, err := newBusOutboxEvent(EventInteractionCreated, data,
+				interactionAggregateKey(interaction.UserID, interaction.ContentID))
+			if err != nil {
+				return nil, err
+			}
+			events = append(events, webhookEvent, busEvent)
+		}
+		return events, nil
+	}
+	if err := s.repo.CreateWithReactions(interactions, reactions, outbox, related...); err != nil {
+		return err
+	}
+
+	// Interações sinalizadas como abuso também não são publicadas
+	for _, interaction := range interactions {
+		if !interaction.Flagged {
+			s.events.Emit(EventInteractionCreated, newInteractionEventData(interaction))
+		}
+	}
+	return nil
+}
+
+// affectsReaction indica se o tipo de interação altera o estado da reação
+func affectsReaction(interactionType string) bool {
+	switch interactionType {
+	case "like", "dislike", "rating":
+		return true
+	}
+	return isRetraction(interactionType)
+}
+
+// isRetraction indica se o tipo de interação desfaz uma reação
+func isRetraction(interactionType string) bool {
+	switch interactionType {
+	case InteractionUnlike, InteractionUndislike, InteractionUnrate:
+		return true
+	}
+	return false
+}
+
+// reactionReducer retorna a função que aplica os eventos, já ordenados, ao
+// estado da reação lido pelo repository
+func reactionReducer(events []*models.UserInteraction) func(state *models.UserReaction) bool {
+	return func(state *models.UserReaction) bool {
+		changed := false
+		for _, event := range events {
+			if applyReaction(state, event) {
+				changed = true
+			}
+		}
+		return changed
+	}
+}
+
+// applyReaction aplica um evento ao estado da reação. Like/dislike e nota
+// têm marcas de tempo separadas: um evento mais antigo que o último aplicado
+// ao mesmo campo é ignorado. A marca só avança quando o estado muda, então
+// uma retratação que não corresponde à reação atual não esconde eventos
+// válidos posteriores.
+func applyReaction(state *models.UserReaction, event *models.UserInteraction) bool {
+	switch event.InteractionType {
+	case "like", "dislike", InteractionUnlike, InteractionUndislike:
+		if event.CreatedAt.Before(state.ReactedAt) {
+			return false
+		}
+		var reaction *string
+		switch event.InteractionType {
+		case "like", "dislike":
+			value := event.InteractionType
+			reaction = &value
+		default:
+			if state.Reaction == nil || retractionTypes[*state.Reaction] != event.InteractionType {
+				return false
+			}
+		}
+		if equalStringPtr(reaction, state.Reaction) {
+			return false
+		}
+		state.Reaction = reaction
+		state.ReactedAt = event.CreatedAt
+		return true
+	case "rating", InteractionUnrate:
+		if event.CreatedAt.Before(state.RatedAt) {
+			return false
+		}
+		rating := event.Rating
+		if event.InteractionType == InteractionUnrate {
+			rating = nil
+		}
+		if equalFloatPtr(rating, state.Rating) {
+			return false
+		}
+		state.Rating = rating
+		state.RatedAt = event.CreatedAt
+		return true
+	}
+	return false
+}
+
+func equalStringPtr(a, b *string) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
+
+func equalFloatPtr(a, b *float64) bool {
+	return (a == nil && b == nil) || (a != nil && b != nil && *a == *b)
+}
+
+// missingReferences retorna os IDs de usuários e conteúdos que não existem no banco
+func (s *interactionService) missingReferences(userIDs, contentIDs []uint) (map[uint]bool, map[uint]bool, error) {
+	existingUsers, err := s.userRepo.GetExistingIDs(userIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+	existingContents, err := s.contentRepo.GetExistingIDs(contentIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return missingIDs(userIDs, existingUsers), missingIDs(contentIDs, existingContents), nil
+}
+
+// missingIDs retorna os IDs de requested ausentes em existing
+func missingIDs(requested, existing []uint) map[uint]bool {
+	found := make(map[uint]bool, len(existing))
+	for _, id := range existing {
+		found[id] = true
+	}
+	missing := make(map[uint]bool)
+	for _, id := range requested {
+		if !found[id] {
+			missing[id] = true
+		}
+	}
+	return missing
+}
+
+// newInteraction valida os dados e monta uma nova interação
+func newInteraction(userID, contentID uint, interactionType string, rating *float64, meta InteractionMetadata) (*models.UserInteraction, error) {
+	if userID == 0 || contentID == 0 {
+		return nil, errors.New("ID inválido")
+	}
+
+	// Validar tipo de interação; retratações (unlike, undislike, unrate)
+	// também são aceitas, ex.: em lotes sincronizados offline
+	if !validInteractionTypes[interactionType] && !isRetraction(interactionType) {
 		return nil, errors.New("tipo de interação inválido")
 	}
 
 	// Validar rating se fornecido
 	if rating != nil {
+		if isRetraction(interactionType) {
+			return nil, errors.New("rating não se aplica a retratações")
+		}
 		if *rating < 1.0 || *rating > 5.0 {
 			return nil, errors.New("rating deve estar entre 1.0 e 5.0")
 		}
@@ -54,28 +812,44 @@ func (s *interactionService) CreateInteraction(userID, contentID uint, interacti
 		return nil, errors.New("rating é obrigatório para interação do tipo rating")
 	}
 
-	interaction := &models.UserInteraction{
+	if err := validateMetadata(meta); err != nil {
+		return nil, err
+	}
+
+	return &models.UserInteraction{
 		UserID:          userID,
 		ContentID:       contentID,
 		InteractionType: interactionType,
 		Rating:          rating,
+		SessionID:       meta.SessionID,
+		Device:          meta.Device,
+		AppVersion:      meta.AppVersion,
+		Surface:         meta.Surface,
+		Position:        meta.Position,
+		DwellMs:         meta.DwellMs,
 		CreatedAt:       time.Now(),
-	}
-
-	if err := s.repo.Create(interaction); err != nil {
-		return nil, err
-	}
-
-	return interaction, nil
-}
-
-// GetUserInteractions retorna todas as interações de um usuário
-func (s *interactionService) GetUserInteractions(userID uint) ([]models.UserInteraction, error) {
-	return s.repo.GetByUserID(userID)
+	}, nil
 }
 
-// GetContentInteractions retorna todas as interações de um conteúdo
-func (s *interactionService) GetContentInteractions(contentID uint) ([]models.UserInteraction, error) {
-	return s.repo.GetByContentID(contentID)
+// validateMetadata valida os campos opcionais de contexto da interação
+func validateMetadata(meta InteractionMetadata) error {
+	if meta.SessionID != nil && len(*meta.SessionID) > 64 {
+		return errors.New("session_id deve ter no máximo 64 caracteres")
+	}
+	if meta.Device != nil && len(*meta.Device) > 32 {
+		return errors.New("device deve ter no máximo 32 caracteres")
+	}
+	if meta.AppVersion != nil && len(*meta.AppVersion) > 32 {
+		return errors.New("app_version deve ter no máximo 32 caracteres")
+	}
+	if meta.Surface != nil && !validSurfaces[*meta.Surface] {
+		return errors.New("surface inválida. Valores válidos: home, search, recs, collection")
+	}
+	if meta.Position != nil && (*meta.Position < 0 || *meta.Position > maxInteractionPosition) {
+		return errors.New("position deve estar entre 0 e 1000")
+	}
+	if meta.DwellMs != nil && (*meta.DwellMs < 0 || *meta.DwellMs > maxInteractionDwellMs) {
+		return errors.New("dwell_ms deve estar entre 0 e 86400000")
+	}
+	return nil
 }
-
@@ -0,0 +1,410 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"backend-go/models"
+	"backend-go/repository"
+)
+
+// fakeInteractionRepository guarda o log e as reações em memória, aplicando
+// as atualizações de reação sob um lock como a transação do repository real
+type fakeInteractionRepository struct {
+	repository.InteractionRepository
+	mu           sync.Mutex
+	interactions []*models.UserInteraction
+	reactions    map[userContentKey]models.UserReaction
+	outbox       []*models.OutboxEvent
+	// Atraso das contagens, para expor leituras concorrentes
+	countDelay time.Duration
+}
+
+func newFakeInteractionRepository() *fakeInteractionRepository {
+	return &fakeInteractionRepository{reactions: make(map[userContentKey]models.UserReaction)}
+}
+
+func (r *fakeInteractionRepository) CreateWithReactions(interactions []*models.UserInteraction, reactions []repository.ReactionUpdate, outbox repository.OutboxFunc, related ...interface{}) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, interaction := range interactions {
+		interaction.ID = uint(len(r.interactions) + 1)
+		r.interactions = append(r.interactions, interaction)
+	}
+	// Monta os eventos como a transação real, para validar os envelopes
+	if outbox != nil {
+		events, err := outbox()
+		if err != nil {
+			return err
+		}
+		r.outbox = append(r.outbox, events...)
+	}
+	for _, update := range reactions {
+		key := userContentKey{update.UserID, update.ContentID}
+		state, ok := r.reactions[key]
+		if !ok {
+			state = models.UserReaction{UserID: update.UserID, ContentID: update.ContentID}
+		}
+		if update.Apply(&state) {
+			r.reactions[key] = state
+		}
+	}
+	return nil
+}
+
+func (r *fakeInteractionRepository) GetReaction(userID, contentID uint) (*models.UserReaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state, ok := r.reactions[userContentKey{userID, contentID}]
+	if !ok {
+		return nil, nil
+	}
+	return &state, nil
+}
+
+func (r *fakeInteractionRepository) GetExistingClientEventIDs(userID uint, clientEventIDs []string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var existing []string
+	for _, interaction := range r.interactions {
+		if interaction.UserID != userID || interaction.ClientEventID == nil {
+			continue
+		}
+		for _, id := range clientEventIDs {
+			if *interaction.ClientEventID == id {
+				existing = append(existing, id)
+			}
+		}
+	}
+	return existing, nil
+}
+
+func (r *fakeInteractionRepository) CountByUserSince(userID uint, since time.Time) (int64, error) {
+	r.mu.Lock()
+	var count int64
+	for _, interaction := range r.interactions {
+		if interaction.UserID == userID && !interaction.ReceivedAt.Before(since) {
+			count++
+		}
+	}
+	r.mu.Unlock()
+	time.Sleep(r.countDelay)
+	return count, nil
+}
+
+func (r *fakeInteractionRepository) CountRatingsSince(userID, contentID uint, since time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var count int64
+	for _, interaction := range r.interactions {
+		if interaction.UserID == userID && interaction.ContentID == contentID &&
+			interaction.InteractionType == "rating" && !interaction.ReceivedAt.Before(since) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *fakeInteractionRepository) GetRatingCountsSince(contentID uint, since time.Time, low, high float64) (repository.RatingCounts, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var counts repository.RatingCounts
+	for _, interaction := range r.interactions {
+		if interaction.ContentID != contentID || interaction.InteractionType != "rating" ||
+			interaction.Rating == nil || interaction.ReceivedAt.Before(since) {
+			continue
+		}
+		counts.Total++
+		if *interaction.Rating <= low {
+			counts.Low++
+		}
+		if *interaction.Rating >= high {
+			counts.High++
+		}
+	}
+	return counts, nil
+}
+
+type fakeExistingUserRepository struct {
+	repository.UserRepository
+}
+
+func (fakeExistingUserRepository) GetExistingIDs(ids []uint) ([]uint, error) { return ids, nil }
+
+type fakeExistingContentRepository struct {
+	repository.ContentRepository
+}
+
+func (fakeExistingContentRepository) GetExistingIDs(ids []uint) ([]uint, error) { return ids, nil }
+
+type discardEmitter struct{}
+
+func (discardEmitter) Emit(eventType string, data interface{}) {}
+
+func newTestInteractionService(repo repository.InteractionRepository) InteractionService {
+	return newTestInteractionServiceWithPolicy(repo, DefaultAbusePolicy())
+}
+
+func newTestInteractionServiceWithPolicy(repo repository.InteractionRepository, policy AbusePolicy) InteractionService {
+	return NewInteractionService(repo, fakeExistingUserRepository{}, fakeExistingContentRepository{}, policy, discardEmitter{})
+}
+
+func TestReactionToggling(t *testing.T) {
+	strPtr := func(s string) *string { return &s }
+	floatPtr := func(f float64) *float64 { return &f }
+
+	type step struct {
+		// put: troca reação/nota; delete: retrata kind; batch: sincroniza os itens
+		op       string
+		reaction *string
+		rating   *float64
+		kind     string
+		items    []string
+		wantErr  error
+	}
+
+	ago := func(d time.Duration) string { return time.Now().Add(-d).UTC().Format(time.RFC3339) }
+	item := func(id, interactionType, at string) string {
+		return fmt.Sprintf(`{"client_event_id":%q,"user_id":1,"content_id":2,"interaction_type":%q,"client_timestamp":%q}`, id, interactionType, at)
+	}
+
+	tests := []struct {
+		name         string
+		steps        []step
+		wantReaction *string
+		wantRating   *float64
+	}{
+		{
+			name:         "like seguido de unlike remove a reação",
+			steps:        []step{{op: "put", reaction: strPtr("like")}, {op: "delete", kind: "like"}},
+			wantReaction: nil,
+		},
+		{
+			name:         "trocar like por dislike mantém só o dislike",
+			steps:        []step{{op: "put", reaction: strPtr("like")}, {op: "put", reaction: strPtr("dislike")}},
+			wantReaction: strPtr("dislike"),
+		},
+		{
+			name: "retratar a nota preserva o like",
+			steps: []step{
+				{op: "put", reaction: strPtr("like"), rating: floatPtr(4)},
+				{op: "delete", kind: "rating"},
+			},
+			wantReaction: strPtr("like"),
+		},
+		{
+			name: "retratar sem tipo remove reação e nota",
+			steps: []step{
+				{op: "put", reaction: strPtr("dislike"), rating: floatPtr(2)},
+				{op: "delete"},
+			},
+			wantReaction: nil,
+		},
+		{
+			name: "retratar sem reação ativa falha",
+			steps: []step{
+				{op: "put", reaction: strPtr("like")},
+				{op: "delete", kind: "dislike", wantErr: ErrNoReaction},
+			},
+			wantReaction: strPtr("like"),
+		},
+		{
+			name: "lote offline aplica unlike na ordem do cliente",
+			steps: []step{{op: "batch", items: []string{
+				item("e2", "unlike", ago(time.Minute)),
+				item("e1", "like", ago(time.Hour)),
+			}}},
+			wantReaction: nil,
+		},
+		{
+			name: "lote offline com retratação antiga não desfaz reação mais nova",
+			steps: []step{
+				{op: "put", rating: floatPtr(5)},
+				{op: "batch", items: []string{item("e1", "unrate", ago(time.Hour))}},
+			},
+			wantRating: floatPtr(5),
+		},
+		{
+			name: "like offline anterior a uma nota mais nova é aplicado",
+			steps: []step{
+				{op: "put", rating: floatPtr(4)},
+				{op: "batch", items: []string{item("e1", "like", ago(time.Hour))}},
+			},
+			wantReaction: strPtr("like"),
+			wantRating:   floatPtr(4),
+		},
+		{
+			name: "unrate offline anterior a um like mais novo remove a nota",
+			steps: []step{
+				{op: "batch", items: []string{fmt.Sprintf(
+					`{"client_event_id":"e1","user_id":1,"content_id":2,"interaction_type":"rating","rating":4,"client_timestamp":%q}`,
+					ago(3*time.Hour))}},
+				{op: "put", reaction: strPtr("like")},
+				{op: "batch", items: []string{item("e2", "unrate", ago(time.Hour))}},
+			},
+			wantReaction: strPtr("like"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeInteractionRepository()
+			svc := newTestInteractionService(repo)
+
+			for i, st := range tt.steps {
+				var err error
+				switch st.op {
+				case "put":
+					_, _, err = svc.UpdateReaction(1, 2, st.reaction, st.rating)
+				case "delete":
+					_, _, err = svc.RetractReaction(1, 2, st.kind)
+				case "batch":
+					raw := make([]json.RawMessage, len(st.items))
+					for j, it := range st.items {
+						raw[j] = json.RawMessage(it)
+					}
+					var results []BatchInteractionResult
+					results, err = svc.CreateInteractionsBatch(raw)
+					for _, result := range results {
+						if result.Status != BatchItemCreated {
+							t.Fatalf("passo %d: item %d com status %s (%s)", i, result.Index, result.Status, result.Error)
+						}
+					}
+				}
+				if !errors.Is(err, st.wantErr) {
+					t.Fatalf("passo %d (%s): erro %v, esperado %v", i, st.op, err, st.wantErr)
+				}
+			}
+
+			state, err := svc.GetReaction(1, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !equalStringPtr(state.Reaction, tt.wantReaction) {
+				t.Errorf("reaction = %v, esperado %v", derefString(state.Reaction), derefString(tt.wantReaction))
+			}
+			if !equalFloatPtr(state.Rating, tt.wantRating) {
+				t.Errorf("rating = %v, esperado %v", state.Rating, tt.wantRating)
+			}
+		})
+	}
+}
+
+func TestNewInteractionRetractions(t *testing.T) {
+	rating := 3.0
+	tests := []struct {
+		name            string
+		interactionType string
+		rating          *float64
+		wantErr         bool
+	}{
+		{name: "unlike é aceito", interactionType: InteractionUnlike},
+		{name: "undislike é aceito", interactionType: InteractionUndislike},
+		{name: "unrate é aceito", interactionType: InteractionUnrate},
+		{name: "retratação com nota é rejeitada", interactionType: InteractionUnrate, rating: &rating, wantErr: true},
+		{name: "tipo desconhecido é rejeitado", interactionType: "unview", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interaction, err := newInteraction(1, 2, tt.interactionType, tt.rating, InteractionMetadata{})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("erro = %v, esperado erro: %v", err, tt.wantErr)
+			}
+			if err == nil && interaction.InteractionType != tt.interactionType {
+				t.Errorf("tipo = %s, esperado %s", interaction.InteractionType, tt.interactionType)
+			}
+		})
+	}
+}
+
+func TestApplyReaction(t *testing.T) {
+	base := time.Now().Add(-time.Hour)
+	like := "like"
+	rating := 4.0
+	event := func(interactionType string, minutes int) *models.UserInteraction {
+		interaction := &models.UserInteraction{InteractionType: interactionType, CreatedAt: base.Add(time.Duration(minutes) * time.Minute)}
+		if interactionType == "rating" {
+			interaction.Rating = &rating
+		}
+		return interaction
+	}
+
+	tests := []struct {
+		name        string
+		state       models.UserReaction
+		event       *models.UserInteraction
+		wantChanged bool
+		// Marcas esperadas, em minutos a partir de base
+		wantReactedAt int
+		wantRatedAt   int
+	}{
+		{
+			name:          "retratação que não corresponde não avança a marca",
+			state:         models.UserReaction{Reaction: &like, ReactedAt: base},
+			event:         event(InteractionUndislike, 10),
+			wantReactedAt: 0,
+		},
+		{
+			name:          "like repetido não muda o estado",
+			state:         models.UserReaction{Reaction: &like, ReactedAt: base},
+			event:         event("like", 10),
+			wantReactedAt: 0,
+		},
+		{
+			name:          "nota mais nova não bloqueia like anterior",
+			state:         models.UserReaction{Rating: &rating, RatedAt: base.Add(20 * time.Minute)},
+			event:         event("like", 10),
+			wantChanged:   true,
+			wantReactedAt: 10,
+			wantRatedAt:   20,
+		},
+		{
+			name:          "unlike mais antigo que o like é ignorado",
+			state:         models.UserReaction{Reaction: &like, ReactedAt: base.Add(20 * time.Minute)},
+			event:         event(InteractionUnlike, 10),
+			wantReactedAt: 20,
+		},
+		{
+			name:          "nota aplicada avança só a marca da nota",
+			state:         models.UserReaction{Reaction: &like, ReactedAt: base.Add(20 * time.Minute)},
+			event:         event("rating", 10),
+			wantChanged:   true,
+			wantReactedAt: 20,
+			wantRatedAt:   10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := tt.state
+			if state.ReactedAt.IsZero() {
+				state.ReactedAt = base
+			}
+			if state.RatedAt.IsZero() {
+				state.RatedAt = base
+			}
+
+			if changed := applyReaction(&state, tt.event); changed != tt.wantChanged {
+				t.Errorf("changed = %v, esperado %v", changed, tt.wantChanged)
+			}
+			if want := base.Add(time.Duration(tt.wantReactedAt) * time.Minute); !state.ReactedAt.Equal(want) {
+				t.Errorf("reacted_at = %v, esperado %v", state.ReactedAt, want)
+			}
+			if want := base.Add(time.Duration(tt.wantRatedAt) * time.Minute); !state.RatedAt.Equal(want) {
+				t.Errorf("rated_at = %v, esperado %v", state.RatedAt, want)
+			}
+		})
+	}
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return "<nil>"
+	}
+	return *s
+}
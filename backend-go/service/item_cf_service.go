@@ -0,0 +1,441 @@
+package service
+
+import (
+	"backend-go/models"
+	"backend-go/repository"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MethodItemCF é o método de recomendação resolvido pela filtragem
+// colaborativa item-item em processo, sem chamar o motor Python
+const MethodItemCF = "item_cf"
+
+// ItemCFStats resume o modelo item-item em memória
+type ItemCFStats struct {
+	Users             int        `json:"users"`
+	Items             int        `json:"items"`
+	ItemPairs         int        `json:"item_pairs"`
+	LastInteractionID uint       `json:"last_interaction_id"`
+	OpenGaps          int        `json:"open_gaps"`
+	BuiltAt           *time.Time `json:"built_at,omitempty"`
+	UpdatedAt         *time.Time `json:"updated_at,omitempty"`
+}
+
+// ItemCFService é a implementação em processo do RecommendationService por
+// filtragem colaborativa item-item (similaridade de cosseno)
+type ItemCFService interface {
+	RecommendationService
+	CatchUp() (int, error)
+	Rebuild() (int, error)
+	Stats() ItemCFStats
+}
+
+// cfPairState guarda o estado de um par usuário-conteúdo, como em
+// computeAffinity: a reação e a nota vigentes mais os eventos acumulados.
+// reactionAt e ratingAt guardam o horário do evento que definiu cada uma.
+type cfPairState struct {
+	accumulated float64
+	reaction    string
+	reactionAt  time.Time
+	rating      *float64
+	ratingAt    time.Time
+}
+
+// apply incorpora uma interação ao estado do par. Uma interação que chega
+// depois de outra mais recente do mesmo par (ex.: lida num buraco de ID) não
+// sobrescreve a reação nem a nota definidas pela mais recente.
+func (p *cfPairState) apply(interaction *models.UserInteraction, weights map[string]float64) {
+	switch interaction.InteractionType {
+	case "like", "dislike":
+		if !interaction.CreatedAt.Before(p.reactionAt) {
+			p.reaction = interaction.InteractionType
+			p.reactionAt = interaction.CreatedAt
+		}
+	case InteractionUnlike, InteractionUndislike:
+		if !interaction.CreatedAt.Before(p.reactionAt) &&
+			p.reaction != "" && retractionTypes[p.reaction] == interaction.InteractionType {
+			p.reaction = ""
+			p.reactionAt = interaction.CreatedAt
+		}
+	case "rating":
+		if interaction.Rating != nil && !interaction.CreatedAt.Before(p.ratingAt) {
+			p.rating = interaction.Rating
+			p.ratingAt = interaction.CreatedAt
+		}
+	case InteractionUnrate:
+		if !interaction.CreatedAt.Before(p.ratingAt) {
+			p.rating = nil
+			p.ratingAt = interaction.CreatedAt
+		}
+	default:
+		p.accumulated += weights[interaction.InteractionType]
+	}
+}
+
+// preference é a preferência do usuário pelo conteúdo. Só preferências
+// positivas entram nos vetores dos itens.
+func (p *cfPairState) preference(weights map[string]float64) float64 {
+	score := p.accumulated + weights[p.reaction]
+	if p.rating != nil {
+		score += (*p.rating - NeutralRating) * weights["rating"]
+	}
+	if score < 0 {
+		return 0
+	}
+	return score
+}
+
+// itemCFModel é a matriz usuário-item e os produtos escalares entre itens.
+// A similaridade de cosseno é dot[i][j] / (|i| |j|).
+type itemCFModel struct {
+	pairs  map[userContentKey]*cfPairState
+	prefs  map[uint]map[uint]float64 // usuário -> conteúdo -> preferência > 0
+	normSq map[uint]float64
+	dot    map[uint]map[uint]float64
+	lastID uint
+	// IDs abaixo de lastID ainda não vistos (transações confirmadas fora de
+	// ordem), com o instante em que o buraco foi visto. Como o modelo, vivem
+	// só em memória: um restart recalcula tudo.
+	gaps map[uint]time.Time
+}
+
+func newItemCFModel() *itemCFModel {
+	return &itemCFModel{
+		pairs:  make(map[userContentKey]*cfPairState),
+		prefs:  make(map[uint]map[uint]float64),
+		normSq: make(map[uint]float64),
+		dot:    make(map[uint]map[uint]float64),
+		gaps:   make(map[uint]time.Time),
+	}
+}
+
+// trackGaps registra os buracos de ID entre lastID e as interações lidas,
+// antes de aplicá-las
+func (m *itemCFModel) trackGaps(interactions []models.UserInteraction, seenAt time.Time) {
+	for _, id := range idGaps(m.lastID, interactions) {
+		m.gaps[id] = seenAt
+	}
+}
+
+// apply incorpora a interação e atualiza incrementalmente os produtos
+// escalares do item com os demais itens do mesmo usuário
+func (m *itemCFModel) apply(interaction *models.UserInteraction, weights map[string]float64) {
+	if interaction.ID > m.lastID {
+		m.lastID = interaction.ID
+	}
+	if interaction.Flagged {
+		return
+	}
+
+	key := userContentKey{interaction.UserID, interaction.ContentID}
+	state, ok := m.pairs[key]
+	if !ok {
+		state = &cfPairState{}
+		m.pairs[key] = state
+	}
+	userPrefs := m.prefs[key.userID]
+	old := userPrefs[key.contentID]
+	state.apply(interaction, weights)
+	pref := state.preference(weights)
+	if pref == old {
+		return
+	}
+
+	delta := pref - old
+	for other, otherPref := range userPrefs {
+		if other == key.contentID {
+			continue
+		}
+		m.addDot(key.contentID, other, delta*otherPref)
+		m.addDot(other, key.contentID, delta*otherPref)
+	}
+	m.normSq[key.contentID] += pref*pref - old*old
+	if m.normSq[key.contentID] <= 1e-9 {
+		delete(m.normSq, key.contentID)
+	}
+
+	if pref == 0 {
+		delete(userPrefs, key.contentID)
+		if len(userPrefs) == 0 {
+			delete(m.prefs, key.userID)
+		}
+		return
+	}
+	if userPrefs == nil {
+		userPrefs = make(map[uint]float64)
+		m.prefs[key.userID] = userPrefs
+	}
+	userPrefs[key.contentID] = pref
+}
+
+func (m *itemCFModel) addDot(i, j uint, value float64) {
+	row, ok := m.dot[i]
+	if !ok {
+		row = make(map[uint]float64)
+		m.dot[i] = row
+	}
+	row[j] += value
+	if math.Abs(row[j]) <= 1e-9 {
+		delete(row, j)
+		if len(row) == 0 {
+			delete(m.dot, i)
+		}
+	}
+}
+
+func (m *itemCFModel) similarity(i, j uint) float64 {
+	normI, normJ := m.normSq[i], m.normSq[j]
+	if normI <= 0 || normJ <= 0 {
+		return 0
+	}
+	return m.dot[i][j] / math.Sqrt(normI*normJ)
+}
+
+// recommend pontua cada conteúdo ainda não consumido pelo usuário pela soma
+// das similaridades com os conteúdos de que ele gostou, ponderadas pela
+// preferência por cada um
+func (m *itemCFModel) recommend(userID uint, topN int) ([]uint, map[uint]float64) {
+	scores := make(map[uint]float64)
+	for item, pref := range m.prefs[userID] {
+		for neighbor := range m.dot[item] {
+			if _, consumed := m.pairs[userContentKey{userID, neighbor}]; consumed {
+				continue
+			}
+			scores[neighbor] += m.similarity(item, neighbor) * pref
+		}
+	}
+
+	candidates := make([]uint, 0, len(scores))
+	for contentID, score := range scores {
+		if score > 0 {
+			candidates = append(candidates, contentID)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if scores[candidates[i]] != scores[candidates[j]] {
+			return scores[candidates[i]] > scores[candidates[j]]
+		}
+		return candidates[i] < candidates[j]
+	})
+	if len(candidates) > topN {
+		candidates = candidates[:topN]
+	}
+
+	selected := make(map[uint]float64, len(candidates))
+	for _, contentID := range candidates {
+		selected[contentID] = scores[contentID]
+	}
+	return candidates, selected
+}
+
+func (m *itemCFModel) itemPairs() int {
+	pairs := 0
+	for _, row := range m.dot {
+		pairs += len(row)
+	}
+	return pairs / 2
+}
+
+type itemCFService struct {
+	rollupRepo repository.RollupRepository
+	weights    map[string]float64
+	// mu serializa CatchUp e Rebuild; modelMu protege o modelo para leitura
+	mu        sync.Mutex
+	modelMu   sync.RWMutex
+	model     *itemCFModel
+	builtAt   *time.Time
+	updatedAt *time.Time
+}
+
+// NewItemCFService cria o recomendador item-item em processo. As
+// preferências usam os pesos das afinidades, sem decaimento no tempo: a
+// similaridade entre itens reflete preferências de longo prazo. O modelo
+// começa vazio; chame Rebuild para carregá-lo.
+func NewItemCFService(rollupRepo repository.RollupRepository, policy AffinityPolicy) ItemCFService {
+	return &itemCFService{
+		rollupRepo: rollupRepo,
+		weights:    policy.Weights,
+		model:      newItemCFModel(),
+	}
+}
+
+// GetRecommendations retorna os conteúdos mais similares aos que o usuário
+// preferiu. Usuários sem preferências positivas recebem uma lista vazia. Os
+// filtros de negócio são aplicados pelo RecommendationService.
+func (s *itemCFService) GetRecommendations(userID uint, topN int, method string, filter RecommendationFilter, reqCtx RecommendationContext) (*RecommendationResult, error) {
+	if topN <= 0 || topN > 50 {
+		topN = 10
+	}
+	s.modelMu.RLock()
+	defer s.modelMu.RUnlock()
+
+	contentIDs, scores := s.model.recommend(userID, topN)
+	result := &RecommendationResult{ContentIDs: contentIDs, Scores: scores, Method: MethodItemCF}
+	if s.builtAt != nil {
+		result.ModelVersion = modelVersion(MethodItemCF, *s.builtAt)
+	}
+	return result, nil
+}
+
+// DeliverInteractions apenas antecipa a atualização incremental: o modelo
+// lê as interações diretamente do log
+func (s *itemCFService) DeliverInteractions(interactions []InteractionRequest) error {
+	_, err := s.CatchUp()
+	return err
+}
+
+// CatchUp incorpora ao modelo as interações gravadas desde a última
+// atualização, inclusive as que apareceram em buracos de ID já vistos.
+// Retorna o número de interações lidas.
+func (s *itemCFService) CatchUp() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	upTo, err := s.rollupRepo.MaxInteractionID(time.Now().Add(-rollupSafetyLag))
+	if err != nil {
+		return 0, err
+	}
+
+	read, err := s.fillGaps()
+	if err != nil {
+		return 0, err
+	}
+
+	s.modelMu.RLock()
+	lastID := s.model.lastID
+	s.modelMu.RUnlock()
+
+	for lastID < upTo {
+		interactions, err := s.rollupRepo.ListInteractions(lastID, upTo, nil, nil, rollupChunkSize)
+		if err != nil {
+			return read, err
+		}
+		if len(interactions) == 0 {
+			break
+		}
+
+		s.modelMu.Lock()
+		s.model.trackGaps(interactions, time.Now())
+		for i := range interactions {
+			s.model.apply(&interactions[i], s.weights)
+		}
+		s.model.lastID = interactions[len(interactions)-1].ID
+		s.modelMu.Unlock()
+
+		read += len(interactions)
+		lastID = interactions[len(interactions)-1].ID
+	}
+
+	if read > 0 {
+		now := time.Now()
+		s.modelMu.Lock()
+		s.updatedAt = &now
+		s.modelMu.Unlock()
+	}
+	return read, nil
+}
+
+// fillGaps aplica as interações que apareceram nos buracos de ID abertos e
+// descarta os buracos preenchidos ou abertos há mais de rollupGapTimeout
+// (interações apagadas ou transações desfeitas). Retorna o número de
+// interações aplicadas.
+func (s *itemCFService) fillGaps() (int, error) {
+	s.modelMu.RLock()
+	ids := make([]uint, 0, len(s.model.gaps))
+	for id := range s.model.gaps {
+		ids = append(ids, id)
+	}
+	s.modelMu.RUnlock()
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	interactions, err := s.rollupRepo.ListInteractionsByID(ids)
+	if err != nil {
+		return 0, err
+	}
+
+	expired := time.Now().Add(-rollupGapTimeout)
+	s.modelMu.Lock()
+	defer s.modelMu.Unlock()
+	for i := range interactions {
+		s.model.apply(&interactions[i], s.weights)
+		delete(s.model.gaps, interactions[i].ID)
+	}
+	for id, seenAt := range s.model.gaps {
+		if seenAt.Before(expired) {
+			delete(s.model.gaps, id)
+		}
+	}
+	if len(interactions) > 0 {
+		now := time.Now()
+		s.updatedAt = &now
+	}
+	return len(interactions), nil
+}
+
+// Rebuild recalcula o modelo a partir de todo o log de interações e o troca
+// pelo atual, que continua atendendo durante o recálculo. Retorna o número
+// de interações lidas.
+func (s *itemCFService) Rebuild() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	upTo, err := s.rollupRepo.MaxInteractionID(time.Now().Add(-rollupSafetyLag))
+	if err != nil {
+		return 0, err
+	}
+
+	model := newItemCFModel()
+	read := 0
+	for model.lastID < upTo {
+		interactions, err := s.rollupRepo.ListInteractions(model.lastID, upTo, nil, nil, rollupChunkSize)
+		if err != nil {
+			return read, err
+		}
+		if len(interactions) == 0 {
+			break
+		}
+		model.trackGaps(interactions, time.Now())
+		for i := range interactions {
+			model.apply(&interactions[i], s.weights)
+		}
+		read += len(interactions)
+	}
+	// IDs sem interação no fim do intervalo não são relidos em sequência;
+	// ficam como buracos, para o caso de serem transações ainda abertas
+	if model.lastID < upTo {
+		if upTo-model.lastID <= rollupMaxGapSpan {
+			for id := model.lastID + 1; id <= upTo; id++ {
+				model.gaps[id] = time.Now()
+			}
+		}
+		model.lastID = upTo
+	}
+
+	now := time.Now()
+	s.modelMu.Lock()
+	s.model = model
+	s.builtAt = &now
+	s.updatedAt = &now
+	s.modelMu.Unlock()
+	return read, nil
+}
+
+// Stats retorna o tamanho do modelo e quando foi atualizado
+func (s *itemCFService) Stats() ItemCFStats {
+	s.modelMu.RLock()
+	defer s.modelMu.RUnlock()
+	return ItemCFStats{
+		Users:             len(s.model.prefs),
+		Items:             len(s.model.normSq),
+		ItemPairs:         s.model.itemPairs(),
+		LastInteractionID: s.model.lastID,
+		OpenGaps:          len(s.model.gaps),
+		BuiltAt:           s.builtAt,
+		UpdatedAt:         s.updatedAt,
+	}
+}
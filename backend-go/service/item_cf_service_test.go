@@ -0,0 +1,130 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"backend-go/models"
+)
+
+func TestItemCFGaps(t *testing.T) {
+	base := time.Now().Add(-time.Hour)
+	// Interação do usuário 1 no conteúdo, registrada minutes minutos depois de base
+	event := func(id, contentID uint, interactionType string, minutes int) models.UserInteraction {
+		return models.UserInteraction{
+			ID:              id,
+			UserID:          1,
+			ContentID:       contentID,
+			InteractionType: interactionType,
+			CreatedAt:       base.Add(time.Duration(minutes) * time.Minute),
+		}
+	}
+
+	type step struct {
+		// commit: confirma as interações; catchup/rebuild: atualiza o modelo;
+		// expire: envelhece os buracos abertos além do prazo
+		op     string
+		events []models.UserInteraction
+	}
+	commit := func(events ...models.UserInteraction) step { return step{op: "commit", events: events} }
+	catchUp, rebuild, expire := step{op: "catchup"}, step{op: "rebuild"}, step{op: "expire"}
+
+	tests := []struct {
+		name     string
+		steps    []step
+		wantGaps int
+		// Conteúdos com preferência positiva do usuário 1 ao final
+		wantPreferred []uint
+		wantLastID    uint
+	}{
+		{
+			name: "ID menor confirmado depois é incorporado",
+			steps: []step{
+				commit(event(1, 10, "like", 0), event(3, 30, "like", 2)), catchUp,
+				commit(event(2, 20, "like", 1)), catchUp,
+			},
+			wantPreferred: []uint{10, 20, 30},
+			wantLastID:    3,
+		},
+		{
+			name: "buraco continua aberto até aparecer",
+			steps: []step{
+				commit(event(1, 10, "like", 0), event(4, 40, "like", 3)), catchUp, catchUp,
+			},
+			wantGaps:      2,
+			wantPreferred: []uint{10, 40},
+			wantLastID:    4,
+		},
+		{
+			name: "buraco que nunca aparece expira",
+			steps: []step{
+				commit(event(1, 10, "like", 0), event(3, 30, "like", 2)), catchUp,
+				expire, catchUp,
+			},
+			wantPreferred: []uint{10, 30},
+			wantLastID:    3,
+		},
+		{
+			name: "like atrasado não desfaz o unlike mais recente",
+			steps: []step{
+				commit(event(1, 10, "like", 0), event(3, 10, "unlike", 2)), catchUp,
+				commit(event(2, 10, "like", 1)), catchUp,
+			},
+			wantLastID: 3,
+		},
+		{
+			name: "rebuild mantém os buracos abertos",
+			steps: []step{
+				commit(event(1, 10, "like", 0), event(3, 30, "like", 2)), rebuild,
+				commit(event(2, 20, "like", 1)), catchUp,
+			},
+			wantPreferred: []uint{10, 20, 30},
+			wantLastID:    3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRollupRepository()
+			svc := NewItemCFService(repo, AffinityPolicy{Weights: map[string]float64{"view": 1, "like": 3, "dislike": -4, "rating": 1}}).(*itemCFService)
+
+			for i, st := range tt.steps {
+				var err error
+				switch st.op {
+				case "commit":
+					for _, interaction := range st.events {
+						repo.committed[interaction.ID] = interaction
+					}
+				case "catchup":
+					_, err = svc.CatchUp()
+				case "rebuild":
+					_, err = svc.Rebuild()
+				case "expire":
+					for id := range svc.model.gaps {
+						svc.model.gaps[id] = time.Now().Add(-2 * rollupGapTimeout)
+					}
+				}
+				if err != nil {
+					t.Fatalf("passo %d (%s): %v", i, st.op, err)
+				}
+			}
+
+			stats := svc.Stats()
+			if stats.OpenGaps != tt.wantGaps {
+				t.Errorf("buracos abertos = %d, esperado %d", stats.OpenGaps, tt.wantGaps)
+			}
+			if stats.LastInteractionID != tt.wantLastID {
+				t.Errorf("última interação = %d, esperado %d", stats.LastInteractionID, tt.wantLastID)
+			}
+			prefs := svc.model.prefs[1]
+			if len(prefs) != len(tt.wantPreferred) {
+				t.Fatalf("preferências = %v, esperado %v", prefs, tt.wantPreferred)
+			}
+			for _, contentID := range tt.wantPreferred {
+				if prefs[contentID] <= 0 {
+					t.Errorf("conteúdo %d sem preferência: %v", contentID, prefs)
+				}
+			}
+		})
+	}
+}
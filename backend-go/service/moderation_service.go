@@ -0,0 +1,175 @@
+package service
+
+import (
+	"backend-go/models"
+	"backend-go/repository"
+	"errors"
+)
+
+// ModerationService define a interface para denúncias e fila de moderação
+type ModerationService interface {
+	ReportContent(contentID, userID uint, reason, details string) (*models.ContentReport, error)
+	ListReports(status *string, page, limit int) ([]models.ContentReport, int64, error)
+	DismissReport(reportID, moderatorID uint, note string) (*models.ContentReport, error)
+	ActionReport(reportID, moderatorID uint, note string) (*models.ContentReport, error)
+	GetContentDecisions(contentID uint) ([]models.ModerationDecision, error)
+}
+
+type moderationService struct {
+	repo          repository.ReportRepository
+	contentRepo   repository.ContentRepository
+	userRepo      repository.UserRepository
+	hideThreshold int
+}
+
+// NewModerationService cria uma nova instância do ModerationService.
+// Conteúdos denunciados por hideThreshold usuários distintos, com denúncias
+// em aberto, são ocultados automaticamente das recomendações até serem revisados.
+func NewModerationService(
+	repo repository.ReportRepository,
+	contentRepo repository.ContentRepository,
+	userRepo repository.UserRepository,
+	hideThreshold int,
+) ModerationService {
+	if hideThreshold < 1 {
+		hideThreshold = 3
+	}
+	return &moderationService{
+		repo:          repo,
+		contentRepo:   contentRepo,
+		userRepo:      userRepo,
+		hideThreshold: hideThreshold,
+	}
+}
+
+// Motivos de denúncia válidos
+var validReportReasons = map[string]bool{
+	"outdated":      true,
+	"wrong":         true,
+	"inappropriate": true,
+}
+
+// ReportContent registra uma denúncia e oculta o conteúdo se o limite for
+// atingido. Cada usuário denuncia um conteúdo uma única vez.
+func (s *moderationService) ReportContent(contentID, userID uint, reason, details string) (*models.ContentReport, error) {
+	if contentID == 0 || userID == 0 {
+		return nil, errors.New("ID inválido")
+	}
+	if !validReportReasons[reason] {
+		return nil, errors.New("motivo de denúncia inválido. Motivos válidos: outdated, wrong, inappropriate")
+	}
+	if len(details) > 1000 {
+		return nil, errors.New("detalhes devem ter no máximo 1000 caracteres")
+	}
+
+	content, err := s.contentRepo.GetByID(contentID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ContentReport{
+		ContentID: contentID,
+		UserID:    userID,
+		Reason:    reason,
+		Details:   details,
+		Status:    models.ReportStatusOpen,
+	}
+	if err := s.repo.Create(report); err != nil {
+		return nil, err
+	}
+
+	if content.Hidden {
+		return report, nil
+	}
+
+	reporters, err := s.repo.CountOpenReportersByContentID(contentID)
+	if err != nil {
+		return nil, err
+	}
+	if reporters >= int64(s.hideThreshold) {
+		// Só registra a decisão se esta denúncia de fato ocultou o conteúdo
+		hidden, err := s.contentRepo.HideIfVisible(contentID, models.HiddenReasonReports)
+		if err != nil {
+			return nil, err
+		}
+		if !hidden {
+			return report, nil
+		}
+		if err := s.repo.CreateDecision(&models.ModerationDecision{
+			ContentID: contentID,
+			ReportID:  &report.ID,
+			Action:    models.ModerationActionAutoHide,
+			Note:      "limite de denúncias atingido",
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+// ListReports lista denúncias com paginação e filtro por status
+func (s *moderationService) ListReports(status *string, page, limit int) ([]models.ContentReport, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	return s.repo.List(status, limit, (page-1)*limit)
+}
+
+// DismissReport descarta uma denúncia. Se o conteúdo foi ocultado
+// automaticamente pelas denúncias e não restam denunciantes suficientes, ele
+// volta a ser recomendado; conteúdos ocultados por um moderador continuam ocultos.
+func (s *moderationService) DismissReport(reportID, moderatorID uint, note string) (*models.ContentReport, error) {
+	return s.resolve(reportID, moderatorID, note, repository.ReportResolution{
+		Status:      models.ReportStatusDismissed,
+		Decision:    &models.ModerationDecision{Action: models.ModerationActionDismiss},
+		UnhideBelow: s.hideThreshold,
+	})
+}
+
+// ActionReport acata uma denúncia e mantém o conteúdo oculto das recomendações
+func (s *moderationService) ActionReport(reportID, moderatorID uint, note string) (*models.ContentReport, error) {
+	return s.resolve(reportID, moderatorID, note, repository.ReportResolution{
+		Status:   models.ReportStatusActioned,
+		Decision: &models.ModerationDecision{Action: models.ModerationActionAction},
+		Hide:     true,
+	})
+}
+
+// GetContentDecisions retorna o histórico de decisões de moderação de um conteúdo
+func (s *moderationService) GetContentDecisions(contentID uint) ([]models.ModerationDecision, error) {
+	if contentID == 0 {
+		return nil, errors.New("ID inválido")
+	}
+	return s.repo.ListDecisionsByContentID(contentID)
+}
+
+// resolve valida o moderador e fecha a denúncia, registrando a decisão e a
+// mudança de ocultação na mesma transação
+func (s *moderationService) resolve(reportID, moderatorID uint, note string, resolution repository.ReportResolution) (*models.ContentReport, error) {
+	if reportID == 0 {
+		return nil, errors.New("ID inválido")
+	}
+	if len(note) > 1000 {
+		return nil, errors.New("nota deve ter no máximo 1000 caracteres")
+	}
+
+	moderator, err := s.userRepo.GetByID(moderatorID)
+	if err != nil {
+		return nil, err
+	}
+	if moderator.Role != models.RoleEditor && moderator.Role != models.RoleAdmin {
+		return nil, errors.New("usuário sem permissão de moderação")
+	}
+
+	resolution.Decision.ModeratorID = &moderator.ID
+	resolution.Decision.Note = note
+	return s.repo.Resolve(reportID, resolution)
+}
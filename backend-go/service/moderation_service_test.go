@@ -0,0 +1,245 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"backend-go/models"
+	"backend-go/repository"
+)
+
+// fakeReportRepository guarda as denúncias em memória; Resolve aplica a
+// ocultação no conteúdo do fake de conteúdos, como a transação real
+type fakeReportRepository struct {
+	repository.ReportRepository
+	reports   []*models.ContentReport
+	decisions []*models.ModerationDecision
+	contents  *fakeModerationContentRepository
+}
+
+func (r *fakeReportRepository) Create(report *models.ContentReport) error {
+	for _, existing := range r.reports {
+		if existing.ContentID == report.ContentID && existing.UserID == report.UserID {
+			return repository.ErrDuplicateReport
+		}
+	}
+	report.ID = uint(len(r.reports) + 1)
+	r.reports = append(r.reports, report)
+	return nil
+}
+
+func (r *fakeReportRepository) GetByID(id uint) (*models.ContentReport, error) {
+	for _, report := range r.reports {
+		if report.ID == id {
+			copied := *report
+			return &copied, nil
+		}
+	}
+	return nil, repository.ErrReportNotFound
+}
+
+func (r *fakeReportRepository) Resolve(reportID uint, resolution repository.ReportResolution) (*models.ContentReport, error) {
+	var report *models.ContentReport
+	for _, existing := range r.reports {
+		if existing.ID == reportID {
+			report = existing
+		}
+	}
+	if report == nil {
+		return nil, repository.ErrReportNotFound
+	}
+	if report.Status != models.ReportStatusOpen {
+		return nil, repository.ErrReportResolved
+	}
+
+	report.Status = resolution.Status
+	resolution.Decision.ContentID = report.ContentID
+	resolution.Decision.ReportID = &report.ID
+	r.decisions = append(r.decisions, resolution.Decision)
+
+	if resolution.Hide {
+		_ = r.contents.SetHidden(report.ContentID, true, models.HiddenReasonModerator)
+	} else if resolution.UnhideBelow > 0 {
+		reporters, _ := r.CountOpenReportersByContentID(report.ContentID)
+		if reporters < int64(resolution.UnhideBelow) {
+			_, _ = r.contents.UnhideIfReason(report.ContentID, models.HiddenReasonReports)
+		}
+	}
+	copied := *report
+	return &copied, nil
+}
+
+func (r *fakeReportRepository) CountOpenReportersByContentID(contentID uint) (int64, error) {
+	reporters := make(map[uint]bool)
+	for _, report := range r.reports {
+		if report.ContentID == contentID && report.Status == models.ReportStatusOpen {
+			reporters[report.UserID] = true
+		}
+	}
+	return int64(len(reporters)), nil
+}
+
+func (r *fakeReportRepository) CreateDecision(decision *models.ModerationDecision) error {
+	r.decisions = append(r.decisions, decision)
+	return nil
+}
+
+type fakeModerationContentRepository struct {
+	repository.ContentRepository
+	content *models.Content
+}
+
+func (r *fakeModerationContentRepository) GetByID(id uint) (*models.Content, error) {
+	copied := *r.content
+	return &copied, nil
+}
+
+func (r *fakeModerationContentRepository) SetHidden(id uint, hidden bool, reason string) error {
+	r.content.Hidden = hidden
+	r.content.HiddenReason = nil
+	if hidden {
+		r.content.HiddenReason = &reason
+	}
+	return nil
+}
+
+func (r *fakeModerationContentRepository) HideIfVisible(id uint, reason string) (bool, error) {
+	if r.content.Hidden {
+		return false, nil
+	}
+	return true, r.SetHidden(id, true, reason)
+}
+
+func (r *fakeModerationContentRepository) UnhideIfReason(id uint, reason string) (bool, error) {
+	if !r.content.Hidden || r.content.HiddenReason == nil || *r.content.HiddenReason != reason {
+		return false, nil
+	}
+	return true, r.SetHidden(id, false, "")
+}
+
+type fakeModeratorRepository struct {
+	repository.UserRepository
+}
+
+func (fakeModeratorRepository) GetByID(id uint) (*models.User, error) {
+	return &models.User{ID: id, Role: models.RoleEditor}, nil
+}
+
+func TestModerationService(t *testing.T) {
+	type step struct {
+		// report: userID que denuncia; dismiss/action: ID da denúncia
+		op      string
+		id      uint
+		wantErr error
+	}
+
+	tests := []struct {
+		name       string
+		steps      []step
+		wantHidden bool
+		wantReason string
+		// Decisões registradas, incluindo a da ocultação automática
+		wantDecisions int
+	}{
+		{
+			name:          "limite de denunciantes distintos oculta o conteúdo",
+			steps:         []step{{op: "report", id: 1}, {op: "report", id: 2}, {op: "report", id: 3}},
+			wantHidden:    true,
+			wantReason:    models.HiddenReasonReports,
+			wantDecisions: 1,
+		},
+		{
+			name: "mesmo usuário não denuncia duas vezes",
+			steps: []step{
+				{op: "report", id: 1},
+				{op: "report", id: 1, wantErr: repository.ErrDuplicateReport},
+				{op: "report", id: 1, wantErr: repository.ErrDuplicateReport},
+			},
+			wantHidden: false,
+		},
+		{
+			name: "descartar denúncia desfaz a ocultação automática",
+			steps: []step{
+				{op: "report", id: 1}, {op: "report", id: 2}, {op: "report", id: 3},
+				{op: "dismiss", id: 1},
+			},
+			wantHidden:    false,
+			wantDecisions: 2,
+		},
+		{
+			name: "descartar denúncia não desfaz a ocultação do moderador",
+			steps: []step{
+				{op: "report", id: 1}, {op: "report", id: 2},
+				{op: "action", id: 1},
+				{op: "dismiss", id: 2},
+			},
+			wantHidden:    true,
+			wantReason:    models.HiddenReasonModerator,
+			wantDecisions: 2,
+		},
+		{
+			name: "acatar depois da ocultação automática mantém o conteúdo oculto",
+			steps: []step{
+				{op: "report", id: 1}, {op: "report", id: 2}, {op: "report", id: 3},
+				{op: "action", id: 1},
+				{op: "dismiss", id: 2},
+			},
+			wantHidden:    true,
+			wantReason:    models.HiddenReasonModerator,
+			wantDecisions: 3,
+		},
+		{
+			name: "denúncia já revisada não é resolvida de novo",
+			steps: []step{
+				{op: "report", id: 1}, {op: "report", id: 2},
+				{op: "action", id: 1},
+				{op: "dismiss", id: 1, wantErr: repository.ErrReportResolved},
+				{op: "action", id: 1, wantErr: repository.ErrReportResolved},
+			},
+			wantHidden:    true,
+			wantReason:    models.HiddenReasonModerator,
+			wantDecisions: 1,
+		},
+		{
+			name:  "denúncia inexistente",
+			steps: []step{{op: "dismiss", id: 7, wantErr: repository.ErrReportNotFound}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contents := &fakeModerationContentRepository{content: &models.Content{ID: 10}}
+			reports := &fakeReportRepository{contents: contents}
+			svc := NewModerationService(reports, contents, fakeModeratorRepository{}, 3)
+
+			for _, st := range tt.steps {
+				var err error
+				switch st.op {
+				case "report":
+					_, err = svc.ReportContent(10, st.id, "wrong", "")
+				case "dismiss":
+					_, err = svc.DismissReport(st.id, 99, "")
+				case "action":
+					_, err = svc.ActionReport(st.id, 99, "")
+				}
+				if !errors.Is(err, st.wantErr) {
+					t.Fatalf("%s %d: erro %v, esperado %v", st.op, st.id, err, st.wantErr)
+				}
+			}
+
+			if contents.content.Hidden != tt.wantHidden {
+				t.Errorf("hidden = %v, esperado %v", contents.content.Hidden, tt.wantHidden)
+			}
+			reason := ""
+			if contents.content.HiddenReason != nil {
+				reason = *contents.content.HiddenReason
+			}
+			if reason != tt.wantReason {
+				t.Errorf("hidden_reason = %q, esperado %q", reason, tt.wantReason)
+			}
+			if len(reports.decisions) != tt.wantDecisions {
+				t.Errorf("decisões = %d, esperado %d", len(reports.decisions), tt.wantDecisions)
+			}
+		})
+	}
+}
@@ -0,0 +1,295 @@
+package service
+
+import (
+	"backend-go/models"
+	"backend-go/repository"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Eventos do outbox entregues por lote
+const outboxBatchSize = 100
+
+// OutboxPolicy define as tentativas e o backoff das entregas do outbox
+type OutboxPolicy struct {
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// Eventos entregues há mais tempo que isso são removidos
+	DeliveredRetention time.Duration
+}
+
+// OutboxStatus resume o estado do outbox para acompanhamento
+type OutboxStatus struct {
+	Pending         int64      `json:"pending"`
+	Dead            int64      `json:"dead"`
+	Delivered       int64      `json:"delivered"`
+	OldestPendingAt *time.Time `json:"oldest_pending_at,omitempty"`
+	LagSeconds      float64    `json:"lag_seconds"`
+	MaxAttempts     int        `json:"max_attempts"`
+}
+
+// InteractionDelivery entrega ao motor Python um lote de interações do outbox
+type InteractionDelivery interface {
+	DeliverInteractions(interactions []InteractionRequest) error
+}
+
+// OutboxService define a interface para a entrega dos eventos do outbox
+type OutboxService interface {
+	Dispatch() (int, error)
+	Status() (*OutboxStatus, error)
+	ListEvents(status string, page, limit int) ([]models.OutboxEvent, int64, error)
+	Retry(id uint) (*models.OutboxEvent, error)
+	PurgeDelivered() (int64, error)
+}
+
+type outboxService struct {
+	repo     repository.OutboxRepository
+	delivery InteractionDelivery
+	policy   OutboxPolicy
+	mu       sync.Mutex
+}
+
+// NewOutboxService cria uma nova instância do OutboxService
+func NewOutboxService(
+	repo repository.OutboxRepository,
+	delivery InteractionDelivery,
+	policy OutboxPolicy,
+) OutboxService {
+	return &outboxService{
+		repo:     repo,
+		delivery: delivery,
+		policy:   normalizeOutboxPolicy(policy),
+	}
+}
+
+// normalizeOutboxPolicy garante ao menos uma tentativa e um backoff válido
+func normalizeOutboxPolicy(policy OutboxPolicy) OutboxPolicy {
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+	if policy.BaseBackoff <= 0 {
+		policy.BaseBackoff = time.Second
+	}
+	if policy.MaxBackoff < policy.BaseBackoff {
+		policy.MaxBackoff = policy.BaseBackoff
+	}
+	return policy
+}
+
+// domainEventOutbox retorna a OutboxFunc que enfileira um evento de domínio
+// para os webhooks e para o barramento, na transação da escrita. event é
+// chamada depois da escrita, com os IDs já gerados, e retorna os dados do
+// evento e a chave do agregado, que mantém a ordem das publicações no
+// barramento.
+func domainEventOutbox(eventType string, event func() (interface{}, string)) repository.OutboxFunc {
+	return func() ([]*models.OutboxEvent, error) {
+		data, aggregateKey := event()
+		webhookEvent, err := newWebhookOutboxEvent(eventType, data)
+		if err != nil {
+			return nil, err
+		}
+		busEvent, err := newBusOutboxEvent(eventType, data, aggregateKey)
+		if err != nil {
+			return nil, err
+		}
+		return []*models.OutboxEvent{webhookEvent, busEvent}, nil
+	}
+}
+
+// newRecommenderOutboxEvents monta os eventos do outbox que repassam as
+// interações ao motor Python. Interações sinalizadas como abuso não são repassadas.
+func newRecommenderOutboxEvents(interactions []*models.UserInteraction) ([]*models.OutboxEvent, error) {
+	events := make([]*models.OutboxEvent, 0, len(interactions))
+	for _, interaction := range interactions {
+		if interaction.Flagged {
+			continue
+		}
+		event, err := newRecommenderOutboxEvent(InteractionRequest{
+			UserID:              int(interaction.UserID),
+			ContentID:           int(interaction.ContentID),
+			InteractionType:     interaction.InteractionType,
+			Rating:              interaction.Rating,
+			InteractionMetadata: interactionMetadata(interaction),
+		})
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// newRecommenderOutboxEvent monta um evento do outbox para o motor Python.
+// Os eventos de um mesmo par usuário-conteúdo são entregues em ordem.
+func newRecommenderOutboxEvent(request InteractionRequest) (*models.OutboxEvent, error) {
+	payload, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar evento do outbox: %w", err)
+	}
+	return &models.OutboxEvent{
+		Topic:        models.OutboxTopicRecommenderInteraction,
+		AggregateKey: fmt.Sprintf("user:%d|content:%d", request.UserID, request.ContentID),
+		Payload:      string(payload),
+	}, nil
+}
+
+// Dispatch entrega ao motor Python (por HTTP ou pelo barramento), em lotes, os eventos pendentes cuja
+// próxima tentativa já venceu. Um lote com falha é reagendado com backoff
+// exponencial; ao esgotar as tentativas, o evento vai para o status dead.
+// A entrega é "pelo menos uma vez". Retorna o número de eventos entregues.
+func (s *outboxService) Dispatch() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delivered := 0
+	for {
+		events, err := s.repo.ListDue(models.OutboxTopicRecommenderInteraction, time.Now(), outboxBatchSize)
+		if err != nil {
+			return delivered, err
+		}
+		if len(events) == 0 {
+			return delivered, nil
+		}
+
+		requests := make([]InteractionRequest, 0, len(events))
+		batch := make([]*models.OutboxEvent, 0, len(events))
+		for i := range events {
+			var request InteractionRequest
+			if err := json.Unmarshal([]byte(events[i].Payload), &request); err != nil {
+				// Payload inválido nunca será entregue: vai direto para dead
+				if err := s.fail(&events[i], fmt.Errorf("payload inválido: %w", err), true); err != nil {
+					return delivered, err
+				}
+				continue
+			}
+			requests = append(requests, request)
+			batch = append(batch, &events[i])
+		}
+
+		if deliverErr := s.delivery.DeliverInteractions(requests); deliverErr != nil {
+			for _, event := range batch {
+				if err := s.fail(event, deliverErr, false); err != nil {
+					return delivered, err
+				}
+			}
+			// O motor está indisponível; os próximos lotes esperam o backoff
+			return delivered, deliverErr
+		}
+
+		ids := make([]uint, len(batch))
+		for i, event := range batch {
+			ids[i] = event.ID
+		}
+		if err := s.repo.MarkDelivered(ids, time.Now()); err != nil {
+			return delivered, err
+		}
+		delivered += len(batch)
+
+		if len(events) < outboxBatchSize {
+			return delivered, nil
+		}
+	}
+}
+
+// fail registra uma tentativa sem sucesso e agenda a próxima
+func (s *outboxService) fail(event *models.OutboxEvent, cause error, permanent bool) error {
+	return failOutboxEvent(s.repo, s.policy, event, cause, permanent)
+}
+
+// failOutboxEvent registra uma tentativa sem sucesso de um evento do outbox e
+// agenda a próxima; ao esgotar as tentativas (ou com permanent), o evento
+// vai para o status dead
+func failOutboxEvent(repo repository.OutboxRepository, policy OutboxPolicy, event *models.OutboxEvent, cause error, permanent bool) error {
+	message := cause.Error()
+	event.Attempts++
+	event.LastError = &message
+	if permanent || event.Attempts >= policy.MaxAttempts {
+		event.Status = models.OutboxStatusDead
+	} else {
+		event.NextAttemptAt = time.Now().Add(exponentialBackoff(policy.BaseBackoff, policy.MaxBackoff, event.Attempts))
+	}
+	return repo.Update(event)
+}
+
+// exponentialBackoff retorna a espera antes da próxima tentativa: base
+// dobrada a cada tentativa, limitada a max
+func exponentialBackoff(base, max time.Duration, attempts int) time.Duration {
+	wait := base
+	for i := 1; i < attempts; i++ {
+		wait *= 2
+		if wait >= max {
+			return max
+		}
+	}
+	return wait
+}
+
+// Status retorna as contagens por status e o atraso do evento pendente mais antigo
+func (s *outboxService) Status() (*OutboxStatus, error) {
+	stats, err := s.repo.Stats()
+	if err != nil {
+		return nil, err
+	}
+
+	status := &OutboxStatus{
+		Pending:         stats.Pending,
+		Dead:            stats.Dead,
+		Delivered:       stats.Delivered,
+		OldestPendingAt: stats.OldestPendingAt,
+		MaxAttempts:     s.policy.MaxAttempts,
+	}
+	if stats.OldestPendingAt != nil {
+		status.LagSeconds = time.Since(*stats.OldestPendingAt).Seconds()
+	}
+	return status, nil
+}
+
+// ListEvents lista os eventos do outbox, opcionalmente filtrados por status
+func (s *outboxService) ListEvents(status string, page, limit int) ([]models.OutboxEvent, int64, error) {
+	switch status {
+	case "", models.OutboxStatusPending, models.OutboxStatusDelivered, models.OutboxStatusDead:
+	default:
+		return nil, 0, errors.New("status inválido. Valores válidos: pending, delivered, dead")
+	}
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 50
+	}
+	if limit > 200 {
+		limit = 200 // Limite máximo
+	}
+	return s.repo.ListByStatus(status, limit, (page-1)*limit)
+}
+
+// Retry devolve um evento dead para a fila, com as tentativas zeradas
+func (s *outboxService) Retry(id uint) (*models.OutboxEvent, error) {
+	event, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status != models.OutboxStatusDead {
+		return nil, errors.New("apenas eventos com status dead podem ser reenfileirados")
+	}
+
+	event.Status = models.OutboxStatusPending
+	event.Attempts = 0
+	event.NextAttemptAt = time.Now()
+	if err := s.repo.Update(event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// PurgeDelivered remove os eventos entregues fora da janela de retenção
+func (s *outboxService) PurgeDelivered() (int64, error) {
+	if s.policy.DeliveredRetention <= 0 {
+		return 0, nil
+	}
+	return s.repo.PurgeDelivered(time.Now().Add(-s.policy.DeliveredRetention))
+}
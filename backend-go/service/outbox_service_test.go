@@ -0,0 +1,180 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"backend-go/models"
+	"backend-go/repository"
+)
+
+// fakeOutboxRepository guarda os eventos em memória e aplica em ListDue a
+// mesma regra de retenção por agregado da consulta real
+type fakeOutboxRepository struct {
+	repository.OutboxRepository
+	events []*models.OutboxEvent
+}
+
+func (r *fakeOutboxRepository) insert(events []*models.OutboxEvent) {
+	for _, event := range events {
+		event.ID = uint(len(r.events) + 1)
+		event.Status = models.OutboxStatusPending
+		event.NextAttemptAt = time.Now()
+		r.events = append(r.events, event)
+	}
+}
+
+func (r *fakeOutboxRepository) ListDue(topic string, now time.Time, limit int) ([]models.OutboxEvent, error) {
+	sort.Slice(r.events, func(i, j int) bool { return r.events[i].ID < r.events[j].ID })
+
+	var due []models.OutboxEvent
+	for _, event := range r.events {
+		if event.Status != models.OutboxStatusPending || event.Topic != topic || event.NextAttemptAt.After(now) {
+			continue
+		}
+		blocked := false
+		for _, earlier := range r.events {
+			if event.AggregateKey != "" && earlier.ID < event.ID && earlier.Topic == topic &&
+				earlier.AggregateKey == event.AggregateKey &&
+				earlier.Status == models.OutboxStatusPending && earlier.NextAttemptAt.After(now) {
+				blocked = true
+				break
+			}
+		}
+		if !blocked && len(due) < limit {
+			due = append(due, *event)
+		}
+	}
+	return due, nil
+}
+
+func (r *fakeOutboxRepository) MarkDelivered(ids []uint, at time.Time) error {
+	for _, id := range ids {
+		r.events[id-1].Status = models.OutboxStatusDelivered
+		r.events[id-1].DeliveredAt = &at
+	}
+	return nil
+}
+
+func (r *fakeOutboxRepository) Update(event *models.OutboxEvent) error {
+	stored := *event
+	r.events[event.ID-1] = &stored
+	return nil
+}
+
+// fakeInteractionDelivery registra as interações entregues, na ordem
+type fakeInteractionDelivery struct {
+	fail      bool
+	delivered []string
+}
+
+func (d *fakeInteractionDelivery) DeliverInteractions(interactions []InteractionRequest) error {
+	if d.fail {
+		return errors.New("motor indisponível")
+	}
+	for _, interaction := range interactions {
+		d.delivered = append(d.delivered, fmt.Sprintf("%s:%d", interaction.InteractionType, interaction.ContentID))
+	}
+	return nil
+}
+
+func TestOutboxRetryOrdering(t *testing.T) {
+	type step struct {
+		// insert: grava o evento; dispatch/fail: entrega com o motor no ar ou
+		// fora; expire: vence o backoff dos eventos (todos, ou só os com
+		// attempts tentativas)
+		op              string
+		contentID       uint
+		interactionType string
+		attempts        int
+	}
+	insert := func(contentID uint, interactionType string) step {
+		return step{op: "insert", contentID: contentID, interactionType: interactionType}
+	}
+	dispatch, fail, expire := step{op: "dispatch"}, step{op: "fail"}, step{op: "expire"}
+
+	tests := []struct {
+		name        string
+		maxAttempts int
+		steps       []step
+		want        []string
+	}{
+		{
+			name:  "entrega na ordem de criação",
+			steps: []step{insert(2, "like"), insert(2, "unlike"), dispatch},
+			want:  []string{"like:2", "unlike:2"},
+		},
+		{
+			name:  "evento novo não ultrapassa o anterior em backoff",
+			steps: []step{insert(2, "like"), fail, insert(2, "unlike"), dispatch},
+			want:  nil,
+		},
+		{
+			name:  "eventos retidos saem em ordem depois do backoff",
+			steps: []step{insert(2, "like"), fail, insert(2, "unlike"), dispatch, expire, dispatch},
+			want:  []string{"like:2", "unlike:2"},
+		},
+		{
+			name: "backoff menor do evento posterior não inverte a ordem",
+			steps: []step{
+				insert(2, "like"), fail, expire, insert(2, "unlike"), fail,
+				{op: "expire", attempts: 1}, dispatch, expire, dispatch,
+			},
+			want: []string{"like:2", "unlike:2"},
+		},
+		{
+			name:  "outros conteúdos não ficam retidos",
+			steps: []step{insert(2, "like"), fail, insert(3, "view"), dispatch, expire, dispatch},
+			want:  []string{"view:3", "like:2"},
+		},
+		{
+			name:        "evento dead libera os seguintes",
+			maxAttempts: 1,
+			steps:       []step{insert(2, "like"), fail, insert(2, "unlike"), dispatch},
+			want:        []string{"unlike:2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxAttempts := tt.maxAttempts
+			if maxAttempts == 0 {
+				maxAttempts = 5
+			}
+			repo := &fakeOutboxRepository{}
+			delivery := &fakeInteractionDelivery{}
+			svc := NewOutboxService(repo, delivery, OutboxPolicy{MaxAttempts: maxAttempts, BaseBackoff: time.Minute, MaxBackoff: time.Hour})
+
+			for i, st := range tt.steps {
+				switch st.op {
+				case "insert":
+					events, err := newRecommenderOutboxEvents([]*models.UserInteraction{
+						{UserID: 1, ContentID: st.contentID, InteractionType: st.interactionType},
+					})
+					if err != nil {
+						t.Fatal(err)
+					}
+					repo.insert(events)
+				case "dispatch", "fail":
+					delivery.fail = st.op == "fail"
+					if _, err := svc.Dispatch(); (err != nil) != delivery.fail {
+						t.Fatalf("passo %d (%s): erro %v", i, st.op, err)
+					}
+				case "expire":
+					for _, event := range repo.events {
+						if st.attempts == 0 || event.Attempts == st.attempts {
+							event.NextAttemptAt = time.Now().Add(-time.Second)
+						}
+					}
+				}
+			}
+
+			if fmt.Sprint(delivery.delivered) != fmt.Sprint(tt.want) {
+				t.Errorf("entregues = %v, esperado %v", delivery.delivered, tt.want)
+			}
+		})
+	}
+}
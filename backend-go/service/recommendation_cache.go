@@ -0,0 +1,288 @@
+package service
+
+import (
+	"backend-go/cache"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Situação da lista servida em relação ao cache
+const (
+	CacheHit    = "hit"
+	CacheStale  = "stale"
+	CacheMiss   = "miss"
+	CacheBypass = "bypass"
+)
+
+// RecommendationCachePolicy define por quanto tempo uma lista é servida do cache
+type RecommendationCachePolicy struct {
+	// Idade até a qual a lista é servida sem recálculo
+	TTL time.Duration
+	// Janela após o TTL em que a lista ainda é servida enquanto é recalculada
+	// em segundo plano (stale-while-revalidate)
+	Stale time.Duration
+}
+
+// RecommendationCacheStats são as métricas do cache de recomendações
+type RecommendationCacheStats struct {
+	Enabled       bool    `json:"enabled"`
+	Hits          int64   `json:"hits"`
+	StaleHits     int64   `json:"stale_hits"`
+	Misses        int64   `json:"misses"`
+	HitRate       float64 `json:"hit_rate"`
+	Refreshes     int64   `json:"refreshes"`
+	RefreshErrors int64   `json:"refresh_errors"`
+	Invalidations int64   `json:"invalidations"`
+	StoreErrors   int64   `json:"store_errors"`
+	Users         int     `json:"users"`
+}
+
+// RecommendationCache guarda as listas recomendadas por usuário, método e
+// parâmetros. Como EventEmitter, invalida as listas do usuário a cada
+// interaction.created.
+type RecommendationCache interface {
+	EventEmitter
+	Get(userID uint, topN int, method string, load func() (*RecommendationResult, error)) (*RecommendationResult, string, error)
+	Invalidate(userID uint) error
+	Stats() RecommendationCacheStats
+}
+
+// cachedRecommendation é uma lista guardada no cache
+type cachedRecommendation struct {
+	ContentIDs   []uint           `json:"content_ids"`
+	Scores       map[uint]float64 `json:"scores,omitempty"`
+	Method       string           `json:"method"`
+	ModelVersion string           `json:"model_version,omitempty"`
+	StoredAt     time.Time        `json:"stored_at"`
+}
+
+// cachedUserRecommendations agrupa as listas de um usuário em uma única
+// chave, para a invalidação ser uma só escrita. InvalidatedAt impede que um
+// cálculo iniciado antes da invalidação seja gravado depois dela.
+type cachedUserRecommendations struct {
+	InvalidatedAt time.Time                       `json:"invalidated_at,omitempty"`
+	Entries       map[string]cachedRecommendation `json:"entries,omitempty"`
+}
+
+type recommendationCache struct {
+	store  cache.Store
+	policy RecommendationCachePolicy
+	// Serializa a leitura e a gravação das listas de cada usuário, para que
+	// uma invalidação não seja sobrescrita por uma gravação concorrente
+	users userLocks
+
+	mu       sync.Mutex
+	inflight map[string]bool
+	stats    RecommendationCacheStats
+}
+
+// NewRecommendationCache cria o cache de recomendações sobre o store
+// informado; com store nil, toda chamada calcula a lista (bypass)
+func NewRecommendationCache(store cache.Store, policy RecommendationCachePolicy) RecommendationCache {
+	if policy.TTL <= 0 {
+		policy.TTL = 5 * time.Minute
+	}
+	if policy.Stale < 0 {
+		policy.Stale = 0
+	}
+	return &recommendationCache{
+		store:    store,
+		policy:   policy,
+		inflight: make(map[string]bool),
+		stats:    RecommendationCacheStats{Enabled: store != nil},
+	}
+}
+
+func recommendationCacheKey(userID uint) string {
+	return fmt.Sprintf("recommendations:user:%d", userID)
+}
+
+// Get retorna a lista do cache ou a calcula com load. Listas com idade entre
+// o TTL e TTL+Stale são servidas e recalculadas em segundo plano.
+func (c *recommendationCache) Get(
+	userID uint,
+	topN int,
+	method string,
+	load func() (*RecommendationResult, error),
+) (*RecommendationResult, string, error) {
+	if c.store == nil {
+		result, err := load()
+		return result, CacheBypass, err
+	}
+
+	variant := fmt.Sprintf("%s:%d", method, topN)
+	bucket := c.read(userID)
+	if entry, ok := bucket.Entries[variant]; ok {
+		age := time.Since(entry.StoredAt)
+		if age < c.policy.TTL {
+			c.count(func(s *RecommendationCacheStats) { s.Hits++ })
+			return entry.result(), CacheHit, nil
+		}
+		if age < c.policy.TTL+c.policy.Stale {
+			c.count(func(s *RecommendationCacheStats) { s.StaleHits++ })
+			c.refresh(userID, variant, load)
+			return entry.result(), CacheStale, nil
+		}
+	}
+
+	c.count(func(s *RecommendationCacheStats) { s.Misses++ })
+	startedAt := time.Now()
+	result, err := load()
+	if err != nil {
+		return nil, CacheMiss, err
+	}
+	c.write(userID, variant, result, startedAt)
+	return result, CacheMiss, nil
+}
+
+// refresh recalcula a lista em segundo plano, uma vez por usuário e variante
+func (c *recommendationCache) refresh(userID uint, variant string, load func() (*RecommendationResult, error)) {
+	key := fmt.Sprintf("%d:%s", userID, variant)
+	c.mu.Lock()
+	if c.inflight[key] {
+		c.mu.Unlock()
+		return
+	}
+	c.inflight[key] = true
+	c.stats.Refreshes++
+	c.mu.Unlock()
+
+	go func() {
+		defer func() {
+			c.mu.Lock()
+			delete(c.inflight, key)
+			c.mu.Unlock()
+		}()
+
+		startedAt := time.Now()
+		result, err := load()
+		if err != nil {
+			c.count(func(s *RecommendationCacheStats) { s.RefreshErrors++ })
+			log.Printf("erro ao recalcular recomendações do usuário %d em cache: %v", userID, err)
+			return
+		}
+		c.write(userID, variant, result, startedAt)
+	}()
+}
+
+// read lê as listas do usuário; erros do store contam como cache vazio
+func (c *recommendationCache) read(userID uint) cachedUserRecommendations {
+	var bucket cachedUserRecommendations
+	data, ok, err := c.store.Get(recommendationCacheKey(userID))
+	if err == nil && ok {
+		err = json.Unmarshal(data, &bucket)
+	}
+	if err != nil {
+		c.count(func(s *RecommendationCacheStats) { s.StoreErrors++ })
+		log.Printf("erro ao ler recomendações do usuário %d do cache: %v", userID, err)
+		return cachedUserRecommendations{}
+	}
+	return bucket
+}
+
+// write grava a lista calculada a partir de startedAt, a menos que o usuário
+// tenha sido invalidado depois disso. Listas do ranking de fallback não são
+// guardadas, para o motor voltar a ser consultado assim que se recuperar.
+// O lock é por instância: com o store compartilhado entre réplicas, uma
+// invalidação feita em outra réplica ainda pode ser sobrescrita.
+func (c *recommendationCache) write(userID uint, variant string, result *RecommendationResult, startedAt time.Time) {
+	if result.Method == MethodPopularityFallback {
+		return
+	}
+
+	unlock := c.users.lock([]uint{userID})
+	defer unlock()
+
+	bucket := c.read(userID)
+	if bucket.InvalidatedAt.After(startedAt) {
+		return
+	}
+
+	now := time.Now()
+	entries := make(map[string]cachedRecommendation, len(bucket.Entries)+1)
+	for key, entry := range bucket.Entries {
+		if now.Sub(entry.StoredAt) < c.policy.TTL+c.policy.Stale {
+			entries[key] = entry
+		}
+	}
+	entries[variant] = cachedRecommendation{
+		ContentIDs:   result.ContentIDs,
+		Scores:       result.Scores,
+		Method:       result.Method,
+		ModelVersion: result.ModelVersion,
+		StoredAt:     now,
+	}
+	bucket.Entries = entries
+	_ = c.save(userID, bucket)
+}
+
+func (c *recommendationCache) save(userID uint, bucket cachedUserRecommendations) error {
+	data, err := json.Marshal(bucket)
+	if err == nil {
+		err = c.store.Set(recommendationCacheKey(userID), data, c.policy.TTL+c.policy.Stale)
+	}
+	if err != nil {
+		c.count(func(s *RecommendationCacheStats) { s.StoreErrors++ })
+		log.Printf("erro ao gravar recomendações do usuário %d no cache: %v", userID, err)
+	}
+	return err
+}
+
+// Invalidate descarta as listas do usuário
+func (c *recommendationCache) Invalidate(userID uint) error {
+	if c.store == nil {
+		return nil
+	}
+	c.count(func(s *RecommendationCacheStats) { s.Invalidations++ })
+	unlock := c.users.lock([]uint{userID})
+	defer unlock()
+	return c.save(userID, cachedUserRecommendations{InvalidatedAt: time.Now()})
+}
+
+// Emit invalida as listas do usuário que registrou uma nova interação
+func (c *recommendationCache) Emit(eventType string, data interface{}) {
+	if eventType != EventInteractionCreated {
+		return
+	}
+	switch interaction := data.(type) {
+	case InteractionEventData:
+		_ = c.Invalidate(interaction.UserID)
+	case *InteractionEventData:
+		_ = c.Invalidate(interaction.UserID)
+	}
+}
+
+func (c *recommendationCache) count(update func(s *RecommendationCacheStats)) {
+	c.mu.Lock()
+	update(&c.stats)
+	c.mu.Unlock()
+}
+
+// Stats retorna os contadores do cache e a taxa de acerto (listas servidas
+// do cache, frescas ou não, sobre o total de consultas)
+func (c *recommendationCache) Stats() RecommendationCacheStats {
+	c.mu.Lock()
+	stats := c.stats
+	c.mu.Unlock()
+
+	if lookups := stats.Hits + stats.StaleHits + stats.Misses; lookups > 0 {
+		stats.HitRate = float64(stats.Hits+stats.StaleHits) / float64(lookups)
+	}
+	if c.store != nil {
+		stats.Users = c.store.Len()
+	}
+	return stats
+}
+
+// result converte a lista guardada no resultado servido
+func (e cachedRecommendation) result() *RecommendationResult {
+	return &RecommendationResult{
+		ContentIDs:   e.ContentIDs,
+		Scores:       e.Scores,
+		Method:       e.Method,
+		ModelVersion: e.ModelVersion,
+	}
+}
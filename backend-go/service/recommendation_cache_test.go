@@ -0,0 +1,133 @@
+package service
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"backend-go/cache"
+)
+
+// hookedStore executa afterGet logo depois da leitura de número afterGetCall,
+// antes de devolver o valor lido
+type hookedStore struct {
+	cache.Store
+	gets         int
+	afterGetCall int
+	afterGet     func()
+}
+
+func (s *hookedStore) Get(key string) ([]byte, bool, error) {
+	s.gets++
+	value, ok, err := s.Store.Get(key)
+	if s.afterGet != nil && s.gets == s.afterGetCall {
+		s.afterGet()
+	}
+	return value, ok, err
+}
+
+// cacheTestEnv é o cache sob teste e o que cada caso pode ajustar antes da
+// primeira consulta
+type cacheTestEnv struct {
+	cache *recommendationCache
+	store *hookedStore
+	load  func() (*RecommendationResult, error)
+	// Invalidações concorrentes ainda em andamento
+	pending sync.WaitGroup
+}
+
+func TestRecommendationCacheInvalidation(t *testing.T) {
+	fresh := func() (*RecommendationResult, error) {
+		return &RecommendationResult{ContentIDs: []uint{1, 2}, Method: "similarity"}, nil
+	}
+
+	tests := []struct {
+		name string
+		// prepare ajusta o store e o load da primeira consulta
+		prepare func(env *cacheTestEnv)
+		// between roda entre a primeira e a segunda consulta
+		between func(env *cacheTestEnv)
+		// Situação esperada na segunda consulta
+		want string
+	}{
+		{
+			name: "lista calculada é servida do cache",
+			want: CacheHit,
+		},
+		{
+			name: "interação descarta a lista do usuário",
+			between: func(env *cacheTestEnv) {
+				env.cache.Emit(EventInteractionCreated, InteractionEventData{UserID: 1})
+			},
+			want: CacheMiss,
+		},
+		{
+			name: "invalidação durante o cálculo não é sobrescrita",
+			prepare: func(env *cacheTestEnv) {
+				env.load = func() (*RecommendationResult, error) {
+					time.Sleep(time.Millisecond)
+					_ = env.cache.Invalidate(1)
+					return fresh()
+				}
+			},
+			want: CacheMiss,
+		},
+		{
+			name: "invalidação entre a leitura e a gravação não é perdida",
+			prepare: func(env *cacheTestEnv) {
+				// A segunda leitura é a de write; a invalidação concorrente
+				// precisa esperar a gravação terminar e prevalecer sobre ela
+				env.store.afterGetCall = 2
+				env.store.afterGet = func() {
+					done := make(chan struct{})
+					env.pending.Add(1)
+					go func() {
+						defer env.pending.Done()
+						_ = env.cache.Invalidate(1)
+						close(done)
+					}()
+					select {
+					case <-done:
+					case <-time.After(50 * time.Millisecond):
+					}
+				}
+			},
+			want: CacheMiss,
+		},
+		{
+			name: "lista de fallback não é guardada",
+			prepare: func(env *cacheTestEnv) {
+				env.load = func() (*RecommendationResult, error) {
+					return &RecommendationResult{ContentIDs: []uint{3}, Method: MethodPopularityFallback}, nil
+				}
+			},
+			want: CacheMiss,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := &cacheTestEnv{store: &hookedStore{Store: cache.NewLRU(10)}, load: fresh}
+			env.cache = NewRecommendationCache(env.store, RecommendationCachePolicy{TTL: time.Minute}).(*recommendationCache)
+			if tt.prepare != nil {
+				tt.prepare(env)
+			}
+
+			if _, status, err := env.cache.Get(1, 10, "similarity", env.load); err != nil || status != CacheMiss {
+				t.Fatalf("primeira consulta = %s, %v; esperado %s", status, err, CacheMiss)
+			}
+			env.pending.Wait()
+			if tt.between != nil {
+				tt.between(env)
+			}
+
+			_, status, err := env.cache.Get(1, 10, "similarity", fresh)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if status != tt.want {
+				t.Errorf("segunda consulta = %s, esperado %s", status, tt.want)
+			}
+		})
+	}
+}
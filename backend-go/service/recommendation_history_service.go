@@ -0,0 +1,159 @@
+package service
+
+import (
+	"backend-go/models"
+	"backend-go/repository"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+
+	"gorm.io/datatypes"
+)
+
+// RecommendationContext descreve onde a lista recomendada será exibida.
+// Todos os campos são opcionais.
+type RecommendationContext struct {
+	SessionID  *string
+	Device     *string
+	AppVersion *string
+	Surface    *string
+}
+
+// RecommendationHistoryQuery define filtros e paginação do histórico de recomendações
+type RecommendationHistoryQuery struct {
+	From  *time.Time
+	To    *time.Time
+	Page  int
+	Limit int
+}
+
+// Normalize aplica os valores padrão e o limite máximo de paginação
+func (q *RecommendationHistoryQuery) Normalize() {
+	if q.Page < 1 {
+		q.Page = 1
+	}
+	if q.Limit < 1 {
+		q.Limit = 20
+	}
+	if q.Limit > 100 {
+		q.Limit = 100 // Limite máximo
+	}
+}
+
+// RecommendationHistoryService define a interface para o histórico de recomendações servidas
+type RecommendationHistoryService interface {
+	Record(userID uint, requestedMethod string, result *RecommendationResult, reqCtx RecommendationContext) (*models.Recommendation, error)
+	ListByUser(userID uint, query RecommendationHistoryQuery) ([]models.Recommendation, int64, error)
+	Get(id uint) (*models.Recommendation, error)
+	Purge() (int64, error)
+}
+
+type recommendationHistoryService struct {
+	repo      repository.RecommendationRepository
+	userRepo  repository.UserRepository
+	retention time.Duration
+}
+
+// NewRecommendationHistoryService cria uma nova instância do
+// RecommendationHistoryService. As recomendações ficam guardadas por
+// retention; zero mantém o histórico indefinidamente.
+func NewRecommendationHistoryService(
+	repo repository.RecommendationRepository,
+	userRepo repository.UserRepository,
+	retention time.Duration,
+) RecommendationHistoryService {
+	return &recommendationHistoryService{
+		repo:      repo,
+		userRepo:  userRepo,
+		retention: retention,
+	}
+}
+
+// Record grava a lista servida com o método, a versão do modelo, as
+// pontuações dos conteúdos da lista e o contexto da requisição, e enfileira
+// o evento recommendation.served na mesma transação
+func (s *recommendationHistoryService) Record(
+	userID uint,
+	requestedMethod string,
+	result *RecommendationResult,
+	reqCtx RecommendationContext,
+) (*models.Recommendation, error) {
+	contentIDs, err := json.Marshal(result.ContentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	scores := make(map[string]float64, len(result.ContentIDs))
+	for _, contentID := range result.ContentIDs {
+		if score, ok := result.Scores[contentID]; ok {
+			scores[strconv.FormatUint(uint64(contentID), 10)] = score
+		}
+	}
+	scoresJSON, err := json.Marshal(scores)
+	if err != nil {
+		return nil, err
+	}
+
+	recommendation := &models.Recommendation{
+		UserID:                userID,
+		RecommendedContentIDs: datatypes.JSON(contentIDs),
+		Scores:                datatypes.JSON(scoresJSON),
+		Method:                result.Method,
+		RequestedMethod:       requestedMethod,
+		ModelVersion:          result.ModelVersion,
+		SessionID:             reqCtx.SessionID,
+		Device:                reqCtx.Device,
+		AppVersion:            reqCtx.AppVersion,
+		Surface:               reqCtx.Surface,
+	}
+	// O evento recommendation.served é gravado no outbox junto com o histórico
+	served := func() ([]*models.OutboxEvent, error) {
+		event, err := newBusOutboxEvent(EventRecommendationServed, RecommendationServedEventData{
+			UserID:     userID,
+			Method:     result.Method,
+			ContentIDs: append([]uint{}, result.ContentIDs...),
+			ServedAt:   recommendation.CreatedAt,
+		}, userAggregateKey(userID))
+		if err != nil {
+			return nil, err
+		}
+		return []*models.OutboxEvent{event}, nil
+	}
+	if err := s.repo.Create(recommendation, served); err != nil {
+		return nil, err
+	}
+	return recommendation, nil
+}
+
+// ListByUser lista as recomendações servidas ao usuário com paginação
+func (s *recommendationHistoryService) ListByUser(userID uint, query RecommendationHistoryQuery) ([]models.Recommendation, int64, error) {
+	if userID == 0 {
+		return nil, 0, errors.New("ID inválido")
+	}
+	if query.From != nil && query.To != nil && !query.From.Before(*query.To) {
+		return nil, 0, errors.New("from deve ser anterior a to")
+	}
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		return nil, 0, err
+	}
+
+	query.Normalize()
+	return s.repo.ListByUserID(userID, query.From, query.To, query.Limit, (query.Page-1)*query.Limit)
+}
+
+// Get busca uma recomendação servida pelo ID
+func (s *recommendationHistoryService) Get(id uint) (*models.Recommendation, error) {
+	if id == 0 {
+		return nil, errors.New("ID inválido")
+	}
+	return s.repo.GetByID(id)
+}
+
+// Purge remove as recomendações fora da janela de retenção
+func (s *recommendationHistoryService) Purge() (int64, error) {
+	if s.retention <= 0 {
+		return 0, nil
+	}
+	return s.repo.PurgeBefore(time.Now().Add(-s.retention))
+}
@@ -1,38 +1,49 @@
 package service
 
 import (
-	"bytes"
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
-	"os"
+	"log"
 	"time"
+
+	"backend-go/models"
+	"backend-go/repository"
 )
 
 // RecommendationService define a interface para operações de recomendações
 type RecommendationService interface {
-	GetRecommendations(userID uint, topN int, method string) ([]uint, error)
-	NotifyNewInteraction(userID, contentID uint, interactionType string, rating *float64) error
+	GetRecommendations(userID uint, topN int, method string, filter RecommendationFilter, reqCtx RecommendationContext) (*RecommendationResult, error)
+	DeliverInteractions(interactions []InteractionRequest) error
 }
 
 type recommendationService struct {
-	recommenderURL string
-	httpClient     *http.Client
+	client         RecommenderClient
+	contentRepo    repository.ContentRepository
+	collectionRepo repository.CollectionRepository
+	itemCF         RecommendationService
+	fallback       FallbackRanker
+	history        RecommendationHistoryService
+	cache          RecommendationCache
 }
 
 // NewRecommendationService cria uma nova instância do RecommendationService
-func NewRecommendationService() RecommendationService {
-	recommenderURL := os.Getenv("RECOMMENDER_URL")
-	if recommenderURL == "" {
-		recommenderURL = "http://recommender:8000"
-	}
-
+func NewRecommendationService(
+	client RecommenderClient,
+	contentRepo repository.ContentRepository,
+	collectionRepo repository.CollectionRepository,
+	itemCF RecommendationService,
+	fallback FallbackRanker,
+	history RecommendationHistoryService,
+	cache RecommendationCache,
+) RecommendationService {
 	return &recommendationService{
-		recommenderURL: recommenderURL,
-		httpClient: &http.Client{
-			Timeout: 10 * time.Second,
-		},
+		client:         client,
+		contentRepo:    contentRepo,
+		collectionRepo: collectionRepo,
+		itemCF:         itemCF,
+		fallback:       fallback,
+		history:        history,
+		cache:          cache,
 	}
 }
 
@@ -45,9 +56,10 @@ type RecommendationRequest struct {
 
 // RecommendationResponse é a resposta do motor Python
 type RecommendationResponse struct {
-	UserID         int                      `json:"user_id"`
+	UserID          int                     `json:"user_id"`
 	Recommendations []ContentRecommendation `json:"recommendations"`
-	Method         string                  `json:"method"`
+	Method          string                  `json:"method"`
+	ModelVersion    string                  `json:"model_version"`
 }
 
 // ContentRecommendation representa uma recomendação individual
@@ -57,8 +69,68 @@ type ContentRecommendation struct {
 	Title     string  `json:"title"`
 }
 
-// GetRecommendations busca recomendações do motor Python
-func (s *recommendationService) GetRecommendations(userID uint, topN int, method string) ([]uint, error) {
+// RecommendationResult é a lista recomendada e o método que de fato a gerou
+type RecommendationResult struct {
+	// ID da lista no histórico de recomendações; zero se não foi gravada
+	ID         uint
+	ContentIDs []uint
+	// Pontuação de cada conteúdo; os que vieram das coleções editoriais não têm
+	Scores map[uint]float64
+	// Method difere do pedido quando o motor falha e o fallback responde
+	Method       string
+	ModelVersion string
+	// Situação em relação ao cache: hit, stale, miss ou bypass
+	Cache string
+}
+
+// Cada método recupera no máximo maxRecommendationCandidates conteúdos (o
+// limite de top_n do motor Python); com filtros de negócio, a lista é
+// recuperada com filterOverfetchFactor vezes mais candidatos que o pedido.
+const (
+	maxRecommendationCandidates = 50
+	filterOverfetchFactor       = 3
+)
+
+// RecommendationFilter são as regras de negócio aplicadas à lista depois da
+// recuperação. Campos vazios não restringem.
+type RecommendationFilter struct {
+	Types       []string
+	CategoryIDs []uint
+	// Exclui conteúdos que o usuário já visualizou
+	ExcludeViewed bool
+	// Exclui conteúdos com dislike ativo do usuário
+	ExcludeDisliked bool
+	ExcludeIDs      []uint
+	// Só conteúdos já lançados
+	AvailableOnly bool
+	// Exclui os conteúdos com qualquer interação do usuário, como faz o
+	// ranking de fallback; não vem da requisição
+	excludeConsumed bool
+}
+
+// Validate verifica os tipos de conteúdo pedidos
+func (f RecommendationFilter) Validate() error {
+	for _, contentType := range f.Types {
+		if !validContentTypes[contentType] {
+			return errors.New("tipo inválido em types: " + contentType + ". Tipos válidos: article, video, podcast, book, course")
+		}
+	}
+	return nil
+}
+
+func (f RecommendationFilter) active() bool {
+	return len(f.Types) > 0 || len(f.CategoryIDs) > 0 || f.ExcludeViewed ||
+		f.ExcludeDisliked || len(f.ExcludeIDs) > 0 || f.AvailableOnly || f.excludeConsumed
+}
+
+// modelVersion identifica um modelo local pelo instante em que foi calculado
+func modelVersion(name string, builtAt time.Time) string {
+	return name + "+" + builtAt.UTC().Format("20060102150405")
+}
+
+// GetRecommendations busca as recomendações do usuário e grava a lista no
+// histórico, que enfileira o evento recommendation.served com os conteúdos servidos
+func (s *recommendationService) GetRecommendations(userID uint, topN int, method string, filter RecommendationFilter, reqCtx RecommendationContext) (*RecommendationResult, error) {
 	if topN <= 0 || topN > 50 {
 		topN = 10
 	}
@@ -66,92 +138,237 @@ func (s *recommendationService) GetRecommendations(userID uint, topN int, method
 		method = "similarity"
 	}
 
-	reqBody := RecommendationRequest{
-		UserID: int(userID),
-		TopN:   topN,
-		Method: method,
+	result, err := s.recommend(userID, topN, method, filter)
+	if err != nil {
+		return nil, err
 	}
 
-	jsonData, err := json.Marshal(reqBody)
+	// Falhar ao gravar o histórico não impede a resposta
+	recommendation, err := s.history.Record(userID, method, result, reqCtx)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao serializar requisição: %w", err)
+		log.Printf("erro ao gravar recomendação do usuário %d no histórico: %v", userID, err)
+	} else {
+		result.ID = recommendation.ID
 	}
+	return result, nil
+}
 
-	url := fmt.Sprintf("%s/recommendations/", s.recommenderURL)
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return nil, fmt.Errorf("erro ao criar requisição: %w", err)
+// recommend busca a lista do método no cache (ou a calcula) e aplica os
+// filtros que dependem do estado atual: conteúdos ocultos e os recusados
+// pelo filtro saem da lista e as coleções editoriais a completam quando há
+// menos de topN conteúdos. Com filtros, a lista é recuperada com mais
+// candidatos e, se ainda faltarem conteúdos, uma vez mais com o máximo.
+func (s *recommendationService) recommend(userID uint, topN int, method string, filter RecommendationFilter) (*RecommendationResult, error) {
+	fetchN := topN
+	if filter.active() {
+		fetchN = min(topN*filterOverfetchFactor, maxRecommendationCandidates)
+	}
+
+	for {
+		result, status, err := s.cache.Get(userID, fetchN, method, func() (*RecommendationResult, error) {
+			return s.rank(userID, fetchN, method)
+		})
+		if err != nil {
+			return nil, err
+		}
+		result.Cache = status
+
+		retrieved := len(result.ContentIDs)
+		contentIDs, err := s.withFilters(userID, result.ContentIDs, filter)
+		if err != nil {
+			return nil, err
+		}
+		// Tenta de novo só se o método pode devolver mais candidatos
+		if len(contentIDs) < topN && retrieved >= fetchN && fetchN < maxRecommendationCandidates {
+			fetchN = maxRecommendationCandidates
+			continue
+		}
+		if len(contentIDs) > topN {
+			contentIDs = contentIDs[:topN]
+		}
+
+		// O fallback não repete conteúdos já consumidos; o preenchimento
+		// editorial segue a mesma regra, com ou sem filtro do usuário
+		padding := filter
+		if result.Method == MethodPopularityFallback {
+			padding.excludeConsumed = true
+		}
+		result.ContentIDs, err = s.withEditorialCandidates(userID, contentIDs, topN, padding)
+		if err != nil {
+			return nil, err
+		}
+		return result, nil
 	}
+}
 
-	req.Header.Set("Content-Type", "application/json")
+// rank busca recomendações do motor Python. Os métodos "editorial" e
+// "item_cf" são resolvidos localmente. Se o motor falha (erro ou timeout),
+// responde o ranking de popularidade e recência.
+func (s *recommendationService) rank(userID uint, topN int, method string) (*RecommendationResult, error) {
+	switch method {
+	case "editorial":
+		return &RecommendationResult{Method: method, ModelVersion: method}, nil
+	case MethodItemCF:
+		return s.itemCF.GetRecommendations(userID, topN, method, RecommendationFilter{}, RecommendationContext{})
+	}
 
-	resp, err := s.httpClient.Do(req)
+	result, err := s.client.Recommend(userID, topN, method)
 	if err != nil {
-		return nil, fmt.Errorf("erro ao chamar motor de recomendação: %w", err)
+		log.Printf("motor de recomendação indisponível para o usuário %d, usando %s: %v", userID, MethodPopularityFallback, err)
+		return s.fallback.Rank(userID, topN)
 	}
-	defer resp.Body.Close()
+	return result, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("erro do motor de recomendação (status %d): %s", resp.StatusCode, string(body))
+// withEditorialCandidates completa a lista até topN com conteúdos das
+// coleções editoriais que passam pelo filtro
+func (s *recommendationService) withEditorialCandidates(userID uint, contentIDs []uint, topN int, filter RecommendationFilter) ([]uint, error) {
+	if len(contentIDs) >= topN {
+		return contentIDs, nil
 	}
 
-	var recommendationResp RecommendationResponse
-	if err := json.NewDecoder(resp.Body).Decode(&recommendationResp); err != nil {
-		return nil, fmt.Errorf("erro ao decodificar resposta: %w", err)
+	limit := topN * 2
+	if filter.active() {
+		limit *= filterOverfetchFactor
+	}
+	candidates, err := s.collectionRepo.GetEditorialContentIDs(limit)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar coleções editoriais: %w", err)
+	}
+	if filter.active() {
+		if candidates, err = s.withFilters(userID, candidates, filter); err != nil {
+			return nil, err
+		}
 	}
 
-	// Extrair apenas os IDs dos conteúdos recomendados
-	contentIDs := make([]uint, len(recommendationResp.Recommendations))
-	for i, rec := range recommendationResp.Recommendations {
-		contentIDs[i] = uint(rec.ContentID)
+	seen := make(map[uint]bool, len(contentIDs)+len(candidates))
+	for _, id := range contentIDs {
+		seen[id] = true
+	}
+	for _, id := range candidates {
+		if len(contentIDs) >= topN {
+			break
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		contentIDs = append(contentIDs, id)
 	}
 
+	if contentIDs == nil {
+		contentIDs = []uint{}
+	}
 	return contentIDs, nil
 }
 
-// InteractionRequest é o payload para notificar nova interação
-type InteractionRequest struct {
-	UserID          int      `json:"user_id"`
-	ContentID       int      `json:"content_id"`
-	InteractionType string   `json:"interaction_type"`
-	Rating          *float64 `json:"rating,omitempty"`
-}
-
-// NotifyNewInteraction notifica o motor Python sobre uma nova interação
-func (s *recommendationService) NotifyNewInteraction(userID, contentID uint, interactionType string, rating *float64) error {
-	reqBody := InteractionRequest{
-		UserID:          int(userID),
-		ContentID:       int(contentID),
-		InteractionType: interactionType,
-		Rating:          rating,
+// withFilters remove da lista os conteúdos ocultos e os recusados pelo
+// filtro, mantendo a ordem
+func (s *recommendationService) withFilters(userID uint, contentIDs []uint, filter RecommendationFilter) ([]uint, error) {
+	if !filter.active() {
+		return s.withoutHidden(contentIDs)
 	}
 
-	jsonData, err := json.Marshal(reqBody)
-	if err != nil {
-		return fmt.Errorf("erro ao serializar requisição: %w", err)
+	excluded := make(map[uint]bool, len(filter.ExcludeIDs))
+	for _, id := range filter.ExcludeIDs {
+		excluded[id] = true
+	}
+	candidates := make([]uint, 0, len(contentIDs))
+	for _, id := range contentIDs {
+		if !excluded[id] {
+			candidates = append(candidates, id)
+		}
 	}
 
-	url := fmt.Sprintf("%s/recommendations/interactions", s.recommenderURL)
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonData))
+	contentFilter := repository.ContentFilter{
+		Types:           filter.Types,
+		CategoryIDs:     filter.CategoryIDs,
+		UserID:          userID,
+		ExcludeViewed:   filter.ExcludeViewed,
+		ExcludeDisliked: filter.ExcludeDisliked,
+		ExcludeConsumed: filter.excludeConsumed,
+	}
+	if filter.AvailableOnly {
+		now := time.Now()
+		contentFilter.ReleasedBy = &now
+	}
+	allowedIDs, err := s.contentRepo.FilterIDs(candidates, contentFilter)
 	if err != nil {
-		return fmt.Errorf("erro ao criar requisição: %w", err)
+		return nil, fmt.Errorf("erro ao aplicar filtros de recomendação: %w", err)
 	}
 
-	req.Header.Set("Content-Type", "application/json")
+	allowed := make(map[uint]bool, len(allowedIDs))
+	for _, id := range allowedIDs {
+		allowed[id] = true
+	}
+	filtered := make([]uint, 0, len(candidates))
+	for _, id := range candidates {
+		if allowed[id] {
+			filtered = append(filtered, id)
+		}
+	}
+	return filtered, nil
+}
 
-	resp, err := s.httpClient.Do(req)
+// withoutHidden remove da lista os conteúdos ocultos pela moderação
+func (s *recommendationService) withoutHidden(contentIDs []uint) ([]uint, error) {
+	hiddenIDs, err := s.contentRepo.GetHiddenIDs(contentIDs)
 	if err != nil {
-		// Não falhar se o motor não estiver disponível - apenas log
-		return fmt.Errorf("erro ao notificar motor (não crítico): %w", err)
+		return nil, fmt.Errorf("erro ao verificar conteúdos ocultos: %w", err)
+	}
+	if len(hiddenIDs) == 0 {
+		return contentIDs, nil
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("erro do motor (não crítico) (status %d): %s", resp.StatusCode, string(body))
+	hidden := make(map[uint]bool, len(hiddenIDs))
+	for _, id := range hiddenIDs {
+		hidden[id] = true
 	}
 
-	return nil
+	visible := make([]uint, 0, len(contentIDs))
+	for _, id := range contentIDs {
+		if !hidden[id] {
+			visible = append(visible, id)
+		}
+	}
+
+	return visible, nil
+}
+
+// InteractionRequest é o payload para notificar nova interação.
+// O contexto (surface, position etc.) permite ao motor corrigir o viés de exposição.
+type InteractionRequest struct {
+	UserID          int      `json:"user_id"`
+	ContentID       int      `json:"content_id"`
+	InteractionType string   `json:"interaction_type"`
+	Rating          *float64 `json:"rating,omitempty"`
+	InteractionMetadata
+}
+
+// InteractionBatchRequest é o payload para notificar um lote de interações
+type InteractionBatchRequest struct {
+	Interactions []InteractionRequest `json:"interactions"`
+}
+
+// DeliverInteractions envia ao motor Python uma única notificação para um lote
+// de interações já persistidas. É chamado pelo worker do outbox, que repete a
+// entrega em caso de erro; o motor apenas agenda a recarga do modelo, então
+// entregas repetidas não têm efeito colateral.
+func (s *recommendationService) DeliverInteractions(interactions []InteractionRequest) error {
+	if len(interactions) == 0 {
+		return nil
+	}
+	return s.client.DeliverInteractions(interactions)
 }
 
+// interactionMetadata extrai o contexto gravado numa interação
+func interactionMetadata(interaction *models.UserInteraction) InteractionMetadata {
+	return InteractionMetadata{
+		SessionID:  interaction.SessionID,
+		Device:     interaction.Device,
+		AppVersion: interaction.AppVersion,
+		Surface:    interaction.Surface,
+		Position:   interaction.Position,
+		DwellMs:    interaction.DwellMs,
+	}
+}
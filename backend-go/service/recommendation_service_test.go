@@ -0,0 +1,305 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"backend-go/models"
+	"backend-go/repository"
+)
+
+// fakeRecommenderClient devolve os primeiros topN conteúdos de ranked e
+// registra o topN de cada chamada
+type fakeRecommenderClient struct {
+	RecommenderClient
+	ranked    []uint
+	down      bool
+	requested []int
+}
+
+func (c *fakeRecommenderClient) Recommend(userID uint, topN int, method string) (*RecommendationResult, error) {
+	c.requested = append(c.requested, topN)
+	if c.down {
+		return nil, errors.New("motor indisponível")
+	}
+	n := min(topN, len(c.ranked))
+	return &RecommendationResult{ContentIDs: append([]uint(nil), c.ranked[:n]...), Method: method}, nil
+}
+
+// fakeFallbackRanker responde o ranking de fallback fixo
+type fakeFallbackRanker struct {
+	ranked []uint
+}
+
+func (r *fakeFallbackRanker) Rank(userID uint, topN int) (*RecommendationResult, error) {
+	n := min(topN, len(r.ranked))
+	return &RecommendationResult{ContentIDs: append([]uint(nil), r.ranked[:n]...), Method: MethodPopularityFallback}, nil
+}
+
+// fakeCatalog aplica os filtros de conteúdo sobre o estado em memória do
+// único usuário dos testes
+type fakeCatalog struct {
+	repository.ContentRepository
+	types    map[uint]string
+	hidden   map[uint]bool
+	viewed   map[uint]bool
+	disliked map[uint]bool
+	// Conteúdos com qualquer interação do usuário
+	consumed map[uint]bool
+}
+
+func (r *fakeCatalog) GetHiddenIDs(ids []uint) ([]uint, error) {
+	var hidden []uint
+	for _, id := range ids {
+		if r.hidden[id] {
+			hidden = append(hidden, id)
+		}
+	}
+	return hidden, nil
+}
+
+func (r *fakeCatalog) FilterIDs(ids []uint, filter repository.ContentFilter) ([]uint, error) {
+	allowed := []uint{}
+	for _, id := range ids {
+		switch {
+		case r.hidden[id],
+			len(filter.Types) > 0 && !containsString(filter.Types, r.types[id]),
+			filter.ExcludeViewed && r.viewed[id],
+			filter.ExcludeDisliked && r.disliked[id],
+			filter.ExcludeConsumed && r.consumed[id]:
+			continue
+		}
+		allowed = append(allowed, id)
+	}
+	return allowed, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// fakeCollectionRepository devolve os conteúdos editoriais em ordem
+type fakeCollectionRepository struct {
+	repository.CollectionRepository
+	editorial []uint
+}
+
+func (r *fakeCollectionRepository) GetEditorialContentIDs(limit int) ([]uint, error) {
+	n := min(limit, len(r.editorial))
+	return append([]uint(nil), r.editorial[:n]...), nil
+}
+
+type fakeRecommendationHistory struct {
+	RecommendationHistoryService
+}
+
+func (h *fakeRecommendationHistory) Record(userID uint, requestedMethod string, result *RecommendationResult, reqCtx RecommendationContext) (*models.Recommendation, error) {
+	return &models.Recommendation{ID: 1}, nil
+}
+
+// newTestRecommendationService monta o serviço sem cache (bypass)
+func newTestRecommendationService(client RecommenderClient, catalog *fakeCatalog, editorial []uint, fallback []uint) RecommendationService {
+	return NewRecommendationService(
+		client,
+		catalog,
+		&fakeCollectionRepository{editorial: editorial},
+		nil,
+		&fakeFallbackRanker{ranked: fallback},
+		&fakeRecommendationHistory{},
+		NewRecommendationCache(nil, RecommendationCachePolicy{}),
+	)
+}
+
+func idSet(ids ...uint) map[uint]bool {
+	set := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+func TestRecommendationEditorialPadding(t *testing.T) {
+	tests := []struct {
+		name       string
+		engineDown bool
+		ranked     []uint
+		fallback   []uint
+		editorial  []uint
+		consumed   map[uint]bool
+		disliked   map[uint]bool
+		filter     RecommendationFilter
+		topN       int
+		want       []uint
+		wantMethod string
+	}{
+		{
+			name:       "fallback completa sem conteúdos consumidos",
+			engineDown: true,
+			fallback:   []uint{1, 2},
+			editorial:  []uint{3, 4, 5, 6},
+			consumed:   idSet(3, 5),
+			topN:       4,
+			want:       []uint{1, 2, 4, 6},
+			wantMethod: MethodPopularityFallback,
+		},
+		{
+			name:       "fallback com filtro do usuário também exclui consumidos",
+			engineDown: true,
+			fallback:   []uint{1},
+			editorial:  []uint{3, 4, 5},
+			consumed:   idSet(3),
+			disliked:   idSet(4),
+			filter:     RecommendationFilter{ExcludeDisliked: true},
+			topN:       3,
+			want:       []uint{1, 5},
+			wantMethod: MethodPopularityFallback,
+		},
+		{
+			name:       "motor disponível completa com editoriais já consumidos",
+			ranked:     []uint{1, 2},
+			editorial:  []uint{3, 4},
+			consumed:   idSet(3),
+			topN:       4,
+			want:       []uint{1, 2, 3, 4},
+			wantMethod: "similarity",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeRecommenderClient{ranked: tt.ranked, down: tt.engineDown}
+			catalog := &fakeCatalog{consumed: tt.consumed, disliked: tt.disliked}
+			svc := newTestRecommendationService(client, catalog, tt.editorial, tt.fallback)
+
+			result, err := svc.GetRecommendations(1, tt.topN, "similarity", tt.filter, RecommendationContext{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(result.ContentIDs) != fmt.Sprint(tt.want) {
+				t.Errorf("conteúdos = %v, esperado %v", result.ContentIDs, tt.want)
+			}
+			if result.Method != tt.wantMethod {
+				t.Errorf("método = %s, esperado %s", result.Method, tt.wantMethod)
+			}
+		})
+	}
+}
+
+// seq retorna os IDs de from até from+n-1
+func seq(from uint, n int) []uint {
+	ids := make([]uint, n)
+	for i := range ids {
+		ids[i] = from + uint(i)
+	}
+	return ids
+}
+
+func TestRecommendationFilterOverfetch(t *testing.T) {
+	// Conteúdos ímpares são vídeos e pares são artigos
+	types := make(map[uint]string)
+	var videos []uint
+	for id := uint(1); id <= 200; id++ {
+		types[id] = "article"
+		if id%2 == 1 {
+			types[id] = "video"
+			videos = append(videos, id)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		ranked    []uint
+		editorial []uint
+		viewed    map[uint]bool
+		filter    RecommendationFilter
+		topN      int
+		// topN pedido ao motor em cada recuperação
+		wantRequested []int
+		want          []uint
+	}{
+		{
+			name:          "sem filtro pede só topN",
+			ranked:        seq(1, 100),
+			topN:          3,
+			wantRequested: []int{3},
+			want:          []uint{1, 2, 3},
+		},
+		{
+			name:          "filtro pede mais candidatos",
+			ranked:        seq(1, 100),
+			filter:        RecommendationFilter{Types: []string{"video"}},
+			topN:          3,
+			wantRequested: []int{9},
+			want:          []uint{1, 3, 5},
+		},
+		{
+			name:          "sobre-recuperação respeita o máximo do motor",
+			ranked:        seq(1, 100),
+			filter:        RecommendationFilter{Types: []string{"video"}},
+			topN:          20,
+			wantRequested: []int{50},
+			want:          videos[:20],
+		},
+		{
+			name:          "filtro restritivo recupera de novo com o máximo",
+			ranked:        seq(1, 100),
+			viewed:        idSet(seq(1, 12)...),
+			filter:        RecommendationFilter{Types: []string{"video"}, ExcludeViewed: true},
+			topN:          3,
+			wantRequested: []int{9, 50},
+			want:          []uint{13, 15, 17},
+		},
+		{
+			name:          "motor sem mais candidatos não é consultado de novo",
+			ranked:        []uint{1, 2, 3, 4},
+			editorial:     []uint{8, 9, 10, 11},
+			filter:        RecommendationFilter{Types: []string{"video"}},
+			topN:          3,
+			wantRequested: []int{9},
+			want:          []uint{1, 3, 9},
+		},
+		{
+			name:          "editoriais completam a lista passando pelo filtro",
+			ranked:        []uint{2},
+			editorial:     []uint{4, 5, 6, 7, 2},
+			viewed:        idSet(5),
+			filter:        RecommendationFilter{Types: []string{"video"}, ExcludeViewed: true},
+			topN:          3,
+			wantRequested: []int{9},
+			want:          []uint{7},
+		},
+		{
+			name:          "editoriais sem filtro só deixam de fora repetidos",
+			ranked:        []uint{1, 2},
+			editorial:     []uint{2, 4, 5},
+			topN:          4,
+			wantRequested: []int{4},
+			want:          []uint{1, 2, 4, 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeRecommenderClient{ranked: tt.ranked}
+			catalog := &fakeCatalog{types: types, viewed: tt.viewed}
+			svc := newTestRecommendationService(client, catalog, tt.editorial, nil)
+
+			result, err := svc.GetRecommendations(1, tt.topN, "similarity", tt.filter, RecommendationContext{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(client.requested) != fmt.Sprint(tt.wantRequested) {
+				t.Errorf("topN pedido ao motor = %v, esperado %v", client.requested, tt.wantRequested)
+			}
+			if fmt.Sprint(result.ContentIDs) != fmt.Sprint(tt.want) {
+				t.Errorf("conteúdos = %v, esperado %v", result.ContentIDs, tt.want)
+			}
+		})
+	}
+}
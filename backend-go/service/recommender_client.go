@@ -0,0 +1,402 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrRecommenderUnavailable indica que o circuit breaker recusou a chamada
+var ErrRecommenderUnavailable = errors.New("motor de recomendação indisponível (circuito aberto)")
+
+// Operação usada nas métricas e no timeout da entrega de interações
+const recommenderOpInteractions = "interactions"
+
+// Amostras de latência guardadas por operação para os percentis
+const latencySamples = 512
+
+// RecommenderClientPolicy define timeouts, retentativas, hedge e circuit
+// breaker das chamadas ao motor Python
+type RecommenderClientPolicy struct {
+	BaseURL string
+	// Tempo total que uma chamada pode levar, retentativas incluídas, por
+	// método de recomendação; DefaultTimeout vale para os demais
+	Timeouts        map[string]time.Duration
+	DefaultTimeout  time.Duration
+	DeliveryTimeout time.Duration
+	// Retentativas das chamadas idempotentes (recomendações), com backoff
+	// exponencial e jitter
+	MaxRetries       int
+	RetryBaseBackoff time.Duration
+	RetryMaxBackoff  time.Duration
+	// Sem resposta após HedgeDelay, uma segunda requisição idêntica é
+	// disparada e vale a primeira que responder; 0 desativa
+	HedgeDelay time.Duration
+	// Circuit breaker
+	BreakerFailureThreshold int
+	BreakerOpenTimeout      time.Duration
+	BreakerHalfOpenProbes   int
+}
+
+// ParseMethodTimeouts lê timeouts no formato "similarity=3s,popularity=1s"
+func ParseMethodTimeouts(raw string) (map[string]time.Duration, error) {
+	timeouts := make(map[string]time.Duration)
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("timeout inválido: %q, use método=duração", item)
+		}
+		method := strings.TrimSpace(parts[0])
+		timeout, err := time.ParseDuration(strings.TrimSpace(parts[1]))
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("timeout inválido para %s: %q", method, parts[1])
+		}
+		timeouts[method] = timeout
+	}
+	return timeouts, nil
+}
+
+// LatencyStats resume a latência das chamadas de uma operação. Os percentis
+// usam as últimas 512 chamadas.
+type LatencyStats struct {
+	Calls    int64   `json:"calls"`
+	Failures int64   `json:"failures"`
+	AvgMs    float64 `json:"avg_ms"`
+	P50Ms    float64 `json:"p50_ms"`
+	P95Ms    float64 `json:"p95_ms"`
+	P99Ms    float64 `json:"p99_ms"`
+	MaxMs    float64 `json:"max_ms"`
+}
+
+// RecommenderClientStats são as métricas do cliente do motor Python
+type RecommenderClientStats struct {
+	Breaker    BreakerStats            `json:"breaker"`
+	Retries    int64                   `json:"retries"`
+	Hedges     int64                   `json:"hedges"`
+	Rejected   int64                   `json:"rejected"`
+	Operations map[string]LatencyStats `json:"operations"`
+}
+
+// RecommenderClient chama o motor Python de recomendação
+type RecommenderClient interface {
+	Recommend(userID uint, topN int, method string) (*RecommendationResult, error)
+	DeliverInteractions(interactions []InteractionRequest) error
+	Stats() RecommenderClientStats
+}
+
+// latencyWindow acumula a latência de uma operação
+type latencyWindow struct {
+	calls    int64
+	failures int64
+	total    time.Duration
+	max      time.Duration
+	samples  []time.Duration
+	next     int
+}
+
+func (w *latencyWindow) add(elapsed time.Duration, failed bool) {
+	w.calls++
+	if failed {
+		w.failures++
+	}
+	w.total += elapsed
+	if elapsed > w.max {
+		w.max = elapsed
+	}
+	if len(w.samples) < latencySamples {
+		w.samples = append(w.samples, elapsed)
+		return
+	}
+	w.samples[w.next] = elapsed
+	w.next = (w.next + 1) % latencySamples
+}
+
+func (w *latencyWindow) stats() LatencyStats {
+	stats := LatencyStats{Calls: w.calls, Failures: w.failures, MaxMs: milliseconds(w.max)}
+	if w.calls > 0 {
+		stats.AvgMs = milliseconds(w.total / time.Duration(w.calls))
+	}
+	if len(w.samples) == 0 {
+		return stats
+	}
+	sorted := append([]time.Duration(nil), w.samples...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	percentile := func(p float64) float64 {
+		return milliseconds(sorted[int(p*float64(len(sorted)-1))])
+	}
+	stats.P50Ms = percentile(0.50)
+	stats.P95Ms = percentile(0.95)
+	stats.P99Ms = percentile(0.99)
+	return stats
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// engineStatusError é uma resposta do motor com status diferente de sucesso
+type engineStatusError struct {
+	status int
+	body   string
+}
+
+func (e *engineStatusError) Error() string {
+	return fmt.Sprintf("erro do motor de recomendação (status %d): %s", e.status, e.body)
+}
+
+// engineFailure informa se o erro indica problema no motor (e não na
+// requisição), contando para o circuit breaker e permitindo retentativa
+func engineFailure(err error) bool {
+	var statusErr *engineStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.status >= 500 || statusErr.status == http.StatusTooManyRequests
+	}
+	return err != nil
+}
+
+type recommenderClient struct {
+	policy     RecommenderClientPolicy
+	httpClient *http.Client
+	breaker    *circuitBreaker
+
+	mu         sync.Mutex
+	retries    int64
+	hedges     int64
+	rejected   int64
+	operations map[string]*latencyWindow
+}
+
+// NewRecommenderClient cria o cliente do motor Python. Os timeouts são
+// aplicados por chamada via contexto, então o http.Client não tem timeout
+// próprio.
+func NewRecommenderClient(policy RecommenderClientPolicy) RecommenderClient {
+	if policy.DefaultTimeout <= 0 {
+		policy.DefaultTimeout = 3 * time.Second
+	}
+	if policy.DeliveryTimeout <= 0 {
+		policy.DeliveryTimeout = 10 * time.Second
+	}
+	return &recommenderClient{
+		policy:     policy,
+		httpClient: &http.Client{},
+		breaker:    newCircuitBreaker(policy.BreakerFailureThreshold, policy.BreakerOpenTimeout, policy.BreakerHalfOpenProbes),
+		operations: make(map[string]*latencyWindow),
+	}
+}
+
+// Recommend pede ao motor os conteúdos recomendados. A chamada é
+// idempotente, então pode ser repetida e duplicada (hedge).
+func (c *recommenderClient) Recommend(userID uint, topN int, method string) (*RecommendationResult, error) {
+	timeout, ok := c.policy.Timeouts[method]
+	if !ok {
+		timeout = c.policy.DefaultTimeout
+	}
+
+	body, err := c.call(method, timeout, "/recommendations/", RecommendationRequest{
+		UserID: int(userID),
+		TopN:   topN,
+		Method: method,
+	}, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var recommendationResp RecommendationResponse
+	if err := json.Unmarshal(body, &recommendationResp); err != nil {
+		return nil, fmt.Errorf("erro ao decodificar resposta: %w", err)
+	}
+
+	result := &RecommendationResult{
+		ContentIDs:   make([]uint, len(recommendationResp.Recommendations)),
+		Scores:       make(map[uint]float64, len(recommendationResp.Recommendations)),
+		Method:       method,
+		ModelVersion: recommendationResp.ModelVersion,
+	}
+	for i, rec := range recommendationResp.Recommendations {
+		result.ContentIDs[i] = uint(rec.ContentID)
+		result.Scores[uint(rec.ContentID)] = rec.Score
+	}
+	return result, nil
+}
+
+// DeliverInteractions envia um lote de interações ao motor. Não há
+// retentativa aqui: o outbox já repete a entrega com backoff.
+func (c *recommenderClient) DeliverInteractions(interactions []InteractionRequest) error {
+	_, err := c.call(recommenderOpInteractions, c.policy.DeliveryTimeout, "/recommendations/interactions/batch",
+		InteractionBatchRequest{Interactions: interactions}, false)
+	return err
+}
+
+// call executa a chamada passando pelo circuit breaker e registra a latência
+func (c *recommenderClient) call(op string, timeout time.Duration, path string, payload interface{}, idempotent bool) ([]byte, error) {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar requisição: %w", err)
+	}
+
+	probe, ok := c.breaker.allow()
+	if !ok {
+		c.mu.Lock()
+		c.rejected++
+		c.mu.Unlock()
+		return nil, ErrRecommenderUnavailable
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	var body []byte
+	// A chamada de teste do circuito meio-aberto é uma requisição única
+	if idempotent && !probe {
+		body, err = c.withRetries(ctx, func(ctx context.Context) ([]byte, error) {
+			return c.hedged(ctx, func(ctx context.Context) ([]byte, error) {
+				return c.post(ctx, path, jsonData)
+			})
+		})
+	} else {
+		body, err = c.post(ctx, path, jsonData)
+	}
+
+	c.breaker.record(probe, !engineFailure(err))
+	c.mu.Lock()
+	window, exists := c.operations[op]
+	if !exists {
+		window = &latencyWindow{}
+		c.operations[op] = window
+	}
+	window.add(time.Since(start), err != nil)
+	c.mu.Unlock()
+
+	return body, err
+}
+
+// withRetries repete a tentativa enquanto o erro for do motor e houver
+// tempo, esperando um backoff exponencial com jitter entre as tentativas
+func (c *recommenderClient) withRetries(ctx context.Context, attempt func(context.Context) ([]byte, error)) ([]byte, error) {
+	for attempts := 1; ; attempts++ {
+		body, err := attempt(ctx)
+		if err == nil || !engineFailure(err) || attempts > c.policy.MaxRetries || ctx.Err() != nil {
+			return body, err
+		}
+
+		// Jitter completo: espera aleatória entre zero e o backoff
+		backoff := exponentialBackoff(c.policy.RetryBaseBackoff, c.policy.RetryMaxBackoff, attempts)
+		if backoff > 0 {
+			backoff = time.Duration(rand.Int63n(int64(backoff)) + 1)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-time.After(backoff):
+		}
+
+		c.mu.Lock()
+		c.retries++
+		c.mu.Unlock()
+	}
+}
+
+// hedged dispara uma segunda tentativa se a primeira não responder em
+// HedgeDelay e retorna o primeiro sucesso; a tentativa perdedora é cancelada
+func (c *recommenderClient) hedged(ctx context.Context, attempt func(context.Context) ([]byte, error)) ([]byte, error) {
+	if c.policy.HedgeDelay <= 0 {
+		return attempt(ctx)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type outcome struct {
+		body []byte
+		err  error
+	}
+	results := make(chan outcome, 2)
+	run := func() {
+		body, err := attempt(ctx)
+		results <- outcome{body, err}
+	}
+
+	go run()
+	pending := 1
+	timer := time.NewTimer(c.policy.HedgeDelay)
+	defer timer.Stop()
+
+	var lastErr error
+	for {
+		select {
+		case <-timer.C:
+			c.mu.Lock()
+			c.hedges++
+			c.mu.Unlock()
+			pending++
+			go run()
+		case result := <-results:
+			pending--
+			if result.err == nil {
+				return result.body, nil
+			}
+			lastErr = result.err
+			if pending == 0 {
+				return nil, lastErr
+			}
+		}
+	}
+}
+
+// post envia o payload ao motor e retorna o corpo da resposta de sucesso
+func (c *recommenderClient) post(ctx context.Context, path string, jsonData []byte) ([]byte, error) {
+	url := c.policy.BaseURL + path
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao criar requisição: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao chamar motor de recomendação: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao ler resposta do motor: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return nil, &engineStatusError{status: resp.StatusCode, body: string(body)}
+	}
+	return body, nil
+}
+
+// Stats retorna o estado do circuit breaker, os contadores e a latência por operação
+func (c *recommenderClient) Stats() RecommenderClientStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	operations := make(map[string]LatencyStats, len(c.operations))
+	for op, window := range c.operations {
+		operations[op] = window.stats()
+	}
+	return RecommenderClientStats{
+		Breaker:    c.breaker.stats(),
+		Retries:    c.retries,
+		Hedges:     c.hedges,
+		Rejected:   c.rejected,
+		Operations: operations,
+	}
+}
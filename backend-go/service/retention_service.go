@@ -0,0 +1,575 @@
+package service
+
+import (
+	"backend-go/models"
+	"backend-go/repository"
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Interações lidas por lote durante o arquivamento e a restauração
+const archiveChunkSize = 500
+
+// RetentionPolicy define por quanto tempo as interações ficam em
+// user_interactions e para onde vão depois disso
+type RetentionPolicy struct {
+	// Meses mantidos em user_interactions; zero desativa o job agendado
+	Months int
+	// "table" (archived_interactions) ou "file" (NDJSON compactado em Dir)
+	Storage string
+	Dir     string
+	// Períodos restaurados não são arquivados de novo durante este intervalo
+	RestoreHold time.Duration
+}
+
+// RetentionService define a interface para retenção e arquivamento de interações
+type RetentionService interface {
+	Enabled() bool
+	Run() ([]models.InteractionArchive, error)
+	ArchiveBefore(cutoff time.Time) ([]models.InteractionArchive, error)
+	Restore(archiveID uint) (*models.InteractionArchive, int64, error)
+	ListArchives(page, limit int) ([]models.InteractionArchive, int64, error)
+}
+
+type retentionService struct {
+	repo        repository.ArchiveRepository
+	rollupRepo  repository.RollupRepository
+	userRepo    repository.UserRepository
+	contentRepo repository.ContentRepository
+	policy      RetentionPolicy
+	mu          sync.Mutex
+}
+
+// NewRetentionService cria uma nova instância do RetentionService
+func NewRetentionService(
+	repo repository.ArchiveRepository,
+	rollupRepo repository.RollupRepository,
+	userRepo repository.UserRepository,
+	contentRepo repository.ContentRepository,
+	policy RetentionPolicy,
+) RetentionService {
+	if policy.Storage != models.ArchiveStorageFile {
+		policy.Storage = models.ArchiveStorageTable
+	}
+	if policy.Dir == "" {
+		policy.Dir = "archive"
+	}
+	return &retentionService{
+		repo:        repo,
+		rollupRepo:  rollupRepo,
+		userRepo:    userRepo,
+		contentRepo: contentRepo,
+		policy:      policy,
+	}
+}
+
+// Enabled indica se a política de retenção está configurada
+func (s *retentionService) Enabled() bool {
+	return s.policy.Months > 0
+}
+
+// Run arquiva as interações mais antigas que a janela de retenção
+func (s *retentionService) Run() ([]models.InteractionArchive, error) {
+	if !s.Enabled() {
+		return nil, nil
+	}
+	return s.ArchiveBefore(time.Now().AddDate(0, -s.policy.Months, 0))
+}
+
+// ArchiveBefore arquiva, mês a mês, as interações criadas antes do início do
+// mês de cutoff. Só são arquivadas interações já agregadas nos rollups (ID até
+// o watermark), então os rollups continuam completos após o arquivamento.
+func (s *retentionService) ArchiveBefore(cutoff time.Time) ([]models.InteractionArchive, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff = monthOf(cutoff)
+
+	watermark, err := s.rollupRepo.GetWatermark(interactionRollupName)
+	if err != nil {
+		return nil, err
+	}
+	if watermark.LastInteractionID == 0 {
+		return nil, nil
+	}
+
+	// Arquivamentos interrompidos são concluídos antes de qualquer outro
+	inProgress, err := s.repo.ListInProgress()
+	if err != nil {
+		return nil, err
+	}
+	var archives []models.InteractionArchive
+	for i := range inProgress {
+		archive, err := s.resumeArchive(&inProgress[i], watermark.LastInteractionID)
+		if err != nil {
+			return archives, err
+		}
+		archives = append(archives, *archive)
+	}
+
+	oldest, err := s.repo.OldestInteraction(cutoff, watermark.LastInteractionID)
+	if err != nil || oldest == nil {
+		return archives, err
+	}
+
+	held, err := s.repo.ListRestoredSince(time.Now().Add(-s.policy.RestoreHold))
+	if err != nil {
+		return archives, err
+	}
+
+	for start := monthOf(*oldest); start.Before(cutoff); start = start.AddDate(0, 1, 0) {
+		end := start.AddDate(0, 1, 0)
+		if overlapsArchive(held, start, end) {
+			continue
+		}
+
+		archive, err := s.archiveRange(start, end, watermark.LastInteractionID)
+		if err != nil {
+			return archives, err
+		}
+		if archive != nil {
+			archives = append(archives, *archive)
+		}
+	}
+	return archives, nil
+}
+
+// archiveRange move as interações de [start, end) com ID até maxID para o
+// armazenamento configurado. Retorna nil se o período não tiver interações.
+func (s *retentionService) archiveRange(start, end time.Time, maxID uint) (*models.InteractionArchive, error) {
+	pending, err := s.repo.ListInteractions(start, end, 0, maxID, 1)
+	if err != nil || len(pending) == 0 {
+		return nil, err
+	}
+
+	archive := &models.InteractionArchive{
+		RangeStart: start,
+		RangeEnd:   end,
+		Storage:    s.policy.Storage,
+		Status:     models.ArchiveStatusArchiving,
+	}
+	if s.policy.Storage == models.ArchiveStorageFile {
+		return s.archiveRangeToFile(archive, pending[0].ID, maxID)
+	}
+	if err := s.moveRangeToTable(archive, maxID); err != nil || archive.ID == 0 {
+		return nil, err
+	}
+	return archive, nil
+}
+
+// resumeArchive conclui um arquivamento interrompido, no armazenamento em
+// que ele foi iniciado
+func (s *retentionService) resumeArchive(archive *models.InteractionArchive, maxID uint) (*models.InteractionArchive, error) {
+	if archive.Storage == models.ArchiveStorageFile {
+		return s.finishFileArchive(archive, nil)
+	}
+	if err := s.moveRangeToTable(archive, maxID); err != nil {
+		return nil, err
+	}
+	return archive, nil
+}
+
+// moveRangeToTable move as interações do período do arquivamento para
+// archived_interactions. Cada lote é movido junto com a atualização do
+// registro, que é criado no primeiro lote, e o arquivamento é concluído no fim.
+func (s *retentionService) moveRangeToTable(archive *models.InteractionArchive, maxID uint) error {
+	start, end := archive.RangeStart, archive.RangeEnd
+	var lastID uint
+	for {
+		interactions, err := s.repo.ListInteractions(start, end, lastID, maxID, archiveChunkSize)
+		if err != nil {
+			return err
+		}
+		if len(interactions) == 0 {
+			break
+		}
+
+		rows := make([]models.ArchivedInteraction, len(interactions))
+		for i := range interactions {
+			rows[i] = toArchivedInteraction(&interactions[i], archive.ID)
+		}
+		if err := s.repo.MoveToTable(archive, rows); err != nil {
+			return err
+		}
+		lastID = interactions[len(interactions)-1].ID
+	}
+	if archive.ID == 0 {
+		return nil
+	}
+
+	archive.Status = models.ArchiveStatusArchived
+	return s.repo.Update(archive)
+}
+
+// archiveRangeToFile grava as interações do período em NDJSON compactado,
+// registra o arquivamento e só então as remove do banco. O nome do arquivo
+// depende só do período e do menor ID, então uma execução que caia antes do
+// registro sobrescreve o mesmo arquivo; depois do registro, a remoção é
+// retomada por finishFileArchive.
+func (s *retentionService) archiveRangeToFile(archive *models.InteractionArchive, firstID, maxID uint) (*models.InteractionArchive, error) {
+	if err := os.MkdirAll(s.policy.Dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	name := fmt.Sprintf("interactions-%s-%d.ndjson.gz", archive.RangeStart.Format("2006-01"), firstID)
+	path := filepath.Join(s.policy.Dir, name)
+	tmpPath := path + ".tmp"
+
+	ids, err := s.writeArchiveFile(tmpPath, archive, maxID)
+	if err != nil {
+		_ = os.Remove(tmpPath)
+		return nil, err
+	}
+	if len(ids) == 0 {
+		_ = os.Remove(tmpPath)
+		return nil, nil
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		_ = os.Remove(tmpPath)
+		return nil, err
+	}
+
+	archive.Path = path
+	archive.Rows = int64(len(ids))
+	if err := s.repo.Create(archive); err != nil {
+		return nil, err
+	}
+	return s.finishFileArchive(archive, ids)
+}
+
+// finishFileArchive remove do banco as interações já gravadas no arquivo e
+// conclui o arquivamento. Sem ids, os IDs são lidos do próprio arquivo.
+func (s *retentionService) finishFileArchive(archive *models.InteractionArchive, ids []uint) (*models.InteractionArchive, error) {
+	if ids == nil {
+		var err error
+		if ids, err = readArchiveFileIDs(archive.Path); err != nil {
+			return nil, err
+		}
+	}
+
+	for i := 0; i < len(ids); i += archiveChunkSize {
+		chunkEnd := i + archiveChunkSize
+		if chunkEnd > len(ids) {
+			chunkEnd = len(ids)
+		}
+		if err := s.repo.DeleteInteractions(ids[i:chunkEnd]); err != nil {
+			return nil, err
+		}
+	}
+
+	archive.Status = models.ArchiveStatusArchived
+	if err := s.repo.Update(archive); err != nil {
+		return nil, err
+	}
+	return archive, nil
+}
+
+// readArchiveFileIDs lê os IDs das interações gravadas em um arquivo de arquivamento
+func readArchiveFileIDs(path string) ([]uint, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	var ids []uint
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var row struct {
+			ID uint `json:"id"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+			return nil, fmt.Errorf("arquivo de arquivamento inválido: %w", err)
+		}
+		ids = append(ids, row.ID)
+	}
+	return ids, scanner.Err()
+}
+
+// writeArchiveFile grava as interações do período no arquivo e retorna seus IDs
+func (s *retentionService) writeArchiveFile(path string, archive *models.InteractionArchive, maxID uint) ([]uint, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	gz := gzip.NewWriter(file)
+	encoder := json.NewEncoder(gz)
+
+	var ids []uint
+	var lastID uint
+	for {
+		interactions, err := s.repo.ListInteractions(archive.RangeStart, archive.RangeEnd, lastID, maxID, archiveChunkSize)
+		if err != nil {
+			return nil, err
+		}
+		if len(interactions) == 0 {
+			break
+		}
+
+		for i := range interactions {
+			row := toArchivedInteraction(&interactions[i], 0)
+			if err := encoder.Encode(&row); err != nil {
+				return nil, err
+			}
+			ids = append(ids, interactions[i].ID)
+		}
+
+		lastID = interactions[len(interactions)-1].ID
+		if lastID > archive.MaxID {
+			archive.MaxID = lastID
+		}
+	}
+
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	if err := file.Sync(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// Restore devolve as interações de um arquivamento para user_interactions.
+// Interações de usuários ou conteúdos removidos depois do arquivamento são
+// descartadas. Retorna o arquivamento e quantas interações foram restauradas.
+func (s *retentionService) Restore(archiveID uint) (*models.InteractionArchive, int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	archive, err := s.repo.GetByID(archiveID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if archive.Status == models.ArchiveStatusRestored {
+		return nil, 0, errors.New("arquivamento já foi restaurado")
+	}
+
+	var restored int64
+	if archive.Storage == models.ArchiveStorageFile {
+		restored, err = s.restoreFromFile(archive)
+	} else {
+		restored, err = s.restoreFromTable(archive)
+	}
+	if err != nil {
+		return nil, restored, err
+	}
+
+	now := time.Now()
+	archive.Status = models.ArchiveStatusRestored
+	archive.RestoredAt = &now
+	if err := s.repo.Update(archive); err != nil {
+		return nil, restored, err
+	}
+
+	if archive.Storage == models.ArchiveStorageFile {
+		if err := os.Remove(archive.Path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return archive, restored, err
+		}
+	}
+	return archive, restored, nil
+}
+
+func (s *retentionService) restoreFromTable(archive *models.InteractionArchive) (int64, error) {
+	var restored int64
+	var lastID uint
+	for {
+		rows, err := s.repo.ListArchivedRows(archive.ID, lastID, archiveChunkSize)
+		if err != nil {
+			return restored, err
+		}
+		if len(rows) == 0 {
+			return restored, nil
+		}
+
+		interactions, err := s.restorable(rows)
+		if err != nil {
+			return restored, err
+		}
+		archivedIDs := make([]uint, len(rows))
+		for i := range rows {
+			archivedIDs[i] = rows[i].ID
+		}
+		if err := s.repo.RestoreRows(interactions, archivedIDs); err != nil {
+			return restored, err
+		}
+
+		restored += int64(len(interactions))
+		lastID = rows[len(rows)-1].ID
+	}
+}
+
+func (s *retentionService) restoreFromFile(archive *models.InteractionArchive) (int64, error) {
+	file, err := os.Open(archive.Path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return 0, err
+	}
+	defer gz.Close()
+
+	var restored int64
+	flush := func(rows []models.ArchivedInteraction) error {
+		interactions, err := s.restorable(rows)
+		if err != nil {
+			return err
+		}
+		if err := s.repo.RestoreRows(interactions, nil); err != nil {
+			return err
+		}
+		restored += int64(len(interactions))
+		return nil
+	}
+
+	scanner := bufio.NewScanner(gz)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	rows := make([]models.ArchivedInteraction, 0, archiveChunkSize)
+	for scanner.Scan() {
+		var row models.ArchivedInteraction
+		if err := json.Unmarshal(scanner.Bytes(), &row); err != nil {
+			return restored, fmt.Errorf("arquivo de arquivamento inválido: %w", err)
+		}
+		rows = append(rows, row)
+		if len(rows) == archiveChunkSize {
+			if err := flush(rows); err != nil {
+				return restored, err
+			}
+			rows = rows[:0]
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return restored, err
+	}
+	if len(rows) > 0 {
+		if err := flush(rows); err != nil {
+			return restored, err
+		}
+	}
+	return restored, nil
+}
+
+// restorable converte as linhas arquivadas, descartando as que referenciam
+// usuários ou conteúdos que não existem mais
+func (s *retentionService) restorable(rows []models.ArchivedInteraction) ([]models.UserInteraction, error) {
+	userIDs := make([]uint, 0, len(rows))
+	contentIDs := make([]uint, 0, len(rows))
+	for i := range rows {
+		userIDs = append(userIDs, rows[i].UserID)
+		contentIDs = append(contentIDs, rows[i].ContentID)
+	}
+
+	existingUsers, err := s.userRepo.GetExistingIDs(userIDs)
+	if err != nil {
+		return nil, err
+	}
+	existingContents, err := s.contentRepo.GetExistingIDs(contentIDs)
+	if err != nil {
+		return nil, err
+	}
+	users := make(map[uint]bool, len(existingUsers))
+	for _, id := range existingUsers {
+		users[id] = true
+	}
+	contents := make(map[uint]bool, len(existingContents))
+	for _, id := range existingContents {
+		contents[id] = true
+	}
+
+	interactions := make([]models.UserInteraction, 0, len(rows))
+	for i := range rows {
+		if users[rows[i].UserID] && contents[rows[i].ContentID] {
+			interactions = append(interactions, fromArchivedInteraction(&rows[i]))
+		}
+	}
+	return interactions, nil
+}
+
+// ListArchives lista os arquivamentos com paginação
+func (s *retentionService) ListArchives(page, limit int) ([]models.InteractionArchive, int64, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100 // Limite máximo
+	}
+	return s.repo.List(limit, (page-1)*limit)
+}
+
+// monthOf retorna o início do mês de t no fuso horário da aplicação
+func monthOf(t time.Time) time.Time {
+	t = t.In(time.Local)
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.Local)
+}
+
+func overlapsArchive(archives []models.InteractionArchive, start, end time.Time) bool {
+	for i := range archives {
+		if archives[i].RangeStart.Before(end) && start.Before(archives[i].RangeEnd) {
+			return true
+		}
+	}
+	return false
+}
+
+func toArchivedInteraction(i *models.UserInteraction, archiveID uint) models.ArchivedInteraction {
+	return models.ArchivedInteraction{
+		ID:              i.ID,
+		ArchiveID:       archiveID,
+		UserID:          i.UserID,
+		ContentID:       i.ContentID,
+		InteractionType: i.InteractionType,
+		Rating:          i.Rating,
+		ClientEventID:   i.ClientEventID,
+		SessionID:       i.SessionID,
+		Device:          i.Device,
+		AppVersion:      i.AppVersion,
+		Surface:         i.Surface,
+		Position:        i.Position,
+		DwellMs:         i.DwellMs,
+		Flagged:         i.Flagged,
+		FlagReason:      i.FlagReason,
+		CreatedAt:       i.CreatedAt,
+	}
+}
+
+func fromArchivedInteraction(a *models.ArchivedInteraction) models.UserInteraction {
+	return models.UserInteraction{
+		ID:              a.ID,
+		UserID:          a.UserID,
+		ContentID:       a.ContentID,
+		InteractionType: a.InteractionType,
+		Rating:          a.Rating,
+		ClientEventID:   a.ClientEventID,
+		SessionID:       a.SessionID,
+		Device:          a.Device,
+		AppVersion:      a.AppVersion,
+		Surface:         a.Surface,
+		Position:        a.Position,
+		DwellMs:         a.DwellMs,
+		Flagged:         a.Flagged,
+		FlagReason:      a.FlagReason,
+		CreatedAt:       a.CreatedAt,
+		ReceivedAt:      a.CreatedAt,
+	}
+}
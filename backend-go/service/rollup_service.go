@@ -0,0 +1,452 @@
+package service
+
+import (
+	"backend-go/models"
+	"backend-go/repository"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// Nome do watermark do rollup diário de interações
+	interactionRollupName = "interaction_daily"
+	// Interações lidas por lote durante a agregação
+	rollupChunkSize = 1000
+	// Interações mais recentes que isso ficam para a próxima execução, dando
+	// tempo para transações concorrentes com IDs menores serem confirmadas
+	rollupSafetyLag = 30 * time.Second
+	// IDs ausentes abaixo do watermark são relidos por esse tempo antes de
+	// serem considerados inserções desfeitas
+	rollupGapTimeout = 10 * time.Minute
+	// Buracos maiores que isso na sequência de IDs são saltos da sequência,
+	// não transações em andamento, e não são acompanhados
+	rollupMaxGapSpan = 1000
+	// Período máximo aceito nas consultas de analytics
+	maxRollupRangeDays = 366
+)
+
+// RollupStatus resume o estado do job de agregação
+type RollupStatus struct {
+	LastInteractionID   uint      `json:"last_interaction_id"`
+	LatestInteractionID uint      `json:"latest_interaction_id"`
+	PendingInteractions uint      `json:"pending_interactions"`
+	OpenGaps            int       `json:"open_gaps"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+// RollupService define a interface para os rollups diários de interações
+type RollupService interface {
+	CatchUp() (int, error)
+	Backfill(from, to *time.Time) (int, error)
+	Status() (*RollupStatus, error)
+	GetContentDaily(contentID uint, from, to time.Time) ([]models.ContentDailyStat, error)
+	GetUserDaily(userID uint, from, to time.Time) ([]models.UserDailyStat, error)
+	GetTopContents(from, to time.Time, types []string, limit int) ([]repository.ContentTotals, error)
+}
+
+type rollupService struct {
+	repo        repository.RollupRepository
+	archiveRepo repository.ArchiveRepository
+	contentRepo repository.ContentRepository
+	userRepo    repository.UserRepository
+	mu          sync.Mutex
+}
+
+// NewRollupService cria uma nova instância do RollupService
+func NewRollupService(
+	repo repository.RollupRepository,
+	archiveRepo repository.ArchiveRepository,
+	contentRepo repository.ContentRepository,
+	userRepo repository.UserRepository,
+) RollupService {
+	return &rollupService{
+		repo:        repo,
+		archiveRepo: archiveRepo,
+		contentRepo: contentRepo,
+		userRepo:    userRepo,
+	}
+}
+
+// CatchUp agrega as interações criadas depois do watermark, em lotes.
+// Cada lote é gravado junto com o avanço do watermark, então uma execução
+// interrompida continua de onde parou. IDs ausentes pelos quais o watermark
+// passou (transações ainda não confirmadas) ficam registrados como lacunas e
+// são agregados quando aparecem. Retorna o número de interações agregadas.
+func (s *rollupService) CatchUp() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	watermark, err := s.repo.GetWatermark(interactionRollupName)
+	if err != nil {
+		return 0, err
+	}
+	upTo, err := s.repo.MaxInteractionID(time.Now().Add(-rollupSafetyLag))
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	lastID := watermark.LastInteractionID
+
+	gapInteractions, resolved, err := loadGaps(s.repo, interactionRollupName)
+	if err != nil {
+		return 0, err
+	}
+	if len(resolved) > 0 {
+		agg := newRollupAggregator()
+		agg.add(gapInteractions)
+		contentStats, userStats := agg.stats()
+		if err := s.repo.Apply(interactionRollupName, lastID, lastID, repository.RollupGapChange{Resolved: resolved}, contentStats, userStats); err != nil {
+			return 0, err
+		}
+		processed += len(gapInteractions)
+	}
+
+	for lastID < upTo {
+		interactions, err := s.repo.ListInteractions(lastID, upTo, nil, nil, rollupChunkSize)
+		if err != nil {
+			return processed, err
+		}
+		if len(interactions) == 0 {
+			break
+		}
+
+		agg := newRollupAggregator()
+		agg.add(interactions)
+		nextID := interactions[len(interactions)-1].ID
+
+		contentStats, userStats := agg.stats()
+		gaps := repository.RollupGapChange{Open: idGaps(lastID, interactions)}
+		if err := s.repo.Apply(interactionRollupName, lastID, nextID, gaps, contentStats, userStats); err != nil {
+			return processed, err
+		}
+
+		processed += len(interactions)
+		lastID = nextID
+	}
+
+	return processed, nil
+}
+
+// Backfill recalcula do zero os rollups dos dias no período [from, to), ou de
+// todo o histórico se o período for nulo, a partir das interações já cobertas
+// pelo watermark. Períodos arquivados não podem ser recalculados, pois suas
+// interações não estão mais em user_interactions; sem from, o recálculo começa
+// no fim do último arquivamento. Retorna o número de interações reagregadas.
+func (s *rollupService) Backfill(from, to *time.Time) (int, error) {
+	if from != nil {
+		day := dayOf(*from)
+		from = &day
+	}
+	if to != nil {
+		day := dayOf(*to)
+		to = &day
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	archivedUntil, err := s.archiveRepo.ArchivedUntil()
+	if err != nil {
+		return 0, err
+	}
+	if archivedUntil != nil {
+		if from == nil {
+			from = archivedUntil
+		} else if from.Before(*archivedUntil) {
+			return 0, errors.New("período inclui interações arquivadas; restaure o arquivamento antes do backfill")
+		}
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		return 0, errors.New("período inválido: from deve ser anterior a to")
+	}
+
+	watermark, err := s.repo.GetWatermark(interactionRollupName)
+	if err != nil {
+		return 0, err
+	}
+	gaps, err := s.repo.ListGaps(interactionRollupName)
+	if err != nil {
+		return 0, err
+	}
+	snapshot := repository.RollupSnapshot{Watermark: watermark.LastInteractionID}
+	open := make(map[uint]bool, len(gaps))
+	for _, gap := range gaps {
+		snapshot.Gaps = append(snapshot.Gaps, gap.InteractionID)
+		open[gap.InteractionID] = true
+	}
+
+	// Lacunas que já apareceram entram no recálculo e são fechadas junto
+	agg := newRollupAggregator()
+	processed := 0
+	var resolved []uint
+	var lastID uint
+	for lastID < watermark.LastInteractionID {
+		interactions, err := s.repo.ListInteractions(lastID, watermark.LastInteractionID, from, to, rollupChunkSize)
+		if err != nil {
+			return 0, err
+		}
+		if len(interactions) == 0 {
+			break
+		}
+		agg.add(interactions)
+		for i := range interactions {
+			if open[interactions[i].ID] {
+				resolved = append(resolved, interactions[i].ID)
+			}
+		}
+		processed += len(interactions)
+		lastID = interactions[len(interactions)-1].ID
+	}
+
+	contentStats, userStats := agg.stats()
+	if err := s.repo.ReplaceRange(interactionRollupName, snapshot, resolved, from, to, contentStats, userStats); err != nil {
+		return 0, err
+	}
+	return processed, nil
+}
+
+// loadGaps relê as lacunas de ID de um job. Retorna as interações que
+// apareceram desde então e os IDs das lacunas resolvidas: as que apareceram e
+// as que expiraram sem aparecer (inserções desfeitas).
+func loadGaps(repo repository.RollupRepository, name string) ([]models.UserInteraction, []uint, error) {
+	gaps, err := repo.ListGaps(name)
+	if err != nil || len(gaps) == 0 {
+		return nil, nil, err
+	}
+
+	ids := make([]uint, len(gaps))
+	for i := range gaps {
+		ids[i] = gaps[i].InteractionID
+	}
+	interactions, err := repo.ListInteractionsByID(ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	found := make(map[uint]bool, len(interactions))
+	for i := range interactions {
+		found[interactions[i].ID] = true
+	}
+
+	expired := time.Now().Add(-rollupGapTimeout)
+	var resolved []uint
+	for _, gap := range gaps {
+		if found[gap.InteractionID] || gap.SeenAt.Before(expired) {
+			resolved = append(resolved, gap.InteractionID)
+		}
+	}
+	return interactions, resolved, nil
+}
+
+// idGaps retorna os IDs ausentes entre afterID e as interações lidas (em
+// ordem de ID). Buracos maiores que rollupMaxGapSpan são ignorados.
+func idGaps(afterID uint, interactions []models.UserInteraction) []uint {
+	var gaps []uint
+	previous := afterID
+	for i := range interactions {
+		id := interactions[i].ID
+		if id-previous-1 <= rollupMaxGapSpan {
+			for missing := previous + 1; missing < id; missing++ {
+				gaps = append(gaps, missing)
+			}
+		}
+		previous = id
+	}
+	return gaps
+}
+
+// Status retorna o watermark atual e quantas interações ainda faltam agregar
+func (s *rollupService) Status() (*RollupStatus, error) {
+	watermark, err := s.repo.GetWatermark(interactionRollupName)
+	if err != nil {
+		return nil, err
+	}
+	latest, err := s.repo.MaxInteractionID(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	gaps, err := s.repo.ListGaps(interactionRollupName)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &RollupStatus{
+		LastInteractionID:   watermark.LastInteractionID,
+		LatestInteractionID: latest,
+		OpenGaps:            len(gaps),
+		UpdatedAt:           watermark.UpdatedAt,
+	}
+	if latest > watermark.LastInteractionID {
+		status.PendingInteractions = latest - watermark.LastInteractionID
+	}
+	return status, nil
+}
+
+// GetContentDaily retorna os rollups diários de um conteúdo
+func (s *rollupService) GetContentDaily(contentID uint, from, to time.Time) ([]models.ContentDailyStat, error) {
+	from, to, err := normalizeRollupRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.contentRepo.GetByID(contentID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListContentDaily(contentID, from, to)
+}
+
+// GetUserDaily retorna os rollups diários de um usuário
+func (s *rollupService) GetUserDaily(userID uint, from, to time.Time) ([]models.UserDailyStat, error) {
+	from, to, err := normalizeRollupRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.userRepo.GetByID(userID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListUserDaily(userID, from, to)
+}
+
+// GetTopContents retorna os conteúdos com mais interações no período
+func (s *rollupService) GetTopContents(from, to time.Time, types []string, limit int) ([]repository.ContentTotals, error) {
+	from, to, err := normalizeRollupRange(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if limit < 1 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100 // Limite máximo
+	}
+	for _, interactionType := range types {
+		if !validInteractionTypes[interactionType] && !affectsReaction(interactionType) {
+			return nil, errors.New("tipo de interação inválido: " + interactionType)
+		}
+	}
+
+	return s.repo.TopContents(from, to, types, nil, limit)
+}
+
+// normalizeRollupRange alinha o período a dias inteiros e limita seu tamanho
+func normalizeRollupRange(from, to time.Time) (time.Time, time.Time, error) {
+	from, to = dayOf(from), dayOf(to)
+	if !from.Before(to) {
+		return from, to, errors.New("período inválido: from deve ser anterior a to")
+	}
+	if to.Sub(from) > maxRollupRangeDays*24*time.Hour {
+		return from, to, errors.New("período máximo é de 366 dias")
+	}
+	return from, to, nil
+}
+
+// dayOf retorna o início do dia de t no fuso horário da aplicação
+func dayOf(t time.Time) time.Time {
+	t = t.In(time.Local)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+type contentDayKey struct {
+	contentID       uint
+	day             time.Time
+	interactionType string
+}
+
+type userDayKey struct {
+	userID          uint
+	day             time.Time
+	interactionType string
+}
+
+// rollupAggregator acumula interações em memória por dia e tipo
+type rollupAggregator struct {
+	contents map[contentDayKey]*models.ContentDailyStat
+	users    map[userDayKey]*models.UserDailyStat
+}
+
+func newRollupAggregator() *rollupAggregator {
+	return &rollupAggregator{
+		contents: make(map[contentDayKey]*models.ContentDailyStat),
+		users:    make(map[userDayKey]*models.UserDailyStat),
+	}
+}
+
+func (a *rollupAggregator) add(interactions []models.UserInteraction) {
+	now := time.Now()
+	for i := range interactions {
+		interaction := &interactions[i]
+		// Interações sinalizadas como abuso não entram nas estatísticas
+		if interaction.Flagged {
+			continue
+		}
+		day := dayOf(interaction.CreatedAt)
+
+		ck := contentDayKey{interaction.ContentID, day, interaction.InteractionType}
+		contentStat, ok := a.contents[ck]
+		if !ok {
+			contentStat = &models.ContentDailyStat{
+				ContentID:       interaction.ContentID,
+				Day:             day,
+				InteractionType: interaction.InteractionType,
+				UpdatedAt:       now,
+			}
+			a.contents[ck] = contentStat
+		}
+		contentStat.Interactions++
+		if interaction.Rating != nil {
+			contentStat.RatingSum += *interaction.Rating
+			contentStat.RatingCount++
+		}
+
+		uk := userDayKey{interaction.UserID, day, interaction.InteractionType}
+		userStat, ok := a.users[uk]
+		if !ok {
+			userStat = &models.UserDailyStat{
+				UserID:          interaction.UserID,
+				Day:             day,
+				InteractionType: interaction.InteractionType,
+				UpdatedAt:       now,
+			}
+			a.users[uk] = userStat
+		}
+		userStat.Interactions++
+	}
+}
+
+// stats retorna os rollups acumulados em ordem determinística, evitando
+// deadlocks entre upserts concorrentes
+func (a *rollupAggregator) stats() ([]models.ContentDailyStat, []models.UserDailyStat) {
+	contentStats := make([]models.ContentDailyStat, 0, len(a.contents))
+	for _, stat := range a.contents {
+		contentStats = append(contentStats, *stat)
+	}
+	sort.Slice(contentStats, func(i, j int) bool {
+		a, b := contentStats[i], contentStats[j]
+		if a.ContentID != b.ContentID {
+			return a.ContentID < b.ContentID
+		}
+		if !a.Day.Equal(b.Day) {
+			return a.Day.Before(b.Day)
+		}
+		return a.InteractionType < b.InteractionType
+	})
+
+	userStats := make([]models.UserDailyStat, 0, len(a.users))
+	for _, stat := range a.users {
+		userStats = append(userStats, *stat)
+	}
+	sort.Slice(userStats, func(i, j int) bool {
+		a, b := userStats[i], userStats[j]
+		if a.UserID != b.UserID {
+			return a.UserID < b.UserID
+		}
+		if !a.Day.Equal(b.Day) {
+			return a.Day.Before(b.Day)
+		}
+		return a.InteractionType < b.InteractionType
+	})
+
+	return contentStats, userStats
+}
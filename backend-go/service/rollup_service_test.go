@@ -0,0 +1,275 @@
+package service
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"backend-go/models"
+	"backend-go/repository"
+)
+
+// fakeRollupRepository simula user_interactions com IDs alocados antes do
+// commit: só as interações confirmadas são visíveis para o job
+type fakeRollupRepository struct {
+	repository.RollupRepository
+	committed map[uint]models.UserInteraction
+	watermark uint
+	gaps      map[uint]time.Time
+	// Total de interações agregadas por conteúdo
+	totals map[uint]int64
+}
+
+func newFakeRollupRepository() *fakeRollupRepository {
+	return &fakeRollupRepository{
+		committed: make(map[uint]models.UserInteraction),
+		gaps:      make(map[uint]time.Time),
+		totals:    make(map[uint]int64),
+	}
+}
+
+func (r *fakeRollupRepository) commit(ids ...uint) {
+	for _, id := range ids {
+		r.committed[id] = models.UserInteraction{
+			ID:              id,
+			UserID:          1,
+			ContentID:       id % 3,
+			InteractionType: "view",
+			CreatedAt:       time.Now().Add(-time.Hour),
+		}
+	}
+}
+
+func (r *fakeRollupRepository) sortedIDs() []uint {
+	ids := make([]uint, 0, len(r.committed))
+	for id := range r.committed {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func (r *fakeRollupRepository) GetWatermark(name string) (*models.RollupWatermark, error) {
+	return &models.RollupWatermark{Name: name, LastInteractionID: r.watermark}, nil
+}
+
+func (r *fakeRollupRepository) MaxInteractionID(createdBefore time.Time) (uint, error) {
+	var maxID uint
+	for id := range r.committed {
+		if id > maxID {
+			maxID = id
+		}
+	}
+	return maxID, nil
+}
+
+func (r *fakeRollupRepository) ListInteractions(afterID, upToID uint, from, to *time.Time, limit int) ([]models.UserInteraction, error) {
+	var interactions []models.UserInteraction
+	for _, id := range r.sortedIDs() {
+		if id > afterID && id <= upToID && len(interactions) < limit {
+			interactions = append(interactions, r.committed[id])
+		}
+	}
+	return interactions, nil
+}
+
+func (r *fakeRollupRepository) ListInteractionsByID(ids []uint) ([]models.UserInteraction, error) {
+	var interactions []models.UserInteraction
+	for _, id := range ids {
+		if interaction, ok := r.committed[id]; ok {
+			interactions = append(interactions, interaction)
+		}
+	}
+	return interactions, nil
+}
+
+func (r *fakeRollupRepository) ListGaps(name string) ([]models.RollupGap, error) {
+	gaps := make([]models.RollupGap, 0, len(r.gaps))
+	for id, seenAt := range r.gaps {
+		gaps = append(gaps, models.RollupGap{Name: name, InteractionID: id, SeenAt: seenAt})
+	}
+	sort.Slice(gaps, func(i, j int) bool { return gaps[i].InteractionID < gaps[j].InteractionID })
+	return gaps, nil
+}
+
+func (r *fakeRollupRepository) updateGaps(gaps repository.RollupGapChange) error {
+	for _, id := range gaps.Resolved {
+		if _, ok := r.gaps[id]; !ok {
+			return repository.ErrWatermarkMoved
+		}
+	}
+	for _, id := range gaps.Resolved {
+		delete(r.gaps, id)
+	}
+	for _, id := range gaps.Open {
+		r.gaps[id] = time.Now()
+	}
+	return nil
+}
+
+func (r *fakeRollupRepository) Apply(name string, fromID, toID uint, gaps repository.RollupGapChange, contentStats []models.ContentDailyStat, userStats []models.UserDailyStat) error {
+	if r.watermark != fromID {
+		return repository.ErrWatermarkMoved
+	}
+	if err := r.updateGaps(gaps); err != nil {
+		return err
+	}
+	for _, stat := range contentStats {
+		r.totals[stat.ContentID] += stat.Interactions
+	}
+	r.watermark = toID
+	return nil
+}
+
+func (r *fakeRollupRepository) ReplaceRange(name string, snapshot repository.RollupSnapshot, resolved []uint, from, to *time.Time, contentStats []models.ContentDailyStat, userStats []models.UserDailyStat) error {
+	if r.watermark != snapshot.Watermark || len(r.gaps) != len(snapshot.Gaps) {
+		return repository.ErrWatermarkMoved
+	}
+	if err := r.updateGaps(repository.RollupGapChange{Resolved: resolved}); err != nil {
+		return err
+	}
+	r.totals = make(map[uint]int64)
+	for _, stat := range contentStats {
+		r.totals[stat.ContentID] += stat.Interactions
+	}
+	return nil
+}
+
+type fakeArchiveRepository struct {
+	repository.ArchiveRepository
+}
+
+func (fakeArchiveRepository) ArchivedUntil() (*time.Time, error) { return nil, nil }
+
+func TestRollupWatermark(t *testing.T) {
+	type step struct {
+		// commit: confirma as interações; catchup/backfill: roda o job;
+		// expire: envelhece as lacunas abertas além do prazo
+		op  string
+		ids []uint
+	}
+
+	tests := []struct {
+		name          string
+		steps         []step
+		wantWatermark uint
+		wantGaps      int
+		// Interações agregadas no total
+		wantTotal int64
+	}{
+		{
+			name:          "interações confirmadas em ordem",
+			steps:         []step{{op: "commit", ids: []uint{1, 2, 3}}, {op: "catchup"}, {op: "commit", ids: []uint{4}}, {op: "catchup"}},
+			wantWatermark: 4,
+			wantTotal:     4,
+		},
+		{
+			name: "ID menor confirmado depois do watermark é agregado uma vez",
+			steps: []step{
+				{op: "commit", ids: []uint{1, 3}}, {op: "catchup"},
+				{op: "commit", ids: []uint{2}}, {op: "catchup"}, {op: "catchup"},
+			},
+			wantWatermark: 3,
+			wantTotal:     3,
+		},
+		{
+			name: "lacuna aberta continua registrada",
+			steps: []step{
+				{op: "commit", ids: []uint{1, 4}}, {op: "catchup"},
+			},
+			wantWatermark: 4,
+			wantGaps:      2,
+			wantTotal:     2,
+		},
+		{
+			name: "lacuna que nunca aparece expira",
+			steps: []step{
+				{op: "commit", ids: []uint{1, 3}}, {op: "catchup"},
+				{op: "expire"}, {op: "catchup"},
+			},
+			wantWatermark: 3,
+			wantTotal:     2,
+		},
+		{
+			name: "backfill fecha a lacuna sem contar duas vezes",
+			steps: []step{
+				{op: "commit", ids: []uint{1, 3}}, {op: "catchup"},
+				{op: "commit", ids: []uint{2}}, {op: "backfill"}, {op: "catchup"},
+			},
+			wantWatermark: 3,
+			wantTotal:     3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newFakeRollupRepository()
+			svc := NewRollupService(repo, fakeArchiveRepository{}, nil, nil)
+
+			for i, st := range tt.steps {
+				var err error
+				switch st.op {
+				case "commit":
+					repo.commit(st.ids...)
+				case "catchup":
+					_, err = svc.CatchUp()
+				case "backfill":
+					_, err = svc.Backfill(nil, nil)
+				case "expire":
+					for id := range repo.gaps {
+						repo.gaps[id] = time.Now().Add(-2 * rollupGapTimeout)
+					}
+				}
+				if err != nil {
+					t.Fatalf("passo %d (%s): %v", i, st.op, err)
+				}
+			}
+
+			if repo.watermark != tt.wantWatermark {
+				t.Errorf("watermark = %d, esperado %d", repo.watermark, tt.wantWatermark)
+			}
+			if len(repo.gaps) != tt.wantGaps {
+				t.Errorf("lacunas abertas = %d, esperado %d", len(repo.gaps), tt.wantGaps)
+			}
+			var total int64
+			for _, count := range repo.totals {
+				total += count
+			}
+			if total != tt.wantTotal {
+				t.Errorf("interações agregadas = %d, esperado %d", total, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestIDGaps(t *testing.T) {
+	tests := []struct {
+		name    string
+		afterID uint
+		ids     []uint
+		want    []uint
+	}{
+		{name: "sequência contínua", afterID: 0, ids: []uint{1, 2, 3}},
+		{name: "buraco depois do watermark", afterID: 5, ids: []uint{8}, want: []uint{6, 7}},
+		{name: "buracos entre interações", afterID: 0, ids: []uint{1, 3, 6}, want: []uint{2, 4, 5}},
+		{name: "salto da sequência é ignorado", afterID: 1, ids: []uint{rollupMaxGapSpan + 10}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			interactions := make([]models.UserInteraction, len(tt.ids))
+			for i, id := range tt.ids {
+				interactions[i].ID = id
+			}
+			got := idGaps(tt.afterID, interactions)
+			if len(got) != len(tt.want) {
+				t.Fatalf("lacunas = %v, esperado %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("lacunas = %v, esperado %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
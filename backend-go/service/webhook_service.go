@@ -0,0 +1,733 @@
+package service
+
+import (
+	"backend-go/models"
+	"backend-go/repository"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Tipos de evento emitidos para webhooks
+const (
+	EventContentPublished   = "content.published"
+	EventContentUpdated     = "content.updated"
+	EventContentDeleted     = "content.deleted"
+	EventInteractionCreated = "interaction.created"
+)
+
+var validWebhookEventTypes = map[string]bool{
+	EventContentPublished:   true,
+	EventContentUpdated:     true,
+	EventContentDeleted:     true,
+	EventInteractionCreated: true,
+}
+
+const (
+	// Assina todos os tipos de evento
+	webhookAllEvents = "*"
+	// Entregas processadas por execução do worker
+	webhookBatchSize = 100
+	// Entregas simultâneas
+	webhookConcurrency = 8
+	// Trecho máximo da resposta do parceiro guardado no log
+	maxWebhookResponseBytes = 1024
+	// Tempo máximo para resolver o host da URL ao validar uma assinatura
+	webhookResolveTimeout = 5 * time.Second
+)
+
+// Headers enviados em cada entrega
+const (
+	WebhookSignatureHeader = "X-Webhook-Signature"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookDeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// EventEmitter publica eventos de domínio para consumidores externos.
+// Emit não bloqueia nem falha a operação que originou o evento.
+type EventEmitter interface {
+	Emit(eventType string, data interface{})
+}
+
+// WebhookEvent é o corpo enviado a cada assinatura
+type WebhookEvent struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data" swaggertype:"object"`
+}
+
+// ContentEventData é o conteúdo enviado em content.published e content.updated
+type ContentEventData struct {
+	ID          uint      `json:"id"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	Type        string    `json:"type"`
+	ReleaseDate time.Time `json:"release_date"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// ContentDeletedEventData identifica o conteúdo removido em content.deleted
+type ContentDeletedEventData struct {
+	ID uint `json:"id"`
+}
+
+// InteractionEventData é a interação enviada no evento interaction.created
+type InteractionEventData struct {
+	ID              uint      `json:"id"`
+	UserID          uint      `json:"user_id"`
+	ContentID       uint      `json:"content_id"`
+	InteractionType string    `json:"interaction_type"`
+	Rating          *float64  `json:"rating,omitempty"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+func newContentEventData(content *models.Content) ContentEventData {
+	return ContentEventData{
+		ID:          content.ID,
+		Title:       content.Title,
+		Description: content.Description,
+		Type:        content.Type,
+		ReleaseDate: content.ReleaseDate,
+		CreatedAt:   content.CreatedAt,
+	}
+}
+
+func newInteractionEventData(interaction *models.UserInteraction) InteractionEventData {
+	return InteractionEventData{
+		ID:              interaction.ID,
+		UserID:          interaction.UserID,
+		ContentID:       interaction.ContentID,
+		InteractionType: interaction.InteractionType,
+		Rating:          interaction.Rating,
+		CreatedAt:       interaction.CreatedAt,
+	}
+}
+
+// WebhookPolicy define as tentativas, o backoff e o timeout das entregas
+type WebhookPolicy struct {
+	MaxAttempts int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	Timeout     time.Duration
+}
+
+// CreateWebhookRequest representa os dados para criar uma assinatura
+type CreateWebhookRequest struct {
+	URL         string   `json:"url" binding:"required"`
+	EventTypes  []string `json:"event_types" binding:"required,min=1"`
+	Secret      string   `json:"secret,omitempty"`
+	Description string   `json:"description,omitempty"`
+}
+
+// UpdateWebhookRequest representa os dados para atualizar uma assinatura
+type UpdateWebhookRequest struct {
+	URL         *string  `json:"url,omitempty"`
+	EventTypes  []string `json:"event_types,omitempty"`
+	Description *string  `json:"description,omitempty"`
+	Active      *bool    `json:"active,omitempty"`
+}
+
+// WebhookService define a interface para assinaturas e entregas de webhooks
+type WebhookService interface {
+	CreateSubscription(req CreateWebhookRequest) (*models.WebhookSubscription, error)
+	UpdateSubscription(id uint, req UpdateWebhookRequest) (*models.WebhookSubscription, error)
+	DeleteSubscription(id uint) error
+	GetSubscription(id uint) (*models.WebhookSubscription, error)
+	ListSubscriptions(page, limit int) ([]models.WebhookSubscription, int64, error)
+	ListDeliveries(subscriptionID uint, status string, page, limit int) ([]models.WebhookDelivery, int64, error)
+	GetDelivery(id uint) (*models.WebhookDelivery, error)
+	Redeliver(id uint) (*models.WebhookDelivery, error)
+	Dispatch() (int, error)
+}
+
+type webhookService struct {
+	repo       repository.WebhookRepository
+	outbox     repository.OutboxRepository
+	httpClient *http.Client
+	policy     WebhookPolicy
+	mu         sync.Mutex
+}
+
+// NewWebhookService cria uma nova instância do WebhookService
+func NewWebhookService(repo repository.WebhookRepository, outbox repository.OutboxRepository, policy WebhookPolicy) WebhookService {
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+	if policy.BaseBackoff <= 0 {
+		policy.BaseBackoff = time.Second
+	}
+	if policy.MaxBackoff < policy.BaseBackoff {
+		policy.MaxBackoff = policy.BaseBackoff
+	}
+	if policy.Timeout <= 0 {
+		policy.Timeout = 10 * time.Second
+	}
+	return &webhookService{
+		repo:       repo,
+		outbox:     outbox,
+		httpClient: newWebhookHTTPClient(policy.Timeout),
+		policy:     policy,
+	}
+}
+
+// newWebhookHTTPClient cria o cliente das entregas. O endereço é verificado
+// depois de resolvido, a cada conexão (inclusive em redirecionamentos), para
+// que um host que passou a apontar para a rede interna não seja chamado.
+func newWebhookHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: webhookDialControl,
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        webhookConcurrency,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
+
+// webhookDialControl recusa a conexão se o endereço já resolvido não for
+// permitido para webhooks
+func webhookDialControl(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || disallowedWebhookIP(ip) {
+		return fmt.Errorf("endereço %s não permitido para webhooks", host)
+	}
+	return nil
+}
+
+// newWebhookOutboxEvent monta o evento do outbox que o worker transforma em
+// entregas para as assinaturas. O payload já é o corpo enviado aos parceiros.
+func newWebhookOutboxEvent(eventType string, data interface{}) (*models.OutboxEvent, error) {
+	rawData, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar evento: %w", err)
+	}
+	eventID, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(WebhookEvent{
+		ID:        eventID,
+		Type:      eventType,
+		CreatedAt: time.Now(),
+		Data:      rawData,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar evento: %w", err)
+	}
+	return &models.OutboxEvent{
+		Topic:   models.OutboxTopicWebhookEvent,
+		Payload: string(payload),
+	}, nil
+}
+
+// fanOut transforma os eventos de webhook do outbox em entregas, uma para
+// cada assinatura ativa que assina o tipo do evento. As entregas e a baixa
+// dos eventos são gravadas na mesma transação.
+func (s *webhookService) fanOut() error {
+	for {
+		events, err := s.outbox.ListDue(models.OutboxTopicWebhookEvent, time.Now(), webhookBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		subscriptions, err := s.repo.ListActiveSubscriptions()
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		var deliveries []models.WebhookDelivery
+		ids := make([]uint, 0, len(events))
+		for i := range events {
+			var event WebhookEvent
+			if err := json.Unmarshal([]byte(events[i].Payload), &event); err != nil {
+				// Payload inválido nunca será entregue: vai direto para dead
+				message := fmt.Sprintf("payload inválido: %v", err)
+				events[i].Attempts++
+				events[i].LastError = &message
+				events[i].Status = models.OutboxStatusDead
+				if err := s.outbox.Update(&events[i]); err != nil {
+					return err
+				}
+				continue
+			}
+			for _, subscription := range subscriptions {
+				if subscribesTo(subscription.EventTypes, event.Type) {
+					deliveries = append(deliveries, models.WebhookDelivery{
+						SubscriptionID: subscription.ID,
+						EventID:        event.ID,
+						EventType:      event.Type,
+						Payload:        events[i].Payload,
+						Status:         models.WebhookDeliveryPending,
+						NextAttemptAt:  now,
+					})
+				}
+			}
+			ids = append(ids, events[i].ID)
+		}
+		if err := s.repo.EnqueueFromOutbox(deliveries, ids); err != nil {
+			return err
+		}
+
+		if len(events) < webhookBatchSize {
+			return nil
+		}
+	}
+}
+
+// subscribesTo indica se a lista de tipos assinados inclui o evento
+func subscribesTo(eventTypes, eventType string) bool {
+	for _, subscribed := range strings.Split(eventTypes, ",") {
+		if subscribed == webhookAllEvents || subscribed == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateSubscription valida e cria uma assinatura. Sem secret informado, um
+// aleatório é gerado; ele só é devolvido nesta resposta.
+func (s *webhookService) CreateSubscription(req CreateWebhookRequest) (*models.WebhookSubscription, error) {
+	if err := validateWebhookURL(req.URL); err != nil {
+		return nil, err
+	}
+	eventTypes, err := normalizeWebhookEventTypes(req.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+	if len(req.Description) > 255 {
+		return nil, errors.New("descrição deve ter no máximo 255 caracteres")
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		if secret, err = randomHex(32); err != nil {
+			return nil, err
+		}
+	}
+	if len(secret) < 16 || len(secret) > 128 {
+		return nil, errors.New("secret deve ter entre 16 e 128 caracteres")
+	}
+
+	subscription := &models.WebhookSubscription{
+		URL:         req.URL,
+		Secret:      secret,
+		EventTypes:  eventTypes,
+		Description: req.Description,
+		Active:      true,
+	}
+	if err := s.repo.CreateSubscription(subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// UpdateSubscription atualiza apenas os campos informados
+func (s *webhookService) UpdateSubscription(id uint, req UpdateWebhookRequest) (*models.WebhookSubscription, error) {
+	subscription, err := s.repo.GetSubscription(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		if err := validateWebhookURL(*req.URL); err != nil {
+			return nil, err
+		}
+		subscription.URL = *req.URL
+	}
+	if req.EventTypes != nil {
+		eventTypes, err := normalizeWebhookEventTypes(req.EventTypes)
+		if err != nil {
+			return nil, err
+		}
+		subscription.EventTypes = eventTypes
+	}
+	if req.Description != nil {
+		if len(*req.Description) > 255 {
+			return nil, errors.New("descrição deve ter no máximo 255 caracteres")
+		}
+		subscription.Description = *req.Description
+	}
+	if req.Active != nil {
+		subscription.Active = *req.Active
+	}
+
+	if err := s.repo.UpdateSubscription(subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+// DeleteSubscription remove uma assinatura e seu log de entregas
+func (s *webhookService) DeleteSubscription(id uint) error {
+	return s.repo.DeleteSubscription(id)
+}
+
+// GetSubscription busca uma assinatura
+func (s *webhookService) GetSubscription(id uint) (*models.WebhookSubscription, error) {
+	return s.repo.GetSubscription(id)
+}
+
+// ListSubscriptions lista as assinaturas com paginação
+func (s *webhookService) ListSubscriptions(page, limit int) ([]models.WebhookSubscription, int64, error) {
+	page, limit = normalizeWebhookPage(page, limit)
+	return s.repo.ListSubscriptions(limit, (page-1)*limit)
+}
+
+// ListDeliveries lista o log de entregas de uma assinatura
+func (s *webhookService) ListDeliveries(subscriptionID uint, status string, page, limit int) ([]models.WebhookDelivery, int64, error) {
+	switch status {
+	case "", models.WebhookDeliveryPending, models.WebhookDeliveryDelivered, models.WebhookDeliveryFailed:
+	default:
+		return nil, 0, errors.New("status inválido. Valores válidos: pending, delivered, failed")
+	}
+	if _, err := s.repo.GetSubscription(subscriptionID); err != nil {
+		return nil, 0, err
+	}
+	page, limit = normalizeWebhookPage(page, limit)
+	return s.repo.ListDeliveries(subscriptionID, status, limit, (page-1)*limit)
+}
+
+// GetDelivery busca uma entrega do log
+func (s *webhookService) GetDelivery(id uint) (*models.WebhookDelivery, error) {
+	return s.repo.GetDelivery(id)
+}
+
+// Redeliver enfileira uma nova entrega do mesmo evento, preservando a entrega
+// original no log. O parceiro pode usar o ID do evento para descartar duplicatas.
+func (s *webhookService) Redeliver(id uint) (*models.WebhookDelivery, error) {
+	original, err := s.repo.GetDelivery(id)
+	if err != nil {
+		return nil, err
+	}
+	if original.Status == models.WebhookDeliveryPending {
+		return nil, errors.New("entrega ainda está pendente")
+	}
+
+	delivery := models.WebhookDelivery{
+		SubscriptionID: original.SubscriptionID,
+		EventID:        original.EventID,
+		EventType:      original.EventType,
+		Payload:        original.Payload,
+		Status:         models.WebhookDeliveryPending,
+		NextAttemptAt:  time.Now(),
+	}
+	deliveries := []models.WebhookDelivery{delivery}
+	if err := s.repo.CreateDeliveries(deliveries); err != nil {
+		return nil, err
+	}
+	return &deliveries[0], nil
+}
+
+// Dispatch gera as entregas dos eventos enfileirados no outbox e faz as
+// entregas pendentes cuja próxima tentativa já venceu, com até
+// webhookConcurrency requisições simultâneas. Entregas sem resposta 2xx
+// são reagendadas com backoff exponencial até esgotar as tentativas (failed).
+// Retorna o número de entregas bem-sucedidas.
+func (s *webhookService) Dispatch() (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Uma falha ao gerar entregas não impede as já enfileiradas de saírem
+	fanOutErr := s.fanOut()
+
+	deliveries, err := s.repo.ListDueDeliveries(time.Now(), webhookBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	if len(deliveries) == 0 {
+		return 0, fanOutErr
+	}
+
+	subscriptions := make(map[uint]*models.WebhookSubscription)
+	for _, delivery := range deliveries {
+		if _, ok := subscriptions[delivery.SubscriptionID]; ok {
+			continue
+		}
+		subscription, err := s.repo.GetSubscription(delivery.SubscriptionID)
+		if err != nil {
+			subscription = nil
+		}
+		subscriptions[delivery.SubscriptionID] = subscription
+	}
+
+	var (
+		wg        sync.WaitGroup
+		countMu   sync.Mutex
+		delivered int
+		firstErr  error
+	)
+	sem := make(chan struct{}, webhookConcurrency)
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		subscription := subscriptions[delivery.SubscriptionID]
+
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			ok, err := s.deliver(delivery, subscription)
+			countMu.Lock()
+			defer countMu.Unlock()
+			if ok {
+				delivered++
+			}
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firstErr == nil {
+		firstErr = fanOutErr
+	}
+	return delivered, firstErr
+}
+
+// deliver faz uma tentativa de entrega e grava o resultado no log
+func (s *webhookService) deliver(delivery *models.WebhookDelivery, subscription *models.WebhookSubscription) (bool, error) {
+	delivery.Attempts++
+
+	var status int
+	var deliverErr error
+	switch {
+	case subscription == nil:
+		deliverErr = errors.New("assinatura não encontrada")
+	case !subscription.Active:
+		deliverErr = errors.New("assinatura inativa")
+	default:
+		status, deliverErr = s.post(delivery, subscription)
+	}
+
+	if status != 0 {
+		delivery.ResponseStatus = &status
+	}
+	if deliverErr == nil {
+		now := time.Now()
+		delivery.Status = models.WebhookDeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = nil
+		return true, s.repo.UpdateDelivery(delivery)
+	}
+
+	message := deliverErr.Error()
+	delivery.LastError = &message
+	if delivery.Attempts >= s.policy.MaxAttempts || subscription == nil || !subscription.Active {
+		delivery.Status = models.WebhookDeliveryFailed
+	} else {
+		delivery.NextAttemptAt = time.Now().Add(exponentialBackoff(s.policy.BaseBackoff, s.policy.MaxBackoff, delivery.Attempts))
+	}
+	return false, s.repo.UpdateDelivery(delivery)
+}
+
+// post envia o evento assinado para a URL da assinatura
+func (s *webhookService) post(delivery *models.WebhookDelivery, subscription *models.WebhookSubscription) (int, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequest(http.MethodPost, subscription.URL, bytes.NewBufferString(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("erro ao criar requisição: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, delivery.EventType)
+	req.Header.Set(WebhookDeliveryHeader, strconv.FormatUint(uint64(delivery.ID), 10))
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhookPayload(subscription.Secret, timestamp, []byte(delivery.Payload)))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao chamar webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxWebhookResponseBytes))
+		return resp.StatusCode, fmt.Errorf("webhook respondeu status %d: %s", resp.StatusCode, string(body))
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxWebhookResponseBytes))
+	return resp.StatusCode, nil
+}
+
+// SignWebhookPayload calcula a assinatura HMAC-SHA256 (hex) de
+// "<timestamp>.<corpo>" com o secret da assinatura. O parceiro recalcula com
+// os headers X-Webhook-Timestamp e o corpo recebido e compara com X-Webhook-Signature.
+func SignWebhookPayload(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// validateWebhookURL exige uma URL absoluta http(s) cujo host não aponte para
+// a rede interna nem para faixas reservadas (webhookDeniedNets). O endereço é
+// conferido de novo a cada entrega, pois o DNS pode mudar depois do cadastro.
+func validateWebhookURL(raw string) error {
+	if len(raw) > 500 {
+		return errors.New("url deve ter no máximo 500 caracteres")
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return errors.New("url inválida, use uma URL http(s) absoluta")
+	}
+
+	host := parsed.Hostname()
+	if ip := net.ParseIP(host); ip != nil {
+		if disallowedWebhookIP(ip) {
+			return errors.New("url inválida, endereços internos não são permitidos")
+		}
+		return nil
+	}
+	if strings.EqualFold(host, "localhost") || strings.HasSuffix(strings.ToLower(host), ".localhost") {
+		return errors.New("url inválida, endereços internos não são permitidos")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), webhookResolveTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil || len(addrs) == 0 {
+		return errors.New("url inválida, não foi possível resolver o host")
+	}
+	for _, addr := range addrs {
+		if disallowedWebhookIP(addr.IP) {
+			return errors.New("url inválida, endereços internos não são permitidos")
+		}
+	}
+	return nil
+}
+
+// webhookDeniedNets são as faixas que nunca recebem webhooks: redes internas,
+// compartilhadas (CGNAT), reservadas e de documentação, além dos prefixos de
+// tradução IPv6 que embutem um endereço IPv4 arbitrário. Endereços IPv4
+// mapeados em IPv6 (::ffff:a.b.c.d) são conferidos contra as faixas IPv4.
+var webhookDeniedNets = mustParseCIDRs(
+	"0.0.0.0/8",       // "esta rede"
+	"10.0.0.0/8",      // privada
+	"100.64.0.0/10",   // compartilhada (CGNAT)
+	"127.0.0.0/8",     // loopback
+	"169.254.0.0/16",  // link-local, inclui metadados de nuvem
+	"172.16.0.0/12",   // privada
+	"192.0.0.0/24",    // atribuições do protocolo IETF
+	"192.0.2.0/24",    // documentação
+	"192.88.99.0/24",  // relay 6to4
+	"192.168.0.0/16",  // privada
+	"198.18.0.0/15",   // testes de desempenho
+	"198.51.100.0/24", // documentação
+	"203.0.113.0/24",  // documentação
+	"224.0.0.0/4",     // multicast
+	"240.0.0.0/4",     // reservada, inclui broadcast
+	"::/128",          // não especificado
+	"::1/128",         // loopback
+	"64:ff9b::/96",    // NAT64
+	"64:ff9b:1::/48",  // NAT64 local
+	"100::/64",        // descarte
+	"2001::/23",       // atribuições do protocolo IETF, inclui Teredo
+	"2001:db8::/32",   // documentação
+	"2002::/16",       // 6to4
+	"fc00::/7",        // local única
+	"fe80::/10",       // link-local
+	"ff00::/8",        // multicast
+)
+
+// mustParseCIDRs converte as faixas fixas do código; uma faixa inválida é bug
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets[i] = ipNet
+	}
+	return nets
+}
+
+// disallowedWebhookIP indica se o endereço é da rede interna ou não roteável
+func disallowedWebhookIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	for _, ipNet := range webhookDeniedNets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeWebhookEventTypes valida os tipos e os grava em ordem, sem duplicatas
+func normalizeWebhookEventTypes(eventTypes []string) (string, error) {
+	if len(eventTypes) == 0 {
+		return "", errors.New("informe ao menos um tipo de evento")
+	}
+	seen := make(map[string]bool, len(eventTypes))
+	normalized := make([]string, 0, len(eventTypes))
+	for _, eventType := range eventTypes {
+		eventType = strings.TrimSpace(eventType)
+		if eventType == webhookAllEvents {
+			return webhookAllEvents, nil
+		}
+		if !validWebhookEventTypes[eventType] {
+			return "", errors.New("tipo de evento inválido: " + eventType)
+		}
+		if !seen[eventType] {
+			seen[eventType] = true
+			normalized = append(normalized, eventType)
+		}
+	}
+	sort.Strings(normalized)
+	return strings.Join(normalized, ","), nil
+}
+
+func normalizeWebhookPage(page, limit int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100 // Limite máximo
+	}
+	return page, limit
+}
+
+// randomHex gera n bytes aleatórios codificados em hex
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("erro ao gerar valor aleatório: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestWebhookDialControl(t *testing.T) {
+	tests := []struct {
+		name    string
+		address string
+		wantErr bool
+	}{
+		{name: "IPv4 público", address: "93.184.216.34:443"},
+		{name: "IPv6 público", address: "[2606:4700:4700::1111]:443"},
+		{name: "loopback", address: "127.0.0.1:80", wantErr: true},
+		{name: "loopback fora de 127.0.0.1", address: "127.8.9.10:80", wantErr: true},
+		{name: "rede privada 10/8", address: "10.1.2.3:80", wantErr: true},
+		{name: "rede privada 172.16/12", address: "172.31.255.1:80", wantErr: true},
+		{name: "rede privada 192.168/16", address: "192.168.0.10:80", wantErr: true},
+		{name: "CGNAT", address: "100.64.0.1:80", wantErr: true},
+		{name: "fim da faixa CGNAT", address: "100.127.255.254:80", wantErr: true},
+		{name: "vizinho da faixa CGNAT é público", address: "100.128.0.1:80"},
+		{name: "metadados de nuvem", address: "169.254.169.254:80", wantErr: true},
+		{name: "esta rede", address: "0.0.0.0:80", wantErr: true},
+		{name: "testes de desempenho", address: "198.18.0.1:80", wantErr: true},
+		{name: "documentação", address: "203.0.113.7:80", wantErr: true},
+		{name: "multicast", address: "224.0.0.1:80", wantErr: true},
+		{name: "broadcast", address: "255.255.255.255:80", wantErr: true},
+		{name: "loopback IPv6", address: "[::1]:80", wantErr: true},
+		{name: "IPv6 não especificado", address: "[::]:80", wantErr: true},
+		{name: "IPv4 mapeado em IPv6", address: "[::ffff:127.0.0.1]:80", wantErr: true},
+		{name: "CGNAT mapeado em IPv6", address: "[::ffff:100.64.0.1]:80", wantErr: true},
+		{name: "NAT64 para a rede interna", address: "[64:ff9b::a00:1]:80", wantErr: true},
+		{name: "6to4", address: "[2002:a00:1::1]:80", wantErr: true},
+		{name: "local única IPv6", address: "[fd00::1]:80", wantErr: true},
+		{name: "link-local IPv6", address: "[fe80::1]:80", wantErr: true},
+		{name: "nome não resolvido", address: "localhost:80", wantErr: true},
+		{name: "endereço sem porta", address: "93.184.216.34", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := webhookDialControl("tcp", tt.address, nil)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("webhookDialControl(%q) = %v, esperado erro: %v", tt.address, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWebhookHTTPClientRefusesInternalAddress(t *testing.T) {
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	// Um redirecionamento para a rede interna passa pela mesma verificação
+	redirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, server.URL, http.StatusFound)
+	}))
+	defer redirect.Close()
+
+	client := newWebhookHTTPClient(time.Second)
+	for _, target := range []string{server.URL, redirect.URL} {
+		resp, err := client.Post(target, "application/json", nil)
+		if err == nil {
+			resp.Body.Close()
+			t.Errorf("POST %s: conexão com loopback não foi recusada", target)
+		}
+	}
+	if called {
+		t.Error("o servidor interno recebeu a entrega")
+	}
+}
+
+func TestValidateWebhookURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		wantErr bool
+	}{
+		{name: "IP público", url: "https://93.184.216.34/hook"},
+		{name: "esquema não http", url: "ftp://93.184.216.34/hook", wantErr: true},
+		{name: "URL relativa", url: "/hook", wantErr: true},
+		{name: "localhost", url: "http://localhost:8080/hook", wantErr: true},
+		{name: "subdomínio de localhost", url: "http://api.localhost/hook", wantErr: true},
+		{name: "CGNAT", url: "http://100.100.100.200/latest/meta-data", wantErr: true},
+		{name: "metadados de nuvem", url: "http://169.254.169.254/latest/meta-data", wantErr: true},
+		{name: "IPv4 mapeado em IPv6", url: "http://[::ffff:10.0.0.1]/hook", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateWebhookURL(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateWebhookURL(%q) = %v, esperado erro: %v", tt.url, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDisallowedWebhookIPCoversDeniedNets(t *testing.T) {
+	for _, ipNet := range webhookDeniedNets {
+		if !disallowedWebhookIP(ipNet.IP) {
+			t.Errorf("início da faixa %s permitido", ipNet)
+		}
+		last := make(net.IP, len(ipNet.IP))
+		for i := range ipNet.IP {
+			last[i] = ipNet.IP[i] | ^ipNet.Mask[i]
+		}
+		if !disallowedWebhookIP(last) {
+			t.Errorf("fim da faixa %s (%s) permitido", ipNet, last)
+		}
+	}
+}
+
+func TestSignWebhookPayload(t *testing.T) {
+	body := []byte(`{"event":"content.published"}`)
+	// Calculada fora do Go: printf '%s' '1700000000.<corpo>' | openssl dgst -sha256 -hmac segredo
+	const want = "6227e954a206db4ac70b682d4280c0ee1eac84f3ee2d2fdfb976669610db15b8"
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		body      []byte
+		wantMatch bool
+	}{
+		{name: "assinatura conhecida", secret: "segredo", timestamp: "1700000000", body: body, wantMatch: true},
+		{name: "outro secret", secret: "outro", timestamp: "1700000000", body: body},
+		{name: "outro timestamp", secret: "segredo", timestamp: "1700000001", body: body},
+		{name: "corpo alterado", secret: "segredo", timestamp: "1700000000", body: []byte(`{"event":"content.deleted"}`)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SignWebhookPayload(tt.secret, tt.timestamp, tt.body)
+			if (got == want) != tt.wantMatch {
+				t.Errorf("SignWebhookPayload = %s, igual à conhecida: %v, esperado %v", got, got == want, tt.wantMatch)
+			}
+		})
+	}
+}
@@ -5,12 +5,15 @@ from fastapi import APIRouter, HTTPException, BackgroundTasks
 from app.models.recommendation import SimpleRecommendationModel
 from app.services.dataset_service import DatasetService
 from app.services.database_service import database_service
+from app.services.model_reloader import ModelReloader
 from app.schemas.recommendation import (
     RecommendationRequest,
     RecommendationResponse,
     ContentRecommendation,
     InteractionRequest,
-    InteractionResponse
+    InteractionResponse,
+    InteractionBatchRequest,
+    InteractionBatchResponse
 )
 from app.core.config import settings
 import logging
@@ -25,6 +28,10 @@ dataset_service = DatasetService(
     n_contents=settings.simulated_contents
 )
 recommendation_model = SimpleRecommendationModel(dataset_service)
+model_reloader = ModelReloader(
+    recommendation_model.reload_model,
+    settings.model_reload_min_interval_seconds
+)
 
 @router.post("/", response_model=RecommendationResponse)
 async def get_recommendations(request: RecommendationRequest):
@@ -39,7 +46,8 @@ async def get_recommendations(request: RecommendationRequest):
         recommendations = recommendation_model.recommend(
             user_id=request.user_id,
             top_n=request.top_n,
-            method=request.method
+            method=request.method,
+            model_version=recommendation_model.model_version
         )
         
         if not recommendations:
@@ -134,13 +142,45 @@ async def create_interaction(
             detail=f"Erro interno ao criar interação: {str(e)}"
         )
 
+@router.post("/interactions/batch", response_model=InteractionBatchResponse)
+async def notify_interactions_batch(
+    request: InteractionBatchRequest,
+    background_tasks: BackgroundTasks
+):
+    """
+    Endpoint para notificar um lote de interações sincronizadas offline
+
+    As interações já foram gravadas pelo backend-go numa única transação,
+    então aqui apenas agendamos uma recarga do modelo para todo o lote.
+    """
+    if settings.data_mode == "real" and database_service.is_connected():
+        background_tasks.add_task(reload_recommendation_model)
+        logger.info(f"Lote com {len(request.interactions)} interações recebido, modelo será atualizado em background")
+    else:
+        logger.info(f"Lote com {len(request.interactions)} interações recebido (modo simulado)")
+
+    return InteractionBatchResponse(
+        success=True,
+        message="Lote de interações recebido com sucesso",
+        received=len(request.interactions)
+    )
+
+def handle_interaction_events(messages):
+    """
+    Processa um lote de interações consumidas do barramento de eventos
+
+    Assim como no endpoint de lote, as interações já estão no banco; basta
+    pedir uma recarga do modelo, que é agrupada com as dos lotes seguintes.
+    """
+    if settings.data_mode == "real" and database_service.is_connected():
+        logger.info(f"{len(messages)} interações recebidas do barramento, modelo será atualizado")
+        model_reloader.request()
+    else:
+        logger.info(f"{len(messages)} interações recebidas do barramento (modo simulado)")
+
 def reload_recommendation_model():
     """
-    Função auxiliar para recarregar o modelo em background
+    Função auxiliar para recarregar o modelo em background, com o intervalo
+    mínimo entre recargas
     """
-    try:
-        logger.info("Recarregando modelo de recomendação com novas interações...")
-        recommendation_model.reload_model()
-        logger.info("Modelo de recomendação atualizado com sucesso")
-    except Exception as e:
-        logger.error(f"Erro ao recarregar modelo: {e}")
+    model_reloader.request()
@@ -18,6 +18,23 @@ class Settings(BaseSettings):
     # Modo de operação: 'real' usa banco de dados, 'simulated' usa dados simulados
     data_mode: str = "real"  # 'real' ou 'simulated'
     
+    # Fonte das preferências em modo real: 'affinity' usa user_content_affinity
+    # (pesos e decaimento definidos no backend-go), 'interactions' usa o log bruto
+    preference_source: str = "affinity"  # 'affinity' ou 'interactions'
+    # Afinidade que corresponde a ~4.5 na escala 1-5 (3 + 2 * tanh(1))
+    affinity_scale: float = 3.0
+    
+    # Barramento de eventos do backend-go (EVENT_BUS=embedded e
+    # RECOMMENDER_DELIVERY=bus). Com a URL definida, as interações são
+    # consumidas do broker em vez de recebidas por HTTP.
+    event_bus_url: Optional[str] = None  # ex.: http://backend:8080/api/bus
+    event_bus_consumer: str = "recommender"
+    event_bus_subject: str = "recommender.interaction.v1"
+    event_bus_wait_seconds: int = 20
+    # Intervalo mínimo entre recargas do modelo; lotes que chegam nesse
+    # intervalo são atendidos por uma única recarga
+    model_reload_min_interval_seconds: float = 30.0
+    
     # Configurações para dados simulados (fallback)
     simulated_users: int = 100
     simulated_contents: int = 50
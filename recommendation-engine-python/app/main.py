@@ -34,6 +34,19 @@ async def startup_event():
             logger.info("✅ Conectado ao banco de dados")
         else:
             logger.warning("⚠️ Não foi possível conectar ao banco de dados - usando dados simulados")
+    
+    # Consumir interações do barramento de eventos do backend-go, se configurado
+    if settings.event_bus_url:
+        from app.services.event_consumer import EventBusConsumer
+        app.state.event_consumer = EventBusConsumer(recommendations.handle_interaction_events)
+        app.state.event_consumer.start()
+
+@app.on_event("shutdown")
+async def shutdown_event():
+    """Evento executado ao encerrar a aplicação"""
+    consumer = getattr(app.state, "event_consumer", None)
+    if consumer:
+        consumer.stop()
 
 @app.get("/")
 def read_root():
@@ -5,8 +5,11 @@ Usa Pandas + Scikit-learn conforme especificado na tarefa
 
 import pandas as pd
 import numpy as np
+from datetime import datetime, timezone
 from typing import List, Dict
+from app.core.config import settings
 from app.services.dataset_service import DatasetService
+from app.services.database_service import database_service
 from app.utils.similarity import calculate_user_similarity, get_top_similar_users
 
 class SimpleRecommendationModel:
@@ -17,6 +20,7 @@ class SimpleRecommendationModel:
         self.interactions_df = None
         self.contents_df = None
         self.interactions_matrix = None
+        self.model_version = None
         self._initialize_model()
 
     def _initialize_model(self):
@@ -24,12 +28,19 @@ class SimpleRecommendationModel:
         self.interactions_df, self.contents_df = \
             self.dataset_service.load_dataset()
         self.interactions_matrix = self.dataset_service.get_interactions_matrix()
+        self._stamp_version()
     
     def reload_model(self):
         """Recarrega o modelo com dados atualizados (útil após novas interações)"""
         self.interactions_df, self.contents_df = \
             self.dataset_service.reload_dataset()
         self.interactions_matrix = self.dataset_service.get_interactions_matrix()
+        self._stamp_version()
+
+    def _stamp_version(self):
+        """Identifica o modelo carregado pela versão da aplicação e o horário da carga"""
+        loaded_at = datetime.now(timezone.utc).strftime("%Y%m%d%H%M%S")
+        self.model_version = f"{settings.version}+{loaded_at}"
 
     def recommend_by_similarity(
         self,
@@ -138,7 +149,8 @@ class SimpleRecommendationModel:
         (Baseado em ratings e número de interações)
         
         Algoritmo:
-        1. Calcula score de popularidade para cada conteúdo
+        1. Calcula score de popularidade para cada conteúdo, a partir dos
+           rollups diários quando disponíveis (dados reais)
         2. Score = média de ratings * log(número de interações)
         3. Remove conteúdos já visualizados pelo usuário
         4. Retorna top N conteúdos mais populares
@@ -151,10 +163,7 @@ class SimpleRecommendationModel:
             List[Dict]: Lista de recomendações com content_id, score, title
         """
         # Calcular popularidade de cada conteúdo
-        content_stats = self.interactions_df.groupby('content_id').agg({
-            'rating': ['mean', 'count']
-        })
-        content_stats.columns = ['avg_rating', 'interaction_count']
+        content_stats = self._load_content_stats()
         
         # Score de popularidade = média de rating * log(contagem)
         # Usa log para evitar que conteúdos com muitas interações dominem
@@ -170,9 +179,10 @@ class SimpleRecommendationModel:
             ]['content_id'].values
         )
         
-        # Remover conteúdos já visualizados e ordenar
+        # Remover conteúdos já visualizados (ou inexistentes) e ordenar
         available_contents = content_stats[
-            ~content_stats.index.isin(user_interactions)
+            ~content_stats.index.isin(user_interactions) &
+            content_stats.index.isin(self.contents_df['content_id'])
         ]
         
         top_contents = available_contents.nlargest(top_n, 'popularity_score')
@@ -198,6 +208,24 @@ class SimpleRecommendationModel:
         
         return recommendations
     
+    def _load_content_stats(self) -> pd.DataFrame:
+        """
+        Retorna avg_rating e interaction_count por conteúdo
+        
+        Com dados reais, lê os rollups diários do banco; se eles ainda não
+        existirem, calcula a partir das interações carregadas em memória
+        """
+        if self.dataset_service.use_real_data:
+            content_stats = database_service.fetch_content_popularity()
+            if content_stats is not None:
+                return content_stats[['avg_rating', 'interaction_count']]
+        
+        content_stats = self.interactions_df.groupby('content_id').agg({
+            'rating': ['mean', 'count']
+        })
+        content_stats.columns = ['avg_rating', 'interaction_count']
+        return content_stats
+    
     def recommend(
         self,
         user_id: int,
@@ -19,6 +19,7 @@ class RecommendationResponse(BaseModel):
     user_id: int
     recommendations: List[ContentRecommendation]
     method: str
+    model_version: Optional[str] = Field(None, description="Versão do modelo que gerou as recomendações")
 
 class InteractionType(str, Enum):
     """Tipos de interação disponíveis"""
@@ -31,8 +32,14 @@ class InteractionRequest(BaseModel):
     """Schema para criar uma nova interação"""
     user_id: int = Field(..., description="ID do usuário")
     content_id: int = Field(..., description="ID do conteúdo")
-    interaction_type: str = Field(..., description="Tipo de interação: 'view', 'like', 'dislike', 'rating'")
+    interaction_type: str = Field(..., description="Tipo de interação: 'view', 'like', 'dislike', 'rating', 'share', 'comment', 'bookmark' ou a retratação 'unlike', 'undislike', 'unrate'")
     rating: Optional[float] = Field(None, ge=1.0, le=5.0, description="Rating de 1 a 5 (opcional)")
+    session_id: Optional[str] = Field(None, max_length=64, description="Sessão do aplicativo")
+    device: Optional[str] = Field(None, max_length=32, description="Dispositivo do usuário")
+    app_version: Optional[str] = Field(None, max_length=32, description="Versão do aplicativo")
+    surface: Optional[str] = Field(None, description="Superfície de origem: 'home', 'search', 'recs', 'collection'")
+    position: Optional[int] = Field(None, ge=0, le=1000, description="Posição do conteúdo na lista exibida")
+    dwell_ms: Optional[int] = Field(None, ge=0, description="Tempo de permanência em milissegundos")
 
 class InteractionResponse(BaseModel):
     """Schema para resposta de criação de interação"""
@@ -40,4 +47,14 @@ class InteractionResponse(BaseModel):
     message: str
     user_id: int
     content_id: int
-    interaction_type: str
\ No newline at end of file
+    interaction_type: str
+
+class InteractionBatchRequest(BaseModel):
+    """Schema para notificação de um lote de interações já persistidas pelo backend"""
+    interactions: List[InteractionRequest]
+
+class InteractionBatchResponse(BaseModel):
+    """Schema para resposta de notificação de lote"""
+    success: bool
+    message: str
+    received: int
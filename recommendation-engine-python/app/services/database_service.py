@@ -5,6 +5,7 @@ Integra com o backend-go para acessar interações e conteúdos reais
 from sqlalchemy import create_engine, text
 from sqlalchemy.orm import sessionmaker, Session
 from sqlalchemy.exc import SQLAlchemyError
+import numpy as np
 import pandas as pd
 from typing import Tuple, Optional, List, Dict
 import logging
@@ -71,20 +72,47 @@ class DatabaseService:
             return None
         
         try:
+            # Reações (like/dislike/rating) vêm do estado atual em user_reactions,
+            # que já descontou as retratações (unlike/undislike/unrate); do log
+            # só entram os sinais implícitos, com o conteúdo salvo (bookmark)
+            # acima de view/share/comment. A ordenação por prioridade faz a
+            # reação prevalecer sobre os sinais implícitos no drop_duplicates
+            # (keep='last') e a nota prevalecer sobre like/dislike.
             query = text("""
-                SELECT 
-                    user_id,
-                    content_id,
-                    CASE 
-                        WHEN interaction_type = 'like' THEN 5.0
-                        WHEN interaction_type = 'dislike' THEN 1.0
-                        WHEN interaction_type = 'view' THEN 3.0
-                        WHEN rating IS NOT NULL THEN rating
-                        ELSE 3.0
-                    END as rating,
-                    interaction_type
-                FROM user_interactions
-                ORDER BY created_at DESC
+                SELECT user_id, content_id, rating, interaction_type
+                FROM (
+                    SELECT
+                        user_id,
+                        content_id,
+                        CASE WHEN interaction_type = 'bookmark' THEN 4.0 ELSE 3.0 END AS rating,
+                        interaction_type,
+                        0 AS priority,
+                        created_at AS signal_at
+                    FROM user_interactions
+                    WHERE flagged = FALSE
+                      AND interaction_type IN ('view', 'share', 'comment', 'bookmark')
+                    UNION ALL
+                    SELECT
+                        user_id,
+                        content_id,
+                        CASE WHEN reaction = 'like' THEN 5.0 ELSE 1.0 END AS rating,
+                        reaction AS interaction_type,
+                        1 AS priority,
+                        reacted_at AS signal_at
+                    FROM user_reactions
+                    WHERE reaction IS NOT NULL
+                    UNION ALL
+                    SELECT
+                        user_id,
+                        content_id,
+                        rating,
+                        'rating' AS interaction_type,
+                        2 AS priority,
+                        rated_at AS signal_at
+                    FROM user_reactions
+                    WHERE rating IS NOT NULL
+                ) AS signals
+                ORDER BY priority, signal_at
             """)
             
             df = pd.read_sql(query, self.engine)
@@ -142,6 +170,122 @@ class DatabaseService:
             logger.error(f"Erro ao buscar conteúdos: {e}")
             return None
     
+    def fetch_affinities(self) -> Optional[pd.DataFrame]:
+        """
+        Busca as afinidades usuário-conteúdo (user_content_affinity), calculadas
+        pelo backend-go com os pesos por tipo de interação e decaimento no tempo
+        
+        O score gravado vale para computed_at; aqui ele é decaído até agora com a
+        meia-vida de cada linha e convertido para a escala 1-5 usada pelos modelos:
+        rating = 3 + 2 * tanh(score / affinity_scale)
+        
+        Returns:
+            pd.DataFrame: DataFrame com colunas user_id, content_id, rating,
+                affinity e interaction_type ('affinity')
+        """
+        if not self.is_connected():
+            logger.warning("Não conectado ao banco de dados")
+            return None
+        
+        try:
+            query = text("""
+                SELECT 
+                    user_id,
+                    content_id,
+                    score,
+                    half_life_hours,
+                    computed_at,
+                    CURRENT_TIMESTAMP as db_now
+                FROM user_content_affinity
+                ORDER BY last_event_at
+            """)
+            
+            df = pd.read_sql(query, self.engine)
+            
+            if df.empty:
+                logger.info("Nenhuma afinidade encontrada no banco de dados")
+                return None
+            
+            # Idade de cada linha medida pelo relógio do próprio banco
+            computed_at = pd.to_datetime(df['computed_at'])
+            db_now = pd.to_datetime(df['db_now'])
+            if getattr(computed_at.dt, 'tz', None) is not None:
+                computed_at = computed_at.dt.tz_localize(None)
+            if getattr(db_now.dt, 'tz', None) is not None:
+                db_now = db_now.dt.tz_localize(None)
+            age_hours = ((db_now - computed_at).dt.total_seconds() / 3600).clip(lower=0)
+            
+            half_life = df['half_life_hours'].astype(float)
+            decay = np.where(half_life > 0, np.power(0.5, age_hours / half_life.where(half_life > 0, 1)), 1.0)
+            affinity = df['score'].astype(float) * decay
+            
+            scale = settings.affinity_scale if settings.affinity_scale > 0 else 1.0
+            result = pd.DataFrame({
+                'user_id': df['user_id'].astype(int),
+                'content_id': df['content_id'].astype(int),
+                'rating': (3.0 + 2.0 * np.tanh(affinity / scale)).clip(1.0, 5.0),
+                'affinity': affinity,
+                'interaction_type': 'affinity',
+            })
+            
+            logger.info(f"Carregadas {len(result)} afinidades do banco de dados")
+            return result
+            
+        except SQLAlchemyError as e:
+            logger.error(f"Erro ao buscar afinidades: {e}")
+            return None
+    
+    def fetch_content_popularity(self) -> Optional[pd.DataFrame]:
+        """
+        Busca estatísticas de popularidade a partir dos rollups diários
+        (content_daily_stats), mantidos pelo backend-go, sem varrer user_interactions
+        
+        Usa o mesmo mapeamento de rating de fetch_interactions:
+        like=5, dislike=1, view=3, demais tipos usam o rating informado ou 3
+        
+        Returns:
+            pd.DataFrame: DataFrame indexado por content_id com colunas
+                avg_rating e interaction_count
+        """
+        if not self.is_connected():
+            logger.warning("Não conectado ao banco de dados")
+            return None
+        
+        try:
+            query = text("""
+                SELECT 
+                    content_id,
+                    SUM(interactions) as interaction_count,
+                    SUM(
+                        CASE 
+                            WHEN interaction_type = 'like' THEN 5.0 * interactions
+                            WHEN interaction_type = 'dislike' THEN 1.0 * interactions
+                            WHEN interaction_type = 'view' THEN 3.0 * interactions
+                            ELSE rating_sum + 3.0 * (interactions - rating_count)
+                        END
+                    ) / SUM(interactions) as avg_rating
+                FROM content_daily_stats
+                WHERE interaction_type NOT IN ('unlike', 'undislike', 'unrate')
+                GROUP BY content_id
+                HAVING SUM(interactions) > 0
+            """)
+            
+            df = pd.read_sql(query, self.engine)
+            
+            if df.empty:
+                logger.info("Nenhum rollup de interações encontrado no banco de dados")
+                return None
+            
+            df['content_id'] = df['content_id'].astype(int)
+            df['interaction_count'] = df['interaction_count'].astype(int)
+            df['avg_rating'] = df['avg_rating'].astype(float)
+            
+            return df.set_index('content_id')
+            
+        except SQLAlchemyError as e:
+            logger.error(f"Erro ao buscar rollups de popularidade: {e}")
+            return None
+    
     def create_interaction(
         self,
         user_id: int,
@@ -168,8 +312,8 @@ class DatabaseService:
         try:
             query = text("""
                 INSERT INTO user_interactions 
-                (user_id, content_id, interaction_type, rating, created_at)
-                VALUES (:user_id, :content_id, :interaction_type, :rating, NOW())
+                (user_id, content_id, interaction_type, rating, created_at, received_at)
+                VALUES (:user_id, :content_id, :interaction_type, :rating, NOW(), NOW())
             """)
             
             with self.engine.begin() as conn:
@@ -54,7 +54,13 @@ class DatasetService:
                 - DataFrame de conteúdos ou None
         """
         try:
-            interactions_df = database_service.fetch_interactions()
+            interactions_df = None
+            if settings.preference_source == "affinity":
+                interactions_df = database_service.fetch_affinities()
+                if interactions_df is None:
+                    logger.info("Afinidades indisponíveis, usando interações brutas")
+            if interactions_df is None:
+                interactions_df = database_service.fetch_interactions()
             contents_df = database_service.fetch_contents()
             
             if interactions_df is None or contents_df is None:
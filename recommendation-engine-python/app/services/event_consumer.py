@@ -0,0 +1,73 @@
+"""
+Consumidor do barramento de eventos do backend-go
+
+Quando o backend-go roda com EVENT_BUS=embedded e RECOMMENDER_DELIVERY=bus,
+as interações deixam de ser enviadas por HTTP para este serviço e passam a
+ser publicadas no broker embutido (subject recommender.interaction.v1). Este
+consumidor busca as mensagens por pull, pede uma recarga do modelo (agrupada
+pelo ModelReloader) e confirma (ack) a última sequência processada. Mensagens não confirmadas são
+entregues de novo, então o processamento precisa tolerar repetições.
+"""
+import json
+import logging
+import threading
+import urllib.error
+import urllib.parse
+import urllib.request
+from typing import Callable, Dict, List
+
+from app.core.config import settings
+
+logger = logging.getLogger(__name__)
+
+# Espera antes de tentar de novo quando o backend-go está indisponível
+RETRY_SECONDS = 5
+
+
+class EventBusConsumer:
+    """Consome mensagens de um consumidor durável do broker do backend-go"""
+
+    def __init__(self, on_batch: Callable[[List[Dict]], None]):
+        self.base_url = settings.event_bus_url.rstrip("/")
+        self.durable = settings.event_bus_consumer
+        self.subject = settings.event_bus_subject
+        self.wait_seconds = settings.event_bus_wait_seconds
+        self.on_batch = on_batch
+        self._stop = threading.Event()
+        self._thread = threading.Thread(target=self._run, name="event-bus-consumer", daemon=True)
+
+    def start(self):
+        logger.info(f"Consumindo {self.subject} de {self.base_url} como '{self.durable}'")
+        self._thread.start()
+
+    def stop(self):
+        self._stop.set()
+
+    def _run(self):
+        while not self._stop.is_set():
+            try:
+                messages = self._fetch()
+                if not messages:
+                    continue
+                self.on_batch(messages)
+                self._ack(messages[-1]["seq"])
+            except Exception as e:
+                logger.error(f"Erro ao consumir barramento de eventos: {e}")
+                self._stop.wait(RETRY_SECONDS)
+
+    def _fetch(self) -> List[Dict]:
+        query = urllib.parse.urlencode({
+            "subject": self.subject,
+            "batch": 100,
+            "wait": f"{self.wait_seconds}s",
+        })
+        url = f"{self.base_url}/consumers/{self.durable}/messages?{query}"
+        with urllib.request.urlopen(url, timeout=self.wait_seconds + 10) as resp:
+            return json.load(resp).get("messages", [])
+
+    def _ack(self, seq: int):
+        url = f"{self.base_url}/consumers/{self.durable}/ack"
+        body = json.dumps({"seq": seq}).encode()
+        req = urllib.request.Request(url, data=body, method="POST", headers={"Content-Type": "application/json"})
+        with urllib.request.urlopen(req, timeout=10):
+            pass
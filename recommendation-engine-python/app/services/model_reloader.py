@@ -0,0 +1,54 @@
+"""
+Recarga do modelo de recomendação com intervalo mínimo
+
+Cada lote de interações (por HTTP ou pelo barramento) pede uma recarga, mas
+recarregar relê o dataset inteiro. Os pedidos são agrupados: o modelo é
+recarregado em uma thread própria no máximo uma vez por intervalo, e os
+pedidos feitos durante uma recarga geram uma única recarga seguinte.
+"""
+import logging
+import threading
+import time
+from typing import Callable
+
+logger = logging.getLogger(__name__)
+
+
+class ModelReloader:
+    """Agrupa pedidos de recarga do modelo"""
+
+    def __init__(self, reload: Callable[[], None], min_interval_seconds: float):
+        self.reload = reload
+        self.min_interval = max(0.0, min_interval_seconds)
+        self._pending = threading.Event()
+        self._lock = threading.Lock()
+        self._thread = None
+        self._last_reload = 0.0
+
+    def request(self):
+        """Pede uma recarga; retorna sem esperar"""
+        self._pending.set()
+        with self._lock:
+            if self._thread is None or not self._thread.is_alive():
+                self._thread = threading.Thread(target=self._run, name="model-reloader", daemon=True)
+                self._thread.start()
+
+    def _run(self):
+        while True:
+            wait = self._last_reload + self.min_interval - time.monotonic()
+            if wait > 0:
+                time.sleep(wait)
+            with self._lock:
+                if not self._pending.is_set():
+                    # Encerra sob o lock para que um novo pedido inicie outra thread
+                    self._thread = None
+                    return
+                self._pending.clear()
+
+            try:
+                logger.info("Recarregando modelo de recomendação com novas interações...")
+                self.reload()
+                logger.info("Modelo de recomendação atualizado com sucesso")
+            except Exception as e:
+                logger.error(f"Erro ao recarregar modelo: {e}")
+            self._last_reload = time.monotonic()